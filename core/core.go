@@ -4,37 +4,404 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/spdx"
 )
 
-func DownloadDependencyInfo(depFile interfaces.DepFile, file string) ([]interfaces.PackageMeta, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+// DefaultConcurrency is the number of packages downloaded in parallel
+// when no concurrency is configured
+const DefaultConcurrency = 8
+
+// Options configures how the dependency information is downloaded
+type Options struct {
+	// Concurrency is the number of workers downloading the package info in parallel
+	Concurrency int
+	// Resolve enables walking the dependencies declared by the packages,
+	// so the transitive dependencies are downloaded as well
+	Resolve bool
+	// Targets are the target environments the conditional dependencies are evaluated for,
+	// they are used only by the depfiles implementing MarkerEvaluator,
+	// the dependency is downloaded when it applies to any of the targets,
+	// all dependencies are downloaded when no target is set
+	// With more targets the packages are annotated by the targets they apply to
+	Targets []Target
+	// Extras are the extras of the project the markers of the direct dependencies
+	// are evaluated with
+	Extras []string
+	// ExcludeGroups are the dependency groups left out (e.g. dev),
+	// the dependency is left out only when all its groups are excluded
+	ExcludeGroups []string
+	// Projects are the workspace projects the dependencies are read for (e.g. packages/app),
+	// they are supported only by the depfiles implementing ProjectSelector
+	Projects []string
+}
+
+// Target is the environment the dependencies are evaluated for
+type Target struct {
+	// Name identifies the target in the package annotations e.g. 3.11/linux
+	Name        string
+	Environment interfaces.Environment
+}
+
+// MaxTargets is the maximum number of the targets evaluated at once
+const MaxTargets = 64
+
+// targetedDependency is the dependency with the targets it applies to,
+// the error is set when its markers cannot be evaluated
+type targetedDependency struct {
+	dependency interfaces.Dependency
+	targets    targetSet
+	err        *interfaces.PackageError
+}
+
+// packageJob is a single dependency to download, the index is the position
+// of the dependency in the depfile and it is used to keep the output order
+type packageJob struct {
+	index int
+	targetedDependency
+}
+
+type packageResult struct {
+	index      int
+	dependency interfaces.Dependency
+	meta       *interfaces.PackageMeta
+	err        *interfaces.PackageError
+	targets    targetSet
+}
+
+// packageError converts the error returned by the repository to the package error
+// errors without a stage are considered to be fetch errors
+func packageError(dependency interfaces.Dependency, err error) *interfaces.PackageError {
+	if err == nil {
+		return nil
+	}
+	var pkgErr *interfaces.PackageError
+	if errors.As(err, &pkgErr) {
+		return pkgErr
+	}
+	return &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+}
+
+// DownloadDependencyInfo downloads the meta info of all dependencies in the depfile
+// The downloads run on a pool of workers, the returned packages keep the order
+// of the dependencies in the depfile
+// When the resolution is enabled, the dependencies of the downloaded packages
+// are downloaded level by level and returned after the direct dependencies,
+// every package is downloaded only once
+// Packages that cannot be resolved do not stop the download, they are returned
+// as package errors in the depfile order as well
+// The error is returned only when the depfile cannot be read or the context is cancelled,
+// cancelling the context stops all in-flight requests
+func DownloadDependencyInfo(ctx context.Context, depFile interfaces.DepFile, file string, options Options) ([]interfaces.PackageMeta, []interfaces.PackageError, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	packages := make([]interfaces.PackageMeta, 0)
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	if len(options.Targets) > MaxTargets {
+		return nil, nil, fmt.Errorf("too many targets %d, at most %d targets are supported", len(options.Targets), MaxTargets)
+	}
+	evaluator, _ := depFile.(interfaces.MarkerEvaluator)
+	targets := targetEvaluator{evaluator: evaluator, targets: options.Targets}
 
-	repo := depFile.GetRepository()
+	if len(options.Projects) > 0 {
+		selector, ok := depFile.(interfaces.ProjectSelector)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s does not support selecting the projects", depFile.GetDepFileType())
+		}
+		selector.SelectProjects(options.Projects)
+	}
 
-	// iterate over the list of dependencies and download their meta info
 	dependencies, err := depFile.GetDependencies(ctx, file)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if len(options.ExcludeGroups) > 0 {
+		dependencies = excludeGroups(ctx, dependencies, options.ExcludeGroups)
+	}
+
+	// the repository can depend on the options read from the depfile
+	repo := depFile.GetRepository()
+
+	results := download(ctx, repo, applyTargets(ctx, dependencies, targets, options.Extras), concurrency)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
 	}
-	for dep := range dependencies {
-		// download the meta info
-		meta, err := repo.GetPackageInfo(dep)
-		if err != nil {
-			return nil, err
+
+	if options.Resolve {
+		// seen maps the packages to their first result, the package required
+		// for another target or with other extras is not downloaded again,
+		// only its targets and extras are extended and its requirements are walked again
+		seen := map[string]int{}
+		for i, result := range results {
+			if _, ok := seen[interfaces.NormalizeName(result.dependency.Name)]; !ok {
+				seen[interfaces.NormalizeName(result.dependency.Name)] = i
+			}
+		}
+
+		level := results
+		for len(level) > 0 {
+			required, failed := requiredDependencies(level, targets)
+			results = append(results, failed...)
+
+			// the packages required for new targets are walked again for them,
+			// the packages required with new extras are walked again for all their targets
+			var extended []packageResult
+			var downloads []targetedDependency
+			queued := map[string]int{}
+			for _, dependency := range required {
+				key := interfaces.NormalizeName(dependency.dependency.Name)
+				if i, ok := seen[key]; ok {
+					added := dependency.targets &^ results[i].targets
+					extras := newExtras(results[i].dependency.Extras, dependency.dependency.Extras)
+					if added == 0 && len(extras) == 0 {
+						continue
+					}
+					results[i].targets |= added
+					results[i].dependency.Extras = append(append([]string{}, results[i].dependency.Extras...), extras...)
+					if results[i].err == nil {
+						result := results[i]
+						if len(extras) == 0 {
+							result.targets = added
+						}
+						extended = append(extended, result)
+					}
+					continue
+				}
+				if i, ok := queued[key]; ok {
+					downloads[i].targets |= dependency.targets
+					extras := newExtras(downloads[i].dependency.Extras, dependency.dependency.Extras)
+					downloads[i].dependency.Extras = append(append([]string{}, downloads[i].dependency.Extras...), extras...)
+					continue
+				}
+				queued[key] = len(downloads)
+				downloads = append(downloads, dependency)
+			}
+
+			level = download(ctx, repo, sendDependencies(ctx, downloads), concurrency)
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			for _, result := range level {
+				seen[interfaces.NormalizeName(result.dependency.Name)] = len(results)
+				results = append(results, result)
+			}
+			level = append(level, extended...)
 		}
-		// store the meta info
-		packages = append(packages, *meta)
 	}
 
-	return packages, nil
+	packages := make([]interfaces.PackageMeta, 0, len(results))
+	pkgErrors := make([]interfaces.PackageError, 0)
+	for _, result := range results {
+		if result.err != nil {
+			pkgErrors = append(pkgErrors, *result.err)
+			continue
+		}
+		if len(options.Targets) > 1 {
+			result.meta.Targets = targets.names(result.targets)
+		}
+		packages = append(packages, *result.meta)
+	}
+
+	return packages, pkgErrors, nil
+}
+
+// download downloads the meta info of the dependencies on a pool of workers
+// the results are returned in the order of the dependencies
+func download(ctx context.Context, repo interfaces.PackageRepository, dependencies <-chan targetedDependency, concurrency int) []packageResult {
+	jobs := make(chan packageJob)
+	results := make(chan packageResult)
+
+	// number the dependencies in the order they come from the depfile
+	go func() {
+		defer close(jobs)
+		index := 0
+		for dep := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- packageJob{index: index, targetedDependency: dep}:
+				index++
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if job.err != nil {
+					results <- packageResult{index: job.index, dependency: job.dependency, err: job.err, targets: job.targets}
+					continue
+				}
+				// download the meta info
+				meta, err := repo.GetPackageInfo(ctx, job.dependency)
+				if err == nil {
+					if meta.LicenseSPDX == "" {
+						meta.LicenseSPDX = spdx.Normalize(meta.License)
+					}
+					meta.Transitive = len(job.dependency.Path) > 0
+					meta.Path = job.dependency.Path
+					meta.Projects = job.dependency.Projects
+				}
+				results <- packageResult{index: job.index, dependency: job.dependency, meta: meta, err: packageError(job.dependency, err), targets: job.targets}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// store the meta info and errors on the position of the dependency
+	ordered := make(map[int]packageResult)
+	for result := range results {
+		ordered[result.index] = result
+	}
+
+	list := make([]packageResult, 0, len(ordered))
+	for i := 0; i < len(ordered); i++ {
+		list = append(list, ordered[i])
+	}
+	return list
+}
+
+// sendDependencies streams the dependencies the same way the depfiles do
+func sendDependencies(ctx context.Context, dependencies []targetedDependency) <-chan targetedDependency {
+	depChan := make(chan targetedDependency)
+	go func() {
+		defer close(depChan)
+		for _, dep := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dep:
+			}
+		}
+	}()
+	return depChan
+}
+
+// excludeGroups filters out the dependencies whose groups are all excluded,
+// the dependencies without any group are always kept
+func excludeGroups(ctx context.Context, dependencies <-chan interfaces.Dependency, groups []string) <-chan interfaces.Dependency {
+	excluded := map[string]bool{}
+	for _, group := range groups {
+		excluded[group] = true
+	}
+
+	depChan := make(chan interfaces.Dependency)
+	go func() {
+		defer close(depChan)
+		for dep := range dependencies {
+			keep := len(dep.Groups) == 0
+			for _, group := range dep.Groups {
+				if !excluded[group] {
+					keep = true
+				}
+			}
+			if !keep {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dep:
+			}
+		}
+	}()
+	return depChan
+}
+
+// applyTargets filters out the direct dependencies which do not apply to any target
+// the markers that cannot be evaluated are returned as package errors
+func applyTargets(ctx context.Context, dependencies <-chan interfaces.Dependency, targets targetEvaluator, extras []string) <-chan targetedDependency {
+	depChan := make(chan targetedDependency)
+	go func() {
+		defer close(depChan)
+		for dep := range dependencies {
+			applies, err := targets.evaluate(dep, extras, targets.all())
+			targeted := targetedDependency{dependency: dep, targets: applies}
+			if err != nil {
+				targeted.err = &interfaces.PackageError{Package: dep.Name, Stage: interfaces.StageResolve, Err: err}
+			} else if applies == 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- targeted:
+			}
+		}
+	}()
+	return depChan
+}
+
+// requiredDependencies returns the dependencies of the downloaded packages
+// with the targets of the package they apply to, the path of every dependency
+// is extended by the package requiring it
+// The markers that cannot be evaluated are returned as package errors
+func requiredDependencies(level []packageResult, targets targetEvaluator) ([]targetedDependency, []packageResult) {
+	var required []targetedDependency
+	var failed []packageResult
+	for _, result := range level {
+		if result.err != nil {
+			continue
+		}
+		path := append(append([]string{}, result.dependency.Path...), result.meta.Name)
+		for _, dependency := range result.meta.Requires {
+			applies, err := targets.evaluate(dependency, result.dependency.Extras, result.targets)
+			if err != nil {
+				pkgErr := &interfaces.PackageError{
+					Package: dependency.Name,
+					Stage:   interfaces.StageResolve,
+					Err:     fmt.Errorf("required by %s: %w", result.meta.Name, err),
+				}
+				failed = append(failed, packageResult{dependency: dependency, err: pkgErr})
+				continue
+			}
+			if applies == 0 {
+				continue
+			}
+			dependency.Path = path
+			dependency.Projects = result.dependency.Projects
+			required = append(required, targetedDependency{dependency: dependency, targets: applies})
+		}
+	}
+	return required, failed
+}
+
+// newExtras returns the requested extras which are not among the known extras,
+// the extras are compared the same way as the package names
+func newExtras(known []string, requested []string) []string {
+	var extras []string
+	for _, extra := range requested {
+		found := false
+		for _, other := range append(append([]string{}, known...), extras...) {
+			if interfaces.NormalizeName(other) == interfaces.NormalizeName(extra) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			extras = append(extras, extra)
+		}
+	}
+	return extras
 }
 
-func ProcessDependencyInfo(result interfaces.Result, packages []interfaces.PackageMeta) string {
+func ProcessDependencyInfo(result interfaces.Result, packages []interfaces.PackageMeta, pkgErrors []interfaces.PackageError, verdicts []interfaces.Verdict) string {
 	result.AddPackageMeta(packages)
+	result.AddPackageErrors(pkgErrors)
+	result.AddVerdicts(verdicts)
 	return string(result.GetResult())
 }
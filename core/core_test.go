@@ -0,0 +1,417 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/languages/python"
+)
+
+// testDepFile is a depfile returning a fixed list of dependencies
+// and fetching them from the given repository
+type testDepFile struct {
+	dependencies []string
+	groups       map[string][]string
+	markers      map[string]string
+	repo         interfaces.PackageRepository
+}
+
+func (d *testDepFile) GetDepFileType() string {
+	return "test"
+}
+
+func (d *testDepFile) GetRepository() interfaces.PackageRepository {
+	return d.repo
+}
+
+func (d *testDepFile) GetDependencies(ctx context.Context, file string) (<-chan interfaces.Dependency, error) {
+	depChan := make(chan interfaces.Dependency)
+	go func() {
+		defer close(depChan)
+		for _, name := range d.dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- interfaces.Dependency{Name: name, Groups: d.groups[name], Markers: d.markers[name]}:
+			}
+		}
+	}()
+	return depChan, nil
+}
+
+// newLatencyServer returns a PyPI stand-in answering every request after the delay
+// the package name is taken from the url, so the order of the output can be checked
+func newLatencyServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		name := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[1]
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, `{"info": {"name": %q, "classifiers": ["License :: OSI Approved :: MIT License"]}}`, name)
+	}))
+}
+
+func testDependencies(count int) []string {
+	var names []string
+	for i := 0; i < count; i++ {
+		names = append(names, fmt.Sprintf("package-%d", i))
+	}
+	return names
+}
+
+func TestDownloadDependencyInfoConcurrent(t *testing.T) {
+	delay := 50 * time.Millisecond
+	server := newLatencyServer(delay)
+	defer server.Close()
+
+	depFile := &testDepFile{
+		dependencies: testDependencies(20),
+		repo:         python.NewPyPI("pypi", server.URL+"/pypi/<package_name>/json"),
+	}
+
+	start := time.Now()
+	packages, pkgErrors, err := DownloadDependencyInfo(context.Background(), depFile, "", Options{Concurrency: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgErrors) != 0 {
+		t.Fatalf("Expected no package errors, got %v", pkgErrors)
+	}
+	elapsed := time.Since(start)
+
+	// sequential download would take 20 * delay
+	if elapsed >= 10*delay {
+		t.Errorf("Expected concurrent download to finish under %s, took %s", 10*delay, elapsed)
+	}
+
+	if len(packages) != len(depFile.dependencies) {
+		t.Fatalf("Expected %d packages, got %d", len(depFile.dependencies), len(packages))
+	}
+	for i, pkg := range packages {
+		if pkg.Name != depFile.dependencies[i] {
+			t.Errorf("Expected %s on position %d, got %s", depFile.dependencies[i], i, pkg.Name)
+		}
+		if pkg.License != "MIT License" {
+			t.Errorf("Expected MIT License, got %s", pkg.License)
+		}
+	}
+}
+
+func TestDownloadDependencyInfoCancel(t *testing.T) {
+	server := newLatencyServer(time.Minute)
+	defer server.Close()
+
+	depFile := &testDepFile{
+		dependencies: testDependencies(5),
+		repo:         python.NewPyPI("pypi", server.URL+"/pypi/<package_name>/json"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := DownloadDependencyInfo(ctx, depFile, "", Options{Concurrency: 2})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected in-flight requests to be cancelled, took %s", elapsed)
+	}
+}
+
+func TestDownloadDependencyInfoPackageErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "missing"):
+			http.NotFound(w, r)
+		case strings.Contains(r.URL.Path, "broken"):
+			fmt.Fprint(w, `{"info": `)
+		default:
+			fmt.Fprint(w, `{"info": {"name": "requests", "classifiers": ["License :: OSI Approved :: Apache Software License"]}}`)
+		}
+	}))
+	defer server.Close()
+
+	depFile := &testDepFile{
+		dependencies: []string{"missing", "requests", "broken"},
+		repo:         python.NewPyPI("pypi", server.URL+"/pypi/<package_name>/json"),
+	}
+
+	packages, pkgErrors, err := DownloadDependencyInfo(context.Background(), depFile, "", Options{Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(packages) != 1 || packages[0].Name != "requests" {
+		t.Fatalf("Expected only requests to be resolved, got %v", packages)
+	}
+
+	expected := []struct {
+		name  string
+		stage string
+	}{
+		{"missing", interfaces.StageFetch},
+		{"broken", interfaces.StageDecode},
+	}
+	if len(pkgErrors) != len(expected) {
+		t.Fatalf("Expected %d package errors, got %d", len(expected), len(pkgErrors))
+	}
+	for i, pkgErr := range pkgErrors {
+		if pkgErr.Package != expected[i].name {
+			t.Errorf("Expected package %s, got %s", expected[i].name, pkgErr.Package)
+		}
+		if pkgErr.Stage != expected[i].stage {
+			t.Errorf("Expected stage %s, got %s", expected[i].stage, pkgErr.Stage)
+		}
+	}
+}
+
+// testMarkerDepFile is a test depfile evaluating the python environment markers
+type testMarkerDepFile struct {
+	testDepFile
+}
+
+func (d *testMarkerDepFile) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return python.NewRequirementsTxt().EvaluateMarkers(dependency, environment, extras)
+}
+
+func TestDownloadDependencyInfoResolve(t *testing.T) {
+	requires := map[string][]string{
+		"app":   {"lib-a", "lib_b; sys_platform == 'win32'", "Lib-C[extra1]"},
+		"lib-a": {"lib-c", "app"},
+		"Lib-C": {"lib-d; extra == 'extra1'", "lib-e; extra == 'other'"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[1]
+		if name == "lib-d" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, `{"info": {"name": %q, "license": "MIT", "requires_dist": %s}}`, name, toJSONList(requires[name]))
+	}))
+	defer server.Close()
+
+	depFile := &testMarkerDepFile{testDepFile{
+		dependencies: []string{"app"},
+		repo:         python.NewPyPI("pypi", server.URL+"/pypi/<package_name>/json"),
+	}}
+	environment, err := python.NewEnvironment("3.11", "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packages, pkgErrors, err := DownloadDependencyInfo(context.Background(), depFile, "", Options{
+		Concurrency: 2,
+		Resolve:     true,
+		Targets:     []Target{{Name: "3.11/linux", Environment: environment}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name       string
+		transitive bool
+		path       string
+	}{
+		{"app", false, ""},
+		{"lib-a", true, "app"},
+		{"Lib-C", true, "app"},
+	}
+	if len(packages) != len(expected) {
+		t.Fatalf("Expected %d packages, got %v", len(expected), packages)
+	}
+	for i, pkg := range packages {
+		if pkg.Name != expected[i].name {
+			t.Errorf("Expected %s on position %d, got %s", expected[i].name, i, pkg.Name)
+		}
+		if pkg.Transitive != expected[i].transitive {
+			t.Errorf("Expected %s transitive %t, got %t", pkg.Name, expected[i].transitive, pkg.Transitive)
+		}
+		if path := strings.Join(pkg.Path, " > "); path != expected[i].path {
+			t.Errorf("Expected %s path %q, got %q", pkg.Name, expected[i].path, path)
+		}
+	}
+
+	// lib-d is required through the requested extra, so it is resolved as well
+	if len(pkgErrors) != 1 || pkgErrors[0].Package != "lib-d" {
+		t.Fatalf("Expected lib-d to fail, got %v", pkgErrors)
+	}
+}
+
+func toJSONList(values []string) string {
+	quoted := []string{}
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func TestDownloadDependencyInfoExcludeGroups(t *testing.T) {
+	server := newLatencyServer(0)
+	defer server.Close()
+
+	depFile := &testDepFile{
+		dependencies: []string{"requests", "pytest", "black", "ungrouped", "click"},
+		groups: map[string][]string{
+			"requests": {"main"},
+			"pytest":   {"dev"},
+			"black":    {"dev", "lint"},
+			"click":    {"main", "dev"},
+		},
+		repo: python.NewPyPI("pypi", server.URL+"/pypi/<package_name>/json"),
+	}
+
+	packages, _, err := DownloadDependencyInfo(context.Background(), depFile, "", Options{ExcludeGroups: []string{"dev", "lint"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, pkg := range packages {
+		names = append(names, pkg.Name)
+	}
+	if strings.Join(names, ",") != "requests,ungrouped,click" {
+		t.Errorf("Expected the packages outside of the excluded groups, got %v", names)
+	}
+}
+
+func TestDownloadDependencyInfoProjects(t *testing.T) {
+	depFile := &testDepFile{dependencies: []string{"requests"}}
+
+	_, _, err := DownloadDependencyInfo(context.Background(), depFile, "", Options{Projects: []string{"packages/app"}})
+	if err == nil {
+		t.Error("Expected an error for the depfile without the projects")
+	}
+}
+
+func TestDownloadDependencyInfoTargets(t *testing.T) {
+	requires := map[string][]string{
+		"app":      {"colorama; sys_platform == 'win32'", "lib-a"},
+		"lib-a":    {"lib-b"},
+		"lib-b":    {"colorama"},
+		"colorama": {"ansi; sys_platform != 'win32'"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[1]
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, `{"info": {"name": %q, "license": "MIT", "requires_dist": %s}}`, name, toJSONList(requires[name]))
+	}))
+	defer server.Close()
+
+	depFile := &testMarkerDepFile{testDepFile{
+		dependencies: []string{"app", "pywin32", "uvloop", "socks-lib"},
+		markers: map[string]string{
+			"pywin32":   `sys_platform == "win32"`,
+			"uvloop":    `sys_platform != "win32"`,
+			"socks-lib": `extra == "socks"`,
+		},
+		repo: python.NewPyPI("pypi", server.URL+"/pypi/<package_name>/json"),
+	}}
+	var targets []Target
+	for _, platform := range []string{"linux", "windows"} {
+		environment, err := python.NewEnvironment("3.11", platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, Target{Name: platform, Environment: environment})
+	}
+
+	packages, pkgErrors, err := DownloadDependencyInfo(context.Background(), depFile, "", Options{
+		Resolve: true,
+		Targets: targets,
+		Extras:  []string{"socks"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgErrors) > 0 {
+		t.Fatalf("Unexpected package errors %v", pkgErrors)
+	}
+
+	var got []string
+	for _, pkg := range packages {
+		got = append(got, fmt.Sprintf("%s (%s) [%s]", pkg.Name, strings.Join(pkg.Path, " > "), strings.Join(pkg.Targets, ", ")))
+	}
+	// colorama is required on linux only through lib-b, so its requirements are walked again for linux
+	expected := []string{
+		"app () [linux, windows]",
+		"pywin32 () [windows]",
+		"uvloop () [linux]",
+		"socks-lib () [linux, windows]",
+		"colorama (app) [linux, windows]",
+		"lib-a (app) [linux, windows]",
+		"lib-b (app > lib-a) [linux, windows]",
+		"ansi (app > colorama) [linux]",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected packages\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// a single target does not annotate the packages
+	packages, _, err = DownloadDependencyInfo(context.Background(), depFile, "", Options{Targets: targets[1:]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, pkg := range packages {
+		got = append(got, fmt.Sprintf("%s [%s]", pkg.Name, strings.Join(pkg.Targets, ", ")))
+	}
+	if strings.Join(got, ",") != "app [],pywin32 []" {
+		t.Errorf("Expected the windows packages without the targets, got %v", got)
+	}
+}
+
+// TestDownloadDependencyInfoResolveNewExtras tests that the package required again
+// with other extras is walked again for the requirements of the extras
+func TestDownloadDependencyInfoResolveNewExtras(t *testing.T) {
+	requires := map[string][]string{
+		"app":      {"requests", "toolkit"},
+		"toolkit":  {"requests[socks]"},
+		"requests": {"urllib3", "pysocks; extra == 'socks'"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[1]
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, `{"info": {"name": %q, "license": "MIT", "requires_dist": %s}}`, name, toJSONList(requires[name]))
+	}))
+	defer server.Close()
+
+	depFile := &testMarkerDepFile{testDepFile{
+		dependencies: []string{"app"},
+		repo:         python.NewPyPI("pypi", server.URL+"/pypi/<package_name>/json"),
+	}}
+	environment, err := python.NewEnvironment("3.11", "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	packages, pkgErrors, err := DownloadDependencyInfo(context.Background(), depFile, "", Options{
+		Resolve: true,
+		Targets: []Target{{Name: "3.11/linux", Environment: environment}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgErrors) > 0 {
+		t.Fatalf("Expected no package errors, got %v", pkgErrors)
+	}
+
+	var got []string
+	for _, pkg := range packages {
+		got = append(got, pkg.Name+" "+strings.Join(pkg.Path, " > "))
+	}
+	expected := []string{"app ", "requests app", "toolkit app", "urllib3 app > requests", "pysocks app > requests"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import "github.com/radiculaCZ/license-check/interfaces"
+
+// targetSet is the set of the targets given by their position in the options,
+// the single implicit target is used when no target is set
+type targetSet uint64
+
+// targetEvaluator evaluates the markers of the dependencies for the targets
+type targetEvaluator struct {
+	evaluator interfaces.MarkerEvaluator
+	targets   []Target
+}
+
+// all returns the set of all targets
+func (t targetEvaluator) all() targetSet {
+	if len(t.targets) == 0 {
+		return 1
+	}
+	return targetSet(1)<<len(t.targets) - 1
+}
+
+// evaluate returns the targets of the given ones the dependency applies to
+// extras are the extras requested for the package declaring the dependency
+// The dependency applies to all targets when the depfile cannot evaluate the markers
+func (t targetEvaluator) evaluate(dependency interfaces.Dependency, extras []string, targets targetSet) (targetSet, error) {
+	if t.evaluator == nil || len(t.targets) == 0 {
+		return targets, nil
+	}
+	var applies targetSet
+	for i, target := range t.targets {
+		if targets&(1<<i) == 0 {
+			continue
+		}
+		ok, err := t.evaluator.EvaluateMarkers(dependency, target.Environment, extras)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			applies |= 1 << i
+		}
+	}
+	return applies, nil
+}
+
+// names returns the names of the targets in the set in the order of the options
+func (t targetEvaluator) names(targets targetSet) []string {
+	var names []string
+	for i, target := range t.targets {
+		if targets&(1<<i) != 0 {
+			names = append(names, target.Name)
+		}
+	}
+	return names
+}
@@ -3,10 +3,17 @@ module github.com/radiculaCZ/license-check
 go 1.20
 
 require (
-	github.com/alecthomas/participle/v2 v2.0.0 // indirect
+	github.com/BurntSushi/toml v1.3.2
+	github.com/alecthomas/participle/v2 v2.0.0
+	github.com/google/go-cmp v0.5.9
+	github.com/urfave/cli/v2 v2.25.7
+	golang.org/x/mod v0.14.0
+	golang.org/x/net v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.2 // indirect
-	github.com/google/go-cmp v0.5.9 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	github.com/urfave/cli/v2 v2.25.7 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
 )
@@ -0,0 +1,73 @@
+package interfaces
+
+import (
+	"regexp"
+	"strings"
+)
+
+// nameSeparators are ignored when comparing the package names
+var nameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// Dependency represents a single requirement read from a dependency file
+// It carries everything that is needed to find the exact package release
+// e.g. numpy[all] >= 1.23.5, < 1.24; python_version >= "3.8"
+// Dependency does not have to be an interface for the same reason
+// as PackageMeta
+type Dependency struct {
+	Name string
+	// Versions contains the version specifiers, all of them have to be satisfied
+	Versions []VersionSpecifier
+	Extras   []string
+	// Markers contains the environment markers in their textual form
+	// (PEP 508 for python), empty if the dependency is unconditional
+	Markers string
+	// Path lists the packages that pulled in the transitive dependency,
+	// starting with the direct dependency, it is empty for the direct dependencies
+	Path []string
+	// File and Line tell where the dependency is declared,
+	// the file can be a file included by the depfile
+	File string
+	Line int
+	// URL is the direct reference of the dependency (e.g. the url or the local path
+	// of the archive), the package is read from it instead of the package index
+	URL string
+	// Index is the url of the package index the dependency has to be downloaded from
+	// (e.g. the source of the locked package), empty when any configured index can be used
+	Index string
+	// Groups are the dependency groups the dependency belongs to (e.g. main, dev),
+	// empty when the depfile does not group the dependencies
+	Groups []string
+	// Projects are the workspace projects of the monorepo requiring the dependency,
+	// empty when the depfile has a single project
+	Projects []string
+}
+
+// VersionSpecifier is a single version constraint e.g. >= 1.23.5
+type VersionSpecifier struct {
+	Operator string
+	Value    string
+}
+
+// PinnedVersion returns the exact version the dependency is pinned to
+// The version is pinned when some specifier uses the == or === operator
+// without any wildcard (e.g. the version pinned by a constraint),
+// otherwise empty string is returned
+func (d Dependency) PinnedVersion() string {
+	for _, specifier := range d.Versions {
+		if specifier.Operator != "==" && specifier.Operator != "===" {
+			continue
+		}
+		if strings.Contains(specifier.Value, "*") {
+			continue
+		}
+		return specifier.Value
+	}
+	return ""
+}
+
+// NormalizeName returns the PEP 503 normalized name of the package or extra,
+// the names are compared case insensitively and the separators are ignored
+// as most package indexes do, e.g. Typing_Extensions and typing.extensions are both typing-extensions
+func NormalizeName(name string) string {
+	return strings.ToLower(nameSeparators.ReplaceAllString(strings.TrimSpace(name), "-"))
+}
@@ -12,13 +12,25 @@ type DepFile interface {
 	// GetDepFileType returns the type of the dependency file
 	// e.g. npm, composer, pip, etc.
 	GetDepFileType() string
-	// GetDependencies returns a channel of package names
+	// GetDependencies returns a channel of dependencies found in the depfile
 	// The path to the depfile is passed as second argument
-	// The channel is closed when all package names are sent
+	// The channel is closed when all dependencies are sent
 	// The context is passed in a case the function needs to be cancelled
-	GetDependencies(context.Context, string) (<-chan string, error)
+	GetDependencies(context.Context, string) (<-chan Dependency, error)
 	// GetRepository returns the repository that is used to get package information
 	// Ussually the repository is language specific, but it might be also
 	// file format specific (altough I am not aware of any such case)
+	// It is called after GetDependencies, so the repository can be configured
+	// by the depfile itself (e.g. the package index url in requirements.txt)
 	GetRepository() PackageRepository
 }
+
+// ProjectSelector is implemented by the depfiles locking the workspace
+// with more projects (e.g. the monorepo), the dependencies are read
+// only for the selected projects
+type ProjectSelector interface {
+	// SelectProjects selects the projects the dependencies are read for,
+	// all projects are read when no project is selected
+	// It is called before GetDependencies
+	SelectProjects(projects []string)
+}
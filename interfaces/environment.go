@@ -0,0 +1,14 @@
+package interfaces
+
+// Environment is the target environment the dependencies are resolved for
+// It maps the environment marker variables to their values,
+// e.g. python_version and sys_platform for python
+type Environment map[string]string
+
+// MarkerEvaluator is implemented by the depfiles whose dependencies
+// can be conditional on the target environment (e.g. PEP 508 markers)
+type MarkerEvaluator interface {
+	// EvaluateMarkers reports whether the dependency applies in the environment
+	// extras are the extras requested for the package declaring the dependency
+	EvaluateMarkers(dependency Dependency, environment Environment, extras []string) (bool, error)
+}
@@ -1,17 +1,60 @@
 package interfaces
 
+// Sources of the package license, they tell which metadata the License comes from
+const (
+	// LicenseSourceExpression is the SPDX license expression (e.g. PEP 639 License-Expression)
+	LicenseSourceExpression = "expression"
+	// LicenseSourceClassifier is the license classifier (e.g. trove classifier)
+	LicenseSourceClassifier = "classifier"
+	// LicenseSourceField is the free text license field
+	LicenseSourceField = "field"
+	// LicenseSourceFile is the license detected from the text of the license file
+	LicenseSourceFile = "file"
+)
+
 // PackageMeta represents a programming langugage package
 // It contains the meta information about the package
 // PackageMeta does not have to be an interface because
 // it will not change and will not require different
 // implementations
 type PackageMeta struct {
-	Author      string
-	Name        string
-	Version     string
-	License     string
+	Author  string
+	Name    string
+	Version string
+	License string
+	// LicenseSPDX is the SPDX identifier or the SPDX license expression
+	// of the License in the canonical form, empty when the license could not be mapped
+	LicenseSPDX string
 	Description string
 	Homepage    string
 	Repository  string
 	Language    string
+	// LicenseSource tells which metadata the License comes from, one of LicenseSource* constants
+	LicenseSource string
+	// LicenseConfidence is the similarity of the license file and the detected license
+	// in the range from 0 to 1, it is set only for the LicenseSourceFile
+	LicenseConfidence float64
+	// LicenseConflicts lists the licenses from the other sources disagreeing
+	// with the License in the form <source>: <license>
+	LicenseConflicts []string
+	// LicenseFiles are the license files declared by the package
+	LicenseFiles []string
+	// Licenses lists all licenses when the package declares several of them
+	// (e.g. multiple license classifiers), the package can be used under any of them
+	Licenses []string
+	// Requires are the dependencies declared by the package itself
+	Requires []Dependency
+	// Transitive is set when the package is not listed in the depfile,
+	// but it is required by some other package
+	Transitive bool
+	// Path lists the packages that pulled in the transitive package,
+	// starting with the direct dependency
+	Path []string
+	// Index is the url of the package index the package info was downloaded from
+	Index string
+	// Targets are the names of the target environments the package is required in,
+	// set only when the dependencies are evaluated for more targets
+	Targets []string
+	// Projects are the workspace projects of the monorepo requiring the package
+	Projects []string
 }
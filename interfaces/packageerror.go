@@ -0,0 +1,29 @@
+package interfaces
+
+// Stages of the package processing, they tell where the package failed
+const (
+	// StageFetch means the package info could not be downloaded
+	StageFetch = "fetch"
+	// StageDecode means the downloaded package info could not be read
+	StageDecode = "decode"
+	// StageResolve means the dependency of the package could not be resolved
+	// e.g. its environment markers could not be evaluated
+	StageResolve = "resolve"
+)
+
+// PackageError records a package that could not be resolved
+// The failed packages are collected alongside the PackageMeta slice,
+// so a single failure does not discard the rest of the packages
+type PackageError struct {
+	Package string
+	Stage   string
+	Err     error
+}
+
+func (e *PackageError) Error() string {
+	return e.Package + ": " + e.Stage + ": " + e.Err.Error()
+}
+
+func (e *PackageError) Unwrap() error {
+	return e.Err
+}
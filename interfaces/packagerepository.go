@@ -2,6 +2,8 @@
 // on the internet
 package interfaces
 
+import "context"
+
 // PackageRepository represents an online package index
 // It is used to search for packages and their versions and licenses
 // some also provide CVE checks
@@ -9,6 +11,9 @@ type PackageRepository interface {
 	// GetRepositoryName returns the name of the repository
 	GetRepositoryName() string
 	// GetPackageInfo returns the meta information about a package
+	// The release matching the dependency version is used when the version is pinned,
+	// otherwise the latest release is used
 	// If nothing cannot be returned, the nil is returned and error
-	GetPackageInfo(packageName string) (*PackageMeta, error)
+	// The context is used to cancel the in-flight request
+	GetPackageInfo(ctx context.Context, dependency Dependency) (*PackageMeta, error)
 }
@@ -9,4 +9,10 @@ type Result interface {
 	GetResultName() string
 	GetResult() []byte
 	AddPackageMeta([]PackageMeta)
+	// AddPackageErrors adds the packages that could not be resolved
+	// they are rendered as an unresolved section
+	AddPackageErrors([]PackageError)
+	// AddVerdicts adds the license policy verdicts of the packages
+	// packages without a verdict are rendered without it
+	AddVerdicts([]Verdict)
 }
@@ -0,0 +1,21 @@
+package interfaces
+
+// Verdict statuses of the license policy evaluation
+const (
+	VerdictAllowed = "allowed"
+	VerdictDenied  = "denied"
+	VerdictReview  = "review"
+	VerdictUnknown = "unknown"
+)
+
+// Verdict is the result of the license policy evaluation for a single package
+type Verdict struct {
+	Package string
+	// Version is the version of the package, the same package can be checked at more versions
+	Version string
+	License string
+	// Status is one of the Verdict* constants
+	Status string
+	// Reason explains the status, e.g. the justification of the exception
+	Reason string
+}
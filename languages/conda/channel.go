@@ -0,0 +1,270 @@
+// Used to get the packages from the conda channels
+// The channels hosted on anaconda.org are queried by the Anaconda API
+// https://api.anaconda.org/package/<channel>/<package_name>, the other channels
+// (e.g. repo.anaconda.com or the local mirror) by their repodata.json
+// https://docs.conda.io/projects/conda-build/en/latest/concepts/generating-index.html
+// The Channel represents an implementation of interface packagerepository
+package conda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// errPackageNotFound is returned when the channel does not contain the package
+var errPackageNotFound = errors.New("package not found in the channel")
+
+// condaRecord is the package record of the repodata.json and the Anaconda API
+type condaRecord struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Build       string   `json:"build"`
+	BuildNumber int      `json:"build_number"`
+	Depends     []string `json:"depends"`
+	License     string   `json:"license"`
+	Subdir      string   `json:"subdir"`
+	Timestamp   int64    `json:"timestamp"`
+}
+
+type repodataResponse struct {
+	Packages      map[string]condaRecord `json:"packages"`
+	PackagesConda map[string]condaRecord `json:"packages.conda"`
+}
+
+// anacondaPackage is the package of the Anaconda API, the files are the builds of all versions
+type anacondaPackage struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+	License string `json:"license"`
+	Home    string `json:"home"`
+	DevURL  string `json:"dev_url"`
+	Files   []struct {
+		Version string      `json:"version"`
+		Attrs   condaRecord `json:"attrs"`
+	} `json:"files"`
+}
+
+// subdirRepodata is the repodata.json of the channel subdir, it is downloaded only once
+type subdirRepodata struct {
+	once     sync.Once
+	packages map[string][]condaRecord
+	err      error
+}
+
+// Channel is the conda channel
+type Channel struct {
+	url string
+	// apiURL is the url of the Anaconda API package listing of the channel
+	// (e.g. https://api.anaconda.org/package/conda-forge), the repodata.json
+	// is read when it is empty
+	apiURL  string
+	subdirs []string
+
+	mu       sync.Mutex
+	repodata map[string]*subdirRepodata
+}
+
+// NewChannel creates the repository of the channel with the given url
+// The subdirs are the platforms the packages are looked for in (e.g. linux-64),
+// the noarch packages are always looked for
+func NewChannel(channelURL string, subdirs []string) *Channel {
+	channel := &Channel{
+		url:      strings.TrimSuffix(channelURL, "/"),
+		subdirs:  subdirs,
+		repodata: map[string]*subdirRepodata{},
+	}
+	if !containsString(channel.subdirs, NoarchSubdir) {
+		channel.subdirs = append(append([]string{}, subdirs...), NoarchSubdir)
+	}
+	if name, ok := strings.CutPrefix(channel.url, DefaultChannelAlias+"/"); ok {
+		// the labels of the channel are not distinguished by the API
+		owner, _, _ := strings.Cut(name, "/")
+		channel.apiURL = AnacondaAPIURL + "/package/" + owner
+	}
+	return channel
+}
+
+func (c *Channel) GetRepositoryName() string {
+	return "conda"
+}
+
+// GetPackageInfo returns the highest version of the package matching the dependency
+// the builds of the same version are ordered by the build number
+func (c *Channel) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	var records []condaRecord
+	var info anacondaPackage
+	var err error
+	if c.apiURL != "" {
+		info, err = c.fetchPackage(ctx, dependency)
+		for _, file := range info.Files {
+			record := file.Attrs
+			if record.Version == "" {
+				record.Version = file.Version
+			}
+			if record.Name == "" {
+				record.Name = info.Name
+			}
+			records = append(records, record)
+		}
+	} else {
+		records, err = c.repodataRecords(ctx, dependency)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: errPackageNotFound}
+	}
+
+	var best *condaRecord
+	for i, record := range records {
+		if !containsString(c.subdirs, record.Subdir) || !matchesSpecifiers(record.Version, dependency.Versions) {
+			continue
+		}
+		if best == nil || newerRecord(record, *best) {
+			best = &records[i]
+		}
+	}
+	if best == nil {
+		err := fmt.Errorf("no build of %s matches the version specifiers", strings.Join(c.subdirs, ", "))
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	meta := &interfaces.PackageMeta{
+		Name:        best.Name,
+		Version:     best.Version,
+		License:     best.License,
+		Description: info.Summary,
+		Homepage:    info.Home,
+		Repository:  info.DevURL,
+		Language:    "conda",
+		Index:       c.url,
+	}
+	if meta.License == "" {
+		meta.License = info.License
+	}
+	if meta.License != "" {
+		meta.LicenseSource = interfaces.LicenseSourceField
+	}
+	// the requirements that cannot be parsed are skipped, they cannot be resolved anyway
+	for _, depend := range best.Depends {
+		spec, err := parseMatchSpec(depend)
+		// the virtual packages (e.g. __glibc) describe the system, they are not installed
+		if err != nil || strings.HasPrefix(depend, "__") {
+			continue
+		}
+		required := spec.dependency()
+		required.Index = c.url
+		meta.Requires = append(meta.Requires, required)
+	}
+	return meta, nil
+}
+
+// newerRecord reports whether the record is newer than the other one
+func newerRecord(record, other condaRecord) bool {
+	if c := compareVersions(record.Version, other.Version); c != 0 {
+		return c > 0
+	}
+	if record.BuildNumber != other.BuildNumber {
+		return record.BuildNumber > other.BuildNumber
+	}
+	return record.Timestamp > other.Timestamp
+}
+
+// fetchPackage downloads the package from the Anaconda API
+func (c *Channel) fetchPackage(ctx context.Context, dependency interfaces.Dependency) (anacondaPackage, error) {
+	var info anacondaPackage
+	_, err := c.fetch(ctx, dependency, c.apiURL+"/"+strings.ToLower(dependency.Name), &info)
+	return info, err
+}
+
+// repodataRecords returns the records of the package in all subdirs of the channel
+func (c *Channel) repodataRecords(ctx context.Context, dependency interfaces.Dependency) ([]condaRecord, error) {
+	var records []condaRecord
+	for _, subdir := range c.subdirs {
+		c.mu.Lock()
+		repodata, ok := c.repodata[subdir]
+		if !ok {
+			repodata = &subdirRepodata{}
+			c.repodata[subdir] = repodata
+		}
+		c.mu.Unlock()
+
+		repodata.once.Do(func() {
+			repodata.packages, repodata.err = c.fetchRepodata(ctx, dependency, subdir)
+		})
+		// the repodata can be downloaded for another package, the error is reported for this one
+		var pkgErr *interfaces.PackageError
+		if errors.As(repodata.err, &pkgErr) {
+			return nil, &interfaces.PackageError{Package: dependency.Name, Stage: pkgErr.Stage, Err: pkgErr.Err}
+		}
+		if repodata.err != nil {
+			return nil, repodata.err
+		}
+		records = append(records, repodata.packages[strings.ToLower(dependency.Name)]...)
+	}
+	return records, nil
+}
+
+// fetchRepodata downloads the repodata.json of the subdir, the missing subdir has no packages
+func (c *Channel) fetchRepodata(ctx context.Context, dependency interfaces.Dependency, subdir string) (map[string][]condaRecord, error) {
+	var response repodataResponse
+	found, err := c.fetch(ctx, dependency, c.url+"/"+subdir+"/repodata.json", &response)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	packages := map[string][]condaRecord{}
+	for _, records := range []map[string]condaRecord{response.Packages, response.PackagesConda} {
+		for _, record := range records {
+			if record.Subdir == "" {
+				record.Subdir = subdir
+			}
+			name := strings.ToLower(record.Name)
+			packages[name] = append(packages[name], record)
+		}
+	}
+	return packages, nil
+}
+
+// fetch downloads and decodes the JSON response, it reports false when the url is not found
+func (c *Channel) fetch(ctx context.Context, dependency interfaces.Dependency, url string, response interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("unexpected response status %s", resp.Status)
+		return false, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return false, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+	}
+	return true, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
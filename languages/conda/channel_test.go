@@ -0,0 +1,212 @@
+package conda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+const linuxRepodata = `{
+	"info": {"subdir": "linux-64"},
+	"packages": {
+		"numpy-1.25.2-py311h64a7726_0.tar.bz2": {"name": "numpy", "version": "1.25.2", "build_number": 0, "license": "BSD-3-Clause", "depends": ["python >=3.11,<3.12.0a0"]}
+	},
+	"packages.conda": {
+		"numpy-1.26.2-py311h64a7726_0.conda": {"name": "numpy", "version": "1.26.2", "build_number": 0, "license": "BSD-3-Clause", "depends": ["libblas >=3.9.0,<4.0a0", "python >=3.11,<3.12.0a0", "__glibc >=2.17"]},
+		"numpy-1.26.2-py311h64a7726_1.conda": {"name": "numpy", "version": "1.26.2", "build_number": 1, "license": "BSD-3-Clause", "depends": ["libblas >=3.9.0,<4.0a0", "python >=3.11,<3.12.0a0", "python_abi 3.11.* *_cp311"]},
+		"numpy-2.0.0rc1-py311h64a7726_0.conda": {"name": "numpy", "version": "2.0.0rc1", "build_number": 0, "license": "BSD-3-Clause"}
+	}
+}`
+
+const noarchRepodata = `{
+	"info": {"subdir": "noarch"},
+	"packages.conda": {
+		"tzdata-2023c-h71feb2d_0.conda": {"name": "tzdata", "version": "2023c", "license": "LicenseRef-Public-Domain"}
+	}
+}`
+
+func TestChannelRepodata(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/mirror/linux-64/repodata.json":
+			w.Write([]byte(linuxRepodata))
+		case "/mirror/noarch/repodata.json":
+			w.Write([]byte(noarchRepodata))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	channel := NewChannel(server.URL+"/mirror/", []string{"linux-64"})
+	if channel.apiURL != "" {
+		t.Fatalf("Expected the mirror to be read by the repodata, got the API %s", channel.apiURL)
+	}
+
+	meta, err := channel.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "numpy",
+		Versions: []interfaces.VersionSpecifier{{Operator: "<", Value: "2.0.0a0"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &interfaces.PackageMeta{
+		Name:          "numpy",
+		Version:       "1.26.2",
+		License:       "BSD-3-Clause",
+		LicenseSource: interfaces.LicenseSourceField,
+		Language:      "conda",
+		Index:         server.URL + "/mirror",
+		Requires: []interfaces.Dependency{
+			{Name: "libblas", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "3.9.0"}, {Operator: "<", Value: "4.0a0"}}, Index: server.URL + "/mirror"},
+			{Name: "python", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "3.11"}, {Operator: "<", Value: "3.12.0a0"}}, Index: server.URL + "/mirror"},
+			{Name: "python_abi", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "3.11.*"}}, Index: server.URL + "/mirror"},
+		},
+	}
+	if diff := cmp.Diff(expected, meta); diff != "" {
+		t.Errorf("Package meta mismatch (-want +got):\n%s", diff)
+	}
+
+	meta, err = channel.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "tzdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Version != "2023c" || meta.License != "LicenseRef-Public-Domain" {
+		t.Errorf("Expected the noarch package tzdata 2023c, got %s %s", meta.Version, meta.License)
+	}
+
+	_, err = channel.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "missing"})
+	if !errors.Is(err, errPackageNotFound) {
+		t.Errorf("Expected the package not to be found, got %v", err)
+	}
+
+	// the repodata of every subdir is downloaded only once
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestChannelAnacondaAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/package/conda-forge/requests" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"name": "requests",
+			"summary": "Python HTTP for Humans",
+			"license": "Apache-2.0",
+			"home": "https://requests.readthedocs.io",
+			"dev_url": "https://github.com/psf/requests",
+			"files": [
+				{"version": "2.31.0", "basename": "noarch/requests-2.31.0-pyhd8ed1ab_0.conda", "attrs": {"subdir": "noarch", "build_number": 0, "license": "Apache-2.0", "depends": ["certifi >=2017.4.17", "python >=3.7"]}},
+				{"version": "2.32.0", "basename": "osx-arm64/requests-2.32.0-0.conda", "attrs": {"subdir": "osx-arm64", "license": "Apache-2.0"}}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	channel := NewChannel(DefaultChannelAlias+"/conda-forge/label/main", []string{"linux-64"})
+	if expected := AnacondaAPIURL + "/package/conda-forge"; channel.apiURL != expected {
+		t.Fatalf("Expected the API %s, got %s", expected, channel.apiURL)
+	}
+	channel.apiURL = server.URL + "/package/conda-forge"
+
+	meta, err := channel.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "requests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &interfaces.PackageMeta{
+		Name:          "requests",
+		Version:       "2.31.0",
+		License:       "Apache-2.0",
+		LicenseSource: interfaces.LicenseSourceField,
+		Description:   "Python HTTP for Humans",
+		Homepage:      "https://requests.readthedocs.io",
+		Repository:    "https://github.com/psf/requests",
+		Language:      "conda",
+		Index:         DefaultChannelAlias + "/conda-forge/label/main",
+		Requires: []interfaces.Dependency{
+			{Name: "certifi", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "2017.4.17"}}, Index: DefaultChannelAlias + "/conda-forge/label/main"},
+			{Name: "python", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "3.7"}}, Index: DefaultChannelAlias + "/conda-forge/label/main"},
+		},
+	}
+	if diff := cmp.Diff(expected, meta); diff != "" {
+		t.Errorf("Package meta mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = channel.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "missing"})
+	if !errors.Is(err, errPackageNotFound) {
+		t.Errorf("Expected the package not to be found, got %v", err)
+	}
+}
+
+// testRepository returns the package with the name of the repository
+type testRepository string
+
+func (r testRepository) GetRepositoryName() string {
+	return string(r)
+}
+
+func (r testRepository) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	return &interfaces.PackageMeta{Name: dependency.Name, Index: string(r)}, nil
+}
+
+func TestChannelRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/main/linux-64/repodata.json":
+			w.Write([]byte(`{"packages": {"numpy-1.26.2-0.tar.bz2": {"name": "numpy", "version": "1.26.2", "depends": ["libblas"]}}}`))
+		case "/extra/linux-64/repodata.json":
+			w.Write([]byte(`{"packages": {"numpy-1.20.0-0.tar.bz2": {"name": "numpy", "version": "1.20.0"}, "tzdata-2023c-0.tar.bz2": {"name": "tzdata", "version": "2023c"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	primary, secondary := server.URL+"/main", server.URL+"/extra"
+	repository := newChannelRepository([]string{primary, secondary}, []string{"linux-64"}, testRepository("pip"))
+
+	packages := []struct {
+		dependency interfaces.Dependency
+		version    string
+		index      string
+	}{
+		// the package of the first channel is looked for in all channels in the priority order
+		{interfaces.Dependency{Name: "numpy", Index: primary}, "1.26.2", primary},
+		{interfaces.Dependency{Name: "tzdata", Index: primary}, "2023c", secondary},
+		// the package of the other channel comes only from it
+		{interfaces.Dependency{Name: "numpy", Index: secondary}, "1.20.0", secondary},
+		{interfaces.Dependency{Name: "requests"}, "", "pip"},
+	}
+	for _, pkg := range packages {
+		meta, err := repository.GetPackageInfo(context.Background(), pkg.dependency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.Version != pkg.version || meta.Index != pkg.index {
+			t.Errorf("Expected %s %s from %s, got %s from %s", pkg.dependency.Name, pkg.version, pkg.index, meta.Version, meta.Index)
+		}
+		for _, required := range meta.Requires {
+			if required.Index != primary {
+				t.Errorf("Expected %s required by %s to come from any channel, got %s", required.Name, meta.Name, required.Index)
+			}
+		}
+	}
+
+	_, err := repository.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "tzdata", Index: primary, Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "2022a"}}})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch error for the missing version, got %v", err)
+	}
+}
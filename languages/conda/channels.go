@@ -0,0 +1,71 @@
+package conda
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// DefaultChannelAlias is the url prefix of the channels given by the name e.g. conda-forge
+const DefaultChannelAlias = "https://conda.anaconda.org"
+
+// AnacondaAPIURL is the Anaconda API queried for the channels hosted on anaconda.org
+const AnacondaAPIURL = "https://api.anaconda.org"
+
+// defaultChannels are the channels of the defaults channel
+var defaultChannels = []string{"https://repo.anaconda.com/pkgs/main", "https://repo.anaconda.com/pkgs/r"}
+
+// NoarchSubdir is the subdir of the platform independent packages
+const NoarchSubdir = "noarch"
+
+// channelURLs returns the urls of the channel, the channel given by the name is prefixed
+// by the channel alias, the CONDA_CHANNEL_ALIAS environment variable replaces
+// the default alias (e.g. for the local mirror)
+// The defaults channel are the channels from the CONDA_DEFAULT_CHANNELS environment variable
+// separated by commas, the main and r channels of repo.anaconda.com by default
+func channelURLs(channel string) []string {
+	channel = strings.TrimSuffix(os.ExpandEnv(strings.TrimSpace(channel)), "/")
+	if channel == "defaults" {
+		if configured := os.Getenv("CONDA_DEFAULT_CHANNELS"); configured != "" {
+			var urls []string
+			for _, url := range strings.Split(configured, ",") {
+				if url = strings.TrimSpace(url); url != "" {
+					urls = append(urls, channelURLs(url)...)
+				}
+			}
+			return urls
+		}
+		return defaultChannels
+	}
+	if strings.Contains(channel, "://") {
+		return []string{channel}
+	}
+	alias := strings.TrimSuffix(os.Getenv("CONDA_CHANNEL_ALIAS"), "/")
+	if alias == "" {
+		alias = DefaultChannelAlias
+	}
+	return []string{alias + "/" + channel}
+}
+
+// DefaultSubdir returns the subdir of the packages for the current platform,
+// the CONDA_SUBDIR environment variable overrides it
+func DefaultSubdir() string {
+	if subdir := os.Getenv("CONDA_SUBDIR"); subdir != "" {
+		return subdir
+	}
+	system := map[string]string{"linux": "linux", "darwin": "osx", "windows": "win"}[runtime.GOOS]
+	architecture := map[string]string{
+		"amd64":   "64",
+		"386":     "32",
+		"arm64":   "arm64",
+		"ppc64le": "ppc64le",
+		"s390x":   "s390x",
+	}[runtime.GOARCH]
+	if system == "" || architecture == "" {
+		return "linux-64"
+	}
+	if system == "linux" && architecture == "arm64" {
+		architecture = "aarch64"
+	}
+	return system + "-" + architecture
+}
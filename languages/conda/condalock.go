@@ -0,0 +1,293 @@
+// Reads the environments locked by conda-lock https://conda.github.io/conda-lock/output/
+// The lock contains every conda and pip package of all locked platforms
+// pinned to the exact version with the names of the packages it depends on
+// The direct dependencies are read from the environment files the lock was created from,
+// when they are not next to the lock, the packages not required by any other package are direct
+package conda
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/languages/python"
+	"github.com/radiculaCZ/license-check/lockgraph"
+)
+
+type condaLockFile struct {
+	Metadata struct {
+		Channels []struct {
+			URL string `yaml:"url"`
+		} `yaml:"channels"`
+		Platforms []string `yaml:"platforms"`
+		// Sources are the files the lock was created from, relative to the lock
+		Sources []string `yaml:"sources"`
+	} `yaml:"metadata"`
+	Package []condaLockPackage `yaml:"package"`
+}
+
+type condaLockPackage struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	// Manager is conda or pip
+	Manager  string `yaml:"manager"`
+	Platform string `yaml:"platform"`
+	// Dependencies map the names of the required packages to their version constraints
+	Dependencies map[string]string `yaml:"dependencies"`
+	URL          string            `yaml:"url"`
+	// Category is the group of the package in the older locks, Categories in the newer ones
+	Category   string   `yaml:"category"`
+	Categories []string `yaml:"categories"`
+}
+
+// lockedPackage is the release locked for one or more platforms
+type lockedPackage struct {
+	id, name, version, channel string
+	groups                     []string
+	// dependencies are the lockID of the required packages
+	dependencies []string
+}
+
+// CondaLock represents the conda-lock.yml file
+type CondaLock struct {
+	requirements *python.RequirementsTxt
+	channels     []string
+	subdirs      []string
+}
+
+// NewCondaLock creates a new instance of the CondaLock struct
+func NewCondaLock() *CondaLock {
+	return &CondaLock{requirements: python.NewRequirementsTxt()}
+}
+
+func (c *CondaLock) GetDepFileType() string {
+	return "conda/conda-lock.yml"
+}
+
+// GetRepository returns the repository querying the channels of the lock
+// for the packages of the locked platforms
+func (c *CondaLock) GetRepository() interfaces.PackageRepository {
+	return newChannelRepository(c.channels, c.subdirs, c.requirements.GetRepository())
+}
+
+func (c *CondaLock) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return c.requirements.EvaluateMarkers(dependency, environment, extras)
+}
+
+// GetDependencies returns the locked packages pinned to the locked version,
+// the release locked for several platforms is returned once, the different versions
+// locked for the platforms are returned separately
+// The conda packages have the Index of the channel they come from, the pip packages none
+func (c *CondaLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var lock condaLockFile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid conda-lock file: %w", fileName, err)
+	}
+
+	c.channels = nil
+	for _, channel := range lock.Metadata.Channels {
+		for _, url := range channelURLs(channel.URL) {
+			if !containsString(c.channels, url) {
+				c.channels = append(c.channels, url)
+			}
+		}
+	}
+	c.subdirs = lock.Metadata.Platforms
+	if len(c.subdirs) == 0 {
+		c.subdirs = []string{DefaultSubdir()}
+	}
+
+	// the platforms can lock different versions of the package and the pip package
+	// can have the name of the conda package, so the packages are keyed by lockID
+	var packages []*lockedPackage
+	byID := map[string]*lockedPackage{}
+	// platformIDs map the manager, platform and name to the package locked for the platform
+	platformIDs := map[string]string{}
+	byName := map[string][]string{}
+	for _, pkg := range lock.Package {
+		id := lockID(pkg)
+		platformIDs[lockManager(pkg)+"/"+pkg.Platform+"/"+interfaces.NormalizeName(pkg.Name)] = id
+		locked, ok := byID[id]
+		if !ok {
+			locked = &lockedPackage{id: id, name: pkg.Name, version: pkg.Version}
+			if lockManager(pkg) != "pip" {
+				locked.channel = packageChannel(pkg.URL)
+				if locked.channel == "" && len(c.channels) > 0 {
+					locked.channel = c.channels[0]
+				}
+				if locked.channel != "" && !containsString(c.channels, locked.channel) {
+					c.channels = append(c.channels, locked.channel)
+				}
+			}
+			byID[id] = locked
+			byName[interfaces.NormalizeName(pkg.Name)] = append(byName[interfaces.NormalizeName(pkg.Name)], id)
+			packages = append(packages, locked)
+		}
+		categories := pkg.Categories
+		if pkg.Category != "" {
+			categories = append(categories, pkg.Category)
+		}
+		for _, category := range categories {
+			if !containsString(locked.groups, category) {
+				locked.groups = append(locked.groups, category)
+			}
+		}
+	}
+	// the required package is the one locked for the same platform,
+	// the pip packages can be satisfied by the conda packages
+	for _, pkg := range lock.Package {
+		locked := byID[lockID(pkg)]
+		for _, name := range sortedKeys(pkg.Dependencies) {
+			for _, manager := range []string{lockManager(pkg), "conda", "pip"} {
+				if id, ok := platformIDs[manager+"/"+pkg.Platform+"/"+interfaces.NormalizeName(name)]; ok {
+					if !containsString(locked.dependencies, id) {
+						locked.dependencies = append(locked.dependencies, id)
+					}
+					break
+				}
+			}
+		}
+	}
+
+	var roots []lockgraph.Root
+	for _, source := range lock.Metadata.Sources {
+		for _, name := range environmentRoots(filepath.Join(filepath.Dir(fileName), source)) {
+			for _, id := range byName[name] {
+				roots = append(roots, lockgraph.Root{ID: id})
+			}
+		}
+	}
+	if len(roots) == 0 {
+		for _, id := range unrequiredPackages(byID) {
+			roots = append(roots, lockgraph.Root{ID: id})
+		}
+	}
+	graph := map[string]lockgraph.Package{}
+	for id, locked := range byID {
+		pkg := lockgraph.Package{Name: locked.name}
+		for _, dependency := range locked.dependencies {
+			pkg.Dependencies = append(pkg.Dependencies, lockgraph.Edge{ID: dependency})
+		}
+		graph[id] = pkg
+	}
+	walked := lockgraph.New(graph)
+	walked.Walk(roots)
+
+	dependencies := make([]interfaces.Dependency, 0, len(packages))
+	for _, locked := range packages {
+		dependency := interfaces.Dependency{
+			Name:   locked.name,
+			File:   fileName,
+			Groups: locked.groups,
+			Index:  locked.channel,
+		}
+		if node, ok := walked.Node(locked.id); ok {
+			dependency.Path = node.Path
+		}
+		if locked.version != "" {
+			dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: locked.version}}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// lockManager returns the manager of the locked package, conda when it is not set
+func lockManager(pkg condaLockPackage) string {
+	if pkg.Manager == "" {
+		return "conda"
+	}
+	return pkg.Manager
+}
+
+// lockID identifies the locked release e.g. conda:openssl@3.1.4 or pip:requests@2.31.0
+func lockID(pkg condaLockPackage) string {
+	return lockManager(pkg) + ":" + interfaces.NormalizeName(pkg.Name) + "@" + pkg.Version
+}
+
+// packageChannel returns the channel of the package url
+// e.g. https://conda.anaconda.org/conda-forge/linux-64/numpy-1.26.0-py311h64a7726_0.conda
+// is from the https://conda.anaconda.org/conda-forge channel
+func packageChannel(packageURL string) string {
+	subdir := strings.LastIndex(packageURL, "/")
+	if subdir < 0 {
+		return ""
+	}
+	channel := strings.LastIndex(packageURL[:subdir], "/")
+	if channel < 0 {
+		return ""
+	}
+	return packageURL[:channel]
+}
+
+// environmentRoots returns the keys of the dependencies of the environment file,
+// the other sources (e.g. pyproject.toml) and the missing files have none
+func environmentRoots(fileName string) []string {
+	extension := filepath.Ext(fileName)
+	if extension != ".yml" && extension != ".yaml" || filepath.Base(fileName) == "meta.yaml" {
+		return nil
+	}
+	if _, err := os.Stat(fileName); err != nil {
+		return nil
+	}
+	dependencies, _, err := readEnvironment(fileName, python.NewRequirementsTxt())
+	if err != nil {
+		return nil
+	}
+	var roots []string
+	for _, dependency := range dependencies {
+		roots = append(roots, interfaces.NormalizeName(dependency.Name))
+	}
+	return roots
+}
+
+// unrequiredPackages returns the ids of the packages no other package depends on
+func unrequiredPackages(packages map[string]*lockedPackage) []string {
+	required := map[string]bool{}
+	for _, locked := range packages {
+		for _, dependency := range locked.dependencies {
+			required[dependency] = true
+		}
+	}
+	var roots []string
+	for id := range packages {
+		if !required[id] {
+			roots = append(roots, id)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package conda
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCondaLock(t *testing.T) {
+	t.Setenv("CONDA_CHANNEL_ALIAS", "")
+
+	condaLock := NewCondaLock()
+	fileName := filepath.Join("testdata", "lock", "conda-lock.yml")
+	dependencies, err := condaLock.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type locked struct {
+		name, version, groups, path, index string
+	}
+	var got []locked
+	for dependency := range dependencies {
+		got = append(got, locked{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			groups:  strings.Join(dependency.Groups, ","),
+			path:    strings.Join(dependency.Path, " > "),
+			index:   dependency.Index,
+		})
+	}
+
+	condaForge := DefaultChannelAlias + "/conda-forge"
+	expected := []locked{
+		{name: "libblas", version: "3.9.0", groups: "main", path: "numpy", index: condaForge},
+		{name: "libopenblas", version: "0.3.25", groups: "main", path: "numpy > libblas", index: condaForge},
+		{name: "numpy", version: "1.26.2", groups: "main", index: condaForge},
+		{name: "python", version: "3.11.6", groups: "main", path: "numpy", index: condaForge},
+		{name: "pytest", version: "7.4.3", groups: "dev", index: condaForge},
+		{name: "requests", version: "2.31.0", groups: "main"},
+		{name: "certifi", version: "2023.11.17", groups: "main", path: "requests"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"linux-64", "osx-arm64"}, condaLock.subdirs); diff != "" {
+		t.Errorf("Subdirs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCondaLockWithoutSources(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "conda-lock.yml")
+	data := `version: 1
+metadata:
+  channels:
+  - url: https://mirror.example.com/conda-forge
+  platforms: [linux-64]
+package:
+- name: numpy
+  version: 1.26.2
+  manager: conda
+  dependencies: {python: '>=3.11'}
+  url: https://mirror.example.com/conda-forge/linux-64/numpy-1.26.2-py311h64a7726_0.conda
+  categories: [main]
+- name: python
+  version: 3.11.6
+  manager: conda
+  url: https://mirror.example.com/bioconda/linux-64/python-3.11.6-hab00c5b_0_cpython.conda
+  categories: [main]
+`
+	writeFile(t, fileName, data)
+
+	condaLock := NewCondaLock()
+	dependencies, err := condaLock.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dependency := range dependencies {
+		got = append(got, dependency.Name+" "+strings.Join(dependency.Path, " > ")+" "+dependency.Index)
+	}
+	// the packages not required by other packages are direct
+	expected := []string{
+		"numpy  https://mirror.example.com/conda-forge",
+		"python numpy https://mirror.example.com/bioconda",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"https://mirror.example.com/conda-forge", "https://mirror.example.com/bioconda"}, condaLock.channels); diff != "" {
+		t.Errorf("Channels mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestCondaLockPlatformVersions tests that the versions locked for the different platforms
+// are all returned and the pip package is not merged with the conda package of the same name
+func TestCondaLockPlatformVersions(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "conda-lock.yml")
+	data := `version: 1
+metadata:
+  channels:
+  - url: https://conda.anaconda.org/conda-forge
+  platforms: [linux-64, osx-arm64]
+package:
+- name: python
+  version: 3.11.6
+  manager: conda
+  platform: linux-64
+  dependencies: {openssl: '>=3.1'}
+  url: https://conda.anaconda.org/conda-forge/linux-64/python-3.11.6-hab00c5b_0_cpython.conda
+  category: main
+- name: python
+  version: 3.11.6
+  manager: conda
+  platform: osx-arm64
+  dependencies: {openssl: '>=3.1'}
+  url: https://conda.anaconda.org/conda-forge/osx-arm64/python-3.11.6-h47c9636_0_cpython.conda
+  category: main
+- name: openssl
+  version: 3.1.4
+  manager: conda
+  platform: linux-64
+  url: https://conda.anaconda.org/conda-forge/linux-64/openssl-3.1.4-hd590300_0.conda
+  category: main
+- name: openssl
+  version: 3.2.0
+  manager: conda
+  platform: osx-arm64
+  url: https://conda.anaconda.org/conda-forge/osx-arm64/openssl-3.2.0-h0d3ecfb_1.conda
+  category: main
+- name: packaging
+  version: '23.2'
+  manager: conda
+  platform: linux-64
+  dependencies: {python: '>=3.7'}
+  url: https://conda.anaconda.org/conda-forge/noarch/packaging-23.2-pyhd8ed1ab_0.conda
+  category: main
+- name: packaging
+  version: '23.2'
+  manager: pip
+  platform: linux-64
+  url: https://files.pythonhosted.org/packages/packaging-23.2-py3-none-any.whl
+  category: main
+`
+	writeFile(t, fileName, data)
+
+	dependencies, err := NewCondaLock().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dependency := range dependencies {
+		got = append(got, dependency.Name+"=="+dependency.PinnedVersion()+" "+strings.Join(dependency.Path, " > ")+" "+dependency.Index)
+	}
+	condaForge := "https://conda.anaconda.org/conda-forge"
+	expected := []string{
+		"python==3.11.6 packaging " + condaForge,
+		"openssl==3.1.4 packaging > python " + condaForge,
+		"openssl==3.2.0 packaging > python " + condaForge,
+		"packaging==23.2  " + condaForge,
+		"packaging==23.2  ",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
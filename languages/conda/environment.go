@@ -0,0 +1,175 @@
+// Implement the depfiles interface for the conda package manager
+// The conda environments mix the conda packages from the conda channels
+// and the python packages installed by pip, the pip packages are read
+// by the python requirements parser and downloaded from the python package indexes
+package conda
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/languages/python"
+)
+
+// environmentFile is the conda environment https://docs.conda.io/projects/conda/en/latest/user-guide/tasks/manage-environments.html#create-env-file-manually
+// The dependencies are the match specs and the pip section with the pip requirements
+type environmentFile struct {
+	Channels     []string    `yaml:"channels"`
+	Dependencies []yaml.Node `yaml:"dependencies"`
+}
+
+// EnvironmentYml represents the conda environment.yml file
+type EnvironmentYml struct {
+	requirements *python.RequirementsTxt
+	// channels are the channel urls in the priority order
+	channels []string
+}
+
+// NewEnvironmentYml creates a new instance of the EnvironmentYml struct
+func NewEnvironmentYml() *EnvironmentYml {
+	return &EnvironmentYml{requirements: python.NewRequirementsTxt()}
+}
+
+func (e *EnvironmentYml) GetDepFileType() string {
+	return "conda/environment.yml"
+}
+
+// GetRepository returns the repository querying the channels of the environment
+// for the packages of the current platform, the pip packages are downloaded
+// from the indexes configured in the pip section
+func (e *EnvironmentYml) GetRepository() interfaces.PackageRepository {
+	return newChannelRepository(e.channels, []string{DefaultSubdir()}, e.requirements.GetRepository())
+}
+
+// EvaluateMarkers evaluates the environment markers of the pip packages,
+// the conda packages do not have any
+func (e *EnvironmentYml) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return e.requirements.EvaluateMarkers(dependency, environment, extras)
+}
+
+// GetDependencies returns the conda packages followed by the pip packages
+func (e *EnvironmentYml) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	dependencies, channels, err := readEnvironment(fileName, e.requirements)
+	if err != nil {
+		return nil, err
+	}
+	e.channels = channels
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// readEnvironment reads the dependencies and the channel urls of the environment
+// The conda packages have the Index of the channel given in the match spec
+// or the Index of the first channel, the pip section is read as the requirements file
+// with its items on the lines of the environment, so the lines of the pip packages are kept
+func readEnvironment(fileName string, requirements *python.RequirementsTxt) ([]interfaces.Dependency, []string, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var file environmentFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid environment file: %w", fileName, err)
+	}
+
+	channels := environmentChannels(file.Channels)
+	if len(channels) == 0 {
+		return nil, nil, fmt.Errorf("%s: the environment has no channels", fileName)
+	}
+	var dependencies []interfaces.Dependency
+	var pipLines []string
+	for _, node := range file.Dependencies {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			spec, err := parseMatchSpec(node.Value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s:%d: %w", fileName, node.Line, err)
+			}
+			dependency := spec.dependency()
+			dependency.File = fileName
+			dependency.Line = node.Line
+			dependency.Index = channels[0]
+			if spec.channel != "" {
+				urls := channelURLs(spec.channel)
+				if len(urls) == 0 {
+					return nil, nil, fmt.Errorf("%s:%d: the channel %s has no urls", fileName, node.Line, spec.channel)
+				}
+				dependency.Index = urls[0]
+				if !containsString(channels, dependency.Index) {
+					channels = append(channels, dependency.Index)
+				}
+			}
+			dependencies = append(dependencies, dependency)
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value != "pip" {
+					continue
+				}
+				// the items are kept at their lines for the error messages, the items
+				// of the flow sequence share the line, so they follow the first one
+				for _, item := range node.Content[i+1].Content {
+					for len(pipLines) < item.Line-1 {
+						pipLines = append(pipLines, "")
+					}
+					pipLines = append(pipLines, item.Value)
+				}
+			}
+		}
+	}
+
+	if len(pipLines) > 0 {
+		pipDependencies, err := requirements.ReadRequirements(fileName, strings.Join(pipLines, "\n")+"\n")
+		if err != nil {
+			return nil, nil, err
+		}
+		dependencies = append(dependencies, pipDependencies...)
+	}
+	return dependencies, channels, nil
+}
+
+// environmentChannels returns the urls of the channels in the priority order
+// the defaults channel is added as the last one unless nodefaults is listed
+func environmentChannels(names []string) []string {
+	var urls []string
+	defaults := true
+	for _, name := range names {
+		switch name {
+		case "nodefaults":
+			defaults = false
+			continue
+		case "defaults":
+			defaults = false
+		}
+		for _, url := range channelURLs(name) {
+			if !containsString(urls, url) {
+				urls = append(urls, url)
+			}
+		}
+	}
+	if defaults || len(urls) == 0 {
+		for _, url := range channelURLs("defaults") {
+			if !containsString(urls, url) {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,124 @@
+package conda
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestEnvironmentYml(t *testing.T) {
+	t.Setenv("CONDA_CHANNEL_ALIAS", "https://mirror.example.com/conda/")
+	t.Setenv("PIP_INDEX_URL", "")
+	t.Setenv("PIP_EXTRA_INDEX_URL", "")
+
+	environment := NewEnvironmentYml()
+	fileName := filepath.Join("testdata", "environment.yml")
+	dependencies, err := environment.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []interfaces.Dependency
+	for dependency := range dependencies {
+		got = append(got, dependency)
+	}
+
+	condaForge := "https://mirror.example.com/conda/conda-forge"
+	bioconda := "https://mirror.example.com/conda/bioconda"
+	expected := []interfaces.Dependency{
+		{Name: "python", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "3.11.*"}}, File: fileName, Line: 6, Index: condaForge},
+		{Name: "numpy", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "1.24"}, {Operator: "<", Value: "2"}}, File: fileName, Line: 7, Index: condaForge},
+		{Name: "samtools", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "1.18"}}, File: fileName, Line: 8, Index: bioconda},
+		{Name: "pip", File: fileName, Line: 9, Index: condaForge},
+		{Name: "requests", Extras: []string{"socks"}, Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "2.31"}}, File: fileName, Line: 12},
+		{Name: "rich", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "13.7.0"}}, File: filepath.Join("testdata", "requirements.txt"), Line: 1},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{condaForge, bioconda}, environment.channels); diff != "" {
+		t.Errorf("Channels mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestEnvironmentYmlPipFlowSequence tests that all pip items of the flow sequence are read
+func TestEnvironmentYmlPipFlowSequence(t *testing.T) {
+	t.Setenv("PIP_INDEX_URL", "")
+	t.Setenv("PIP_EXTRA_INDEX_URL", "")
+	fileName := filepath.Join(t.TempDir(), "environment.yml")
+	data := "channels:\n  - conda-forge\ndependencies:\n  - pip\n  - pip: [requests, flask]\n"
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dependencies, err := NewEnvironmentYml().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dependency := range dependencies {
+		got = append(got, dependency.Name)
+	}
+	if diff := cmp.Diff([]string{"pip", "requests", "flask"}, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestEnvironmentYmlNoChannels tests that the environment without any channel url is an error
+func TestEnvironmentYmlNoChannels(t *testing.T) {
+	t.Setenv("CONDA_DEFAULT_CHANNELS", ",")
+	environments := map[string]string{
+		"no channels":      "dependencies:\n  - numpy\n",
+		"defaults channel": "channels:\n  - conda-forge\ndependencies:\n  - defaults::numpy\n",
+	}
+	for name, data := range environments {
+		fileName := filepath.Join(t.TempDir(), "environment.yml")
+		if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewEnvironmentYml().GetDependencies(context.Background(), fileName); err == nil {
+			t.Errorf("%s: expected an error for the environment without the channel urls", name)
+		}
+	}
+}
+
+func TestEnvironmentChannels(t *testing.T) {
+	t.Setenv("CONDA_CHANNEL_ALIAS", "")
+	t.Setenv("CONDA_DEFAULT_CHANNELS", "")
+
+	channels := map[string][]string{
+		"conda-forge":            {DefaultChannelAlias + "/conda-forge", defaultChannels[0], defaultChannels[1]},
+		"conda-forge,nodefaults": {DefaultChannelAlias + "/conda-forge"},
+		"defaults,conda-forge":   {defaultChannels[0], defaultChannels[1], DefaultChannelAlias + "/conda-forge"},
+		"https://mirror.example.com/channel/,nodefaults": {"https://mirror.example.com/channel"},
+		"": {defaultChannels[0], defaultChannels[1]},
+	}
+	for names, expected := range channels {
+		var list []string
+		if names != "" {
+			list = strings.Split(names, ",")
+		}
+		if diff := cmp.Diff(expected, environmentChannels(list)); diff != "" {
+			t.Errorf("Channels of %q mismatch (-want +got):\n%s", names, diff)
+		}
+	}
+
+	t.Setenv("CONDA_DEFAULT_CHANNELS", "https://mirror.example.com/main, internal")
+	t.Setenv("CONDA_CHANNEL_ALIAS", "https://mirror.example.com")
+	if diff := cmp.Diff([]string{"https://mirror.example.com/main", "https://mirror.example.com/internal"}, environmentChannels(nil)); diff != "" {
+		t.Errorf("Configured default channels mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func writeFile(t *testing.T, fileName string, data string) {
+	t.Helper()
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,124 @@
+package conda
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// matchSpecName is the package name at the start of the match spec
+var matchSpecName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*`)
+
+// matchSpecVersion is the version of the bracket options e.g. numpy[version='>=1.21']
+var matchSpecVersion = regexp.MustCompile(`version\s*=\s*(?:'([^']*)'|"([^"]*)"|([^,\]]+))`)
+
+// versionOperators are the operators of the version constraints, the longer ones first
+var versionOperators = []string{"===", "==", "!=", ">=", "<=", "~=", ">", "<", "="}
+
+// matchSpec is the conda package specification https://docs.conda.io/projects/conda-build/en/latest/resources/package-spec.html#package-match-specifications
+// e.g. conda-forge::numpy >=1.21,<2 or numpy=1.26.0=py311h64a7726_0
+type matchSpec struct {
+	// channel is the channel the package has to come from, empty for any channel
+	channel  string
+	name     string
+	versions []interfaces.VersionSpecifier
+}
+
+// parseMatchSpec parses the match spec, the build string is ignored
+// The version is an exact version (1.26.0), a version prefix (1.26.* or =1.26)
+// or the constraints separated by commas (>=1.21,<2), the alternatives separated by |
+// cannot be expressed by the version specifiers and they match any version
+func parseMatchSpec(spec string) (matchSpec, error) {
+	var parsed matchSpec
+	spec = strings.TrimSpace(spec)
+	if before, after, ok := strings.Cut(spec, "::"); ok {
+		parsed.channel = strings.TrimSpace(before)
+		spec = strings.TrimSpace(after)
+	}
+
+	parsed.name = matchSpecName.FindString(spec)
+	if parsed.name == "" {
+		return parsed, fmt.Errorf("invalid package specification %q", spec)
+	}
+	rest := strings.TrimSpace(spec[len(parsed.name):])
+
+	if options, ok := strings.CutPrefix(rest, "["); ok {
+		if match := matchSpecVersion.FindStringSubmatch(options); match != nil {
+			rest = match[1] + match[2] + match[3]
+		} else {
+			rest = ""
+		}
+	}
+
+	var version string
+	if strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "==") {
+		// numpy=1.26 is the version prefix, numpy=1.26.0=<build> the exact version
+		parts := strings.Split(rest[1:], "=")
+		version = parts[0]
+		if len(parts) == 1 && !strings.HasSuffix(version, "*") {
+			version = "=" + version
+		}
+	} else {
+		// the version and the build are separated by the space
+		fields := strings.Fields(rest)
+		if len(fields) > 0 {
+			version = fields[0]
+			// the operator can be separated from the version e.g. >= 1.21
+			if len(fields) > 1 && strings.Trim(version, "=!<>~") == "" {
+				version += fields[1]
+			}
+		}
+	}
+
+	versions, err := parseVersionSpec(version)
+	if err != nil {
+		return parsed, fmt.Errorf("package %s: %w", parsed.name, err)
+	}
+	parsed.versions = versions
+	return parsed, nil
+}
+
+// parseVersionSpec converts the conda version constraints to the version specifiers
+// The bare version is the exact version, =1.26 is the version prefix 1.26.*
+func parseVersionSpec(version string) ([]interfaces.VersionSpecifier, error) {
+	if version == "" || version == "*" || strings.Contains(version, "|") {
+		return nil, nil
+	}
+
+	var versions []interfaces.VersionSpecifier
+	for _, constraint := range strings.Split(version, ",") {
+		constraint = strings.TrimSpace(constraint)
+		operator := ""
+		for _, candidate := range versionOperators {
+			if strings.HasPrefix(constraint, candidate) {
+				operator = candidate
+				break
+			}
+		}
+		value := strings.TrimSpace(constraint[len(operator):])
+		if value == "" {
+			return nil, fmt.Errorf("invalid version constraint %q", constraint)
+		}
+		switch operator {
+		case "":
+			operator = "=="
+		case "=":
+			operator = "=="
+			if !strings.HasSuffix(value, "*") {
+				value += ".*"
+			}
+		}
+		if value == "*" {
+			continue
+		}
+		versions = append(versions, interfaces.VersionSpecifier{Operator: operator, Value: value})
+	}
+	return versions, nil
+}
+
+// dependency converts the match spec to the dependency
+func (m matchSpec) dependency() interfaces.Dependency {
+	return interfaces.Dependency{Name: m.name, Versions: m.versions}
+}
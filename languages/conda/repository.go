@@ -0,0 +1,75 @@
+package conda
+
+import (
+	"context"
+	"errors"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// channelRepository queries the conda channels for the conda packages
+// and the pip repository for the packages installed by pip
+// The conda packages have the Index set to the channel they come from,
+// the package with the Index of the first channel can come from any channel,
+// the channels are queried in the order of their priority
+// The pip packages do not have any channel set
+type channelRepository struct {
+	channels []*Channel
+	pip      interfaces.PackageRepository
+}
+
+// newChannelRepository creates the repository of the channels in the priority order
+func newChannelRepository(channelURLs []string, subdirs []string, pip interfaces.PackageRepository) *channelRepository {
+	repository := &channelRepository{pip: pip}
+	for _, channelURL := range channelURLs {
+		if repository.channel(channelURL) == nil {
+			repository.channels = append(repository.channels, NewChannel(channelURL, subdirs))
+		}
+	}
+	return repository
+}
+
+func (r *channelRepository) GetRepositoryName() string {
+	return "conda"
+}
+
+func (r *channelRepository) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	channel := r.channel(dependency.Index)
+	if channel == nil {
+		return r.pip.GetPackageInfo(ctx, dependency)
+	}
+	if channel != r.channels[0] {
+		return r.withPriority(channel.GetPackageInfo(ctx, dependency))
+	}
+
+	var meta *interfaces.PackageMeta
+	var err error
+	for _, channel := range r.channels {
+		meta, err = channel.GetPackageInfo(ctx, dependency)
+		if !errors.Is(err, errPackageNotFound) {
+			break
+		}
+	}
+	return r.withPriority(meta, err)
+}
+
+// withPriority lets the dependencies of the package come from any channel
+func (r *channelRepository) withPriority(meta *interfaces.PackageMeta, err error) (*interfaces.PackageMeta, error) {
+	if err != nil {
+		return nil, err
+	}
+	for i := range meta.Requires {
+		meta.Requires[i].Index = r.channels[0].url
+	}
+	return meta, nil
+}
+
+// channel returns the channel with the url, nil for the unknown channel
+func (r *channelRepository) channel(channelURL string) *Channel {
+	for _, channel := range r.channels {
+		if channel.url == channelURL {
+			return channel
+		}
+	}
+	return nil
+}
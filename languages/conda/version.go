@@ -0,0 +1,158 @@
+package conda
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// versionComponent splits the version segment to the numbers and the strings
+var versionComponent = regexp.MustCompile(`[0-9]+|[a-z]+`)
+
+// compareVersions compares the conda versions https://docs.conda.io/projects/conda-build/en/latest/resources/package-spec.html#version-ordering
+// The versions are split to the segments by dots, underscores and dashes and the segments
+// to the numbers and the strings, the strings are lower than the numbers,
+// dev is lower and post is higher than any other component and the missing components are zeros
+// e.g. 1.1dev1 < 1.1a1 < 1.1rc1 < 1.1 < 1.1.post1
+func compareVersions(a, b string) int {
+	epochA, segmentsA := versionSegments(a)
+	epochB, segmentsB := versionSegments(b)
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+
+	for i := 0; i < len(segmentsA) || i < len(segmentsB); i++ {
+		segmentA, segmentB := []string{"0"}, []string{"0"}
+		if i < len(segmentsA) {
+			segmentA = segmentsA[i]
+		}
+		if i < len(segmentsB) {
+			segmentB = segmentsB[i]
+		}
+		for j := 0; j < len(segmentA) || j < len(segmentB); j++ {
+			componentA, componentB := "0", "0"
+			if j < len(segmentA) {
+				componentA = segmentA[j]
+			}
+			if j < len(segmentB) {
+				componentB = segmentB[j]
+			}
+			if c := compareComponents(componentA, componentB); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// versionSegments returns the epoch and the components of the version segments,
+// the segment starting with a string starts with the zero (e.g. 1.a1 is 1.0a1)
+// the local version after + is ignored
+func versionSegments(version string) (int, [][]string) {
+	version = strings.ToLower(strings.TrimSpace(version))
+	version, _, _ = strings.Cut(version, "+")
+	epoch := 0
+	if before, after, ok := strings.Cut(version, "!"); ok {
+		epoch, _ = strconv.Atoi(before)
+		version = after
+	}
+
+	var segments [][]string
+	for _, segment := range strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' || r == '-' }) {
+		components := versionComponent.FindAllString(segment, -1)
+		if len(components) == 0 {
+			continue
+		}
+		if !isNumber(components[0]) {
+			components = append([]string{"0"}, components...)
+		}
+		segments = append(segments, components)
+	}
+	return epoch, segments
+}
+
+func compareComponents(a, b string) int {
+	rank := func(component string) int {
+		switch {
+		case component == "dev":
+			return 0
+		case component == "post":
+			return 3
+		case isNumber(component):
+			return 2
+		}
+		return 1
+	}
+	if rankA, rankB := rank(a), rank(b); rankA != rankB {
+		if rankA < rankB {
+			return -1
+		}
+		return 1
+	}
+	if isNumber(a) {
+		numberA, _ := strconv.ParseUint(a, 10, 64)
+		numberB, _ := strconv.ParseUint(b, 10, 64)
+		switch {
+		case numberA < numberB:
+			return -1
+		case numberA > numberB:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+func isNumber(component string) bool {
+	return component != "" && component[0] >= '0' && component[0] <= '9'
+}
+
+// matchesSpecifiers reports whether the version satisfies all version specifiers
+// The == and != specifiers ending with * match the version prefix
+func matchesSpecifiers(version string, specifiers []interfaces.VersionSpecifier) bool {
+	for _, specifier := range specifiers {
+		if !matchesSpecifier(version, specifier) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesSpecifier(version string, specifier interfaces.VersionSpecifier) bool {
+	if prefix, ok := strings.CutSuffix(specifier.Value, "*"); ok && (specifier.Operator == "==" || specifier.Operator == "!=") {
+		prefix = strings.TrimSuffix(prefix, ".")
+		matches := prefix == "" || version == prefix || strings.HasPrefix(version, prefix+".") ||
+			compareVersions(version, prefix) == 0
+		return matches == (specifier.Operator == "==")
+	}
+
+	c := compareVersions(version, specifier.Value)
+	switch specifier.Operator {
+	case "==", "===":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	case "~=":
+		// ~=1.4.2 is >=1.4.2 and ==1.4.*
+		release := strings.Split(specifier.Value, ".")
+		if len(release) < 2 {
+			return c >= 0
+		}
+		prefix := strings.Join(release[:len(release)-1], ".")
+		return c >= 0 && matchesSpecifier(version, interfaces.VersionSpecifier{Operator: "==", Value: prefix + ".*"})
+	}
+	return false
+}
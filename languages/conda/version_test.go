@@ -0,0 +1,79 @@
+package conda
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestCompareVersions(t *testing.T) {
+	// every version is lower than the next one
+	ordered := []string{"0.4", "0.4.1.rc", "0.4.1", "0.5a1", "0.5b3", "0.5C1", "0.5", "0.9.6", "0.960923", "1.0", "1.1dev1", "1.1a1", "1.1.0rc1", "1.1", "1.1.post1", "1.2.3", "1!0.1"}
+	for i := 0; i+1 < len(ordered); i++ {
+		if c := compareVersions(ordered[i], ordered[i+1]); c != -1 {
+			t.Errorf("Expected %s < %s, got %d", ordered[i], ordered[i+1], c)
+		}
+		if c := compareVersions(ordered[i+1], ordered[i]); c != 1 {
+			t.Errorf("Expected %s > %s, got %d", ordered[i+1], ordered[i], c)
+		}
+	}
+	for _, equal := range [][2]string{{"1.0", "1.0.0"}, {"1.1_2", "1.1.2"}, {"2.0+local", "2.0"}} {
+		if c := compareVersions(equal[0], equal[1]); c != 0 {
+			t.Errorf("Expected %s == %s, got %d", equal[0], equal[1], c)
+		}
+	}
+}
+
+func TestParseMatchSpec(t *testing.T) {
+	specs := map[string]matchSpec{
+		"numpy":                            {name: "numpy"},
+		"numpy >=1.21,<2":                  {name: "numpy", versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "1.21"}, {Operator: "<", Value: "2"}}},
+		"numpy>= 1.21":                     {name: "numpy", versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "1.21"}}},
+		"python=3.11":                      {name: "python", versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "3.11.*"}}},
+		"numpy=1.26.0=py311h64a7726_0":     {name: "numpy", versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "1.26.0"}}},
+		"numpy==1.26.0":                    {name: "numpy", versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "1.26.0"}}},
+		"python_abi 3.11.* *_cp311":        {name: "python_abi", versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "3.11.*"}}},
+		"libzlib 1.2.13 hd590300_5":        {name: "libzlib", versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "1.2.13"}}},
+		"conda-forge::pandas":              {channel: "conda-forge", name: "pandas"},
+		"openssl 1.1.1|3.0":                {name: "openssl"},
+		"numpy[version='>=1.21,<2']":       {name: "numpy", versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "1.21"}, {Operator: "<", Value: "2"}}},
+		"defaults::scipy !=1.11.0,>=1.10 ": {channel: "defaults", name: "scipy", versions: []interfaces.VersionSpecifier{{Operator: "!=", Value: "1.11.0"}, {Operator: ">=", Value: "1.10"}}},
+	}
+	for spec, expected := range specs {
+		got, err := parseMatchSpec(spec)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", spec, err)
+			continue
+		}
+		if diff := cmp.Diff(expected, got, cmp.AllowUnexported(matchSpec{})); diff != "" {
+			t.Errorf("Match spec %q mismatch (-want +got):\n%s", spec, diff)
+		}
+	}
+
+	if _, err := parseMatchSpec(">=1.0"); err == nil {
+		t.Error("Expected error for the match spec without the name")
+	}
+}
+
+func TestMatchesSpecifiers(t *testing.T) {
+	matches := []struct {
+		version  string
+		versions []interfaces.VersionSpecifier
+		expected bool
+	}{
+		{"1.26.2", []interfaces.VersionSpecifier{{Operator: "==", Value: "1.26.*"}}, true},
+		{"1.260", []interfaces.VersionSpecifier{{Operator: "==", Value: "1.26.*"}}, false},
+		{"3.11.6", []interfaces.VersionSpecifier{{Operator: ">=", Value: "3.11"}, {Operator: "<", Value: "3.12.0a0"}}, true},
+		{"3.12.0", []interfaces.VersionSpecifier{{Operator: ">=", Value: "3.11"}, {Operator: "<", Value: "3.12.0a0"}}, false},
+		{"1.4.5", []interfaces.VersionSpecifier{{Operator: "~=", Value: "1.4.2"}}, true},
+		{"1.5.0", []interfaces.VersionSpecifier{{Operator: "~=", Value: "1.4.2"}}, false},
+		{"2.0", []interfaces.VersionSpecifier{{Operator: "!=", Value: "2.*"}}, false},
+	}
+	for _, match := range matches {
+		if got := matchesSpecifiers(match.version, match.versions); got != match.expected {
+			t.Errorf("Expected %v for %s %v, got %v", match.expected, match.version, match.versions, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Implement the depfiles interface for the go language.
+// The go.mod lists the modules the main module requires, since go 1.17 it lists
+// every module providing the packages of the build, the ones not imported by the main module
+// are marked by the // indirect comment https://go.dev/ref/mod#go-mod-file
+package golang
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// Groups of the required modules, the indirect modules are not imported by the main module
+const (
+	MainGroup     = "main"
+	IndirectGroup = "indirect"
+)
+
+// GoMod represents the go.mod file
+type GoMod struct {
+	// sums are the checksums of the go.sum next to the go.mod keyed by <module> <version>
+	sums map[string]string
+}
+
+// NewGoMod creates a new instance of the GoMod struct
+func NewGoMod() *GoMod {
+	return &GoMod{sums: map[string]string{}}
+}
+
+func (g *GoMod) GetDepFileType() string {
+	return "golang/go.mod"
+}
+
+// GetRepository returns the module proxy given by the GOPROXY environment variable,
+// the downloaded modules are verified by the go.sum when it lists them
+func (g *GoMod) GetRepository() interfaces.PackageRepository {
+	return newGoProxy(proxyURLs(os.Getenv("GOPROXY")), g.sums)
+}
+
+// GetDependencies returns the modules required by the main module
+// The replaced modules are returned as their replacement, the modules replaced
+// by the local directory have the url of the directory and no version
+// The requirements of the excluded versions are left out
+func (g *GoMod) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	file, err := modfile.Parse(fileName, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	g.sums, err = readGoSum(filepath.Join(filepath.Dir(fileName), "go.sum"))
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[string]bool{}
+	for _, exclude := range file.Exclude {
+		excluded[exclude.Mod.String()] = true
+	}
+
+	var dependencies []interfaces.Dependency
+	for _, require := range file.Require {
+		if excluded[require.Mod.String()] {
+			continue
+		}
+		dependency := interfaces.Dependency{
+			Name:     require.Mod.Path,
+			Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: require.Mod.Version}},
+			File:     fileName,
+			Groups:   []string{MainGroup},
+		}
+		if require.Syntax != nil {
+			dependency.Line = require.Syntax.Start.Line
+		}
+		if require.Indirect {
+			dependency.Groups = []string{IndirectGroup}
+		}
+		if replace := findReplace(file.Replace, require.Mod.Path, require.Mod.Version); replace != nil {
+			if modfile.IsDirectoryPath(replace.New.Path) {
+				// the module is built from the local directory
+				dependency.Versions = nil
+				dependency.URL = replace.New.Path
+				if !filepath.IsAbs(dependency.URL) {
+					dependency.URL = filepath.Join(filepath.Dir(fileName), replace.New.Path)
+				}
+			} else {
+				dependency.Name = replace.New.Path
+				dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: replace.New.Version}}
+			}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// findReplace returns the replacement of the module version, the replacement
+// of the specific version takes precedence over the replacement of all versions
+func findReplace(replaces []*modfile.Replace, path string, version string) *modfile.Replace {
+	var found *modfile.Replace
+	for _, replace := range replaces {
+		if replace.Old.Path != path {
+			continue
+		}
+		if replace.Old.Version == version {
+			return replace
+		}
+		if replace.Old.Version == "" {
+			found = replace
+		}
+	}
+	return found
+}
+
+// readGoSum reads the checksums of the module zips from the go.sum,
+// the checksums of the go.mod files are skipped, the missing go.sum is empty
+func readGoSum(fileName string) (map[string]string, error) {
+	sums := map[string]string{}
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return sums, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: invalid go.sum line", fileName, line)
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+" "+fields[1]] = fields[2]
+	}
+	return sums, scanner.Err()
+}
@@ -0,0 +1,53 @@
+package golang
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGoMod(t *testing.T) {
+	goMod := NewGoMod()
+	fileName := filepath.Join("testdata", "go.mod")
+	dependencies, err := goMod.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type required struct {
+		name, version, groups, url string
+		line                       int
+	}
+	var got []required
+	for dependency := range dependencies {
+		got = append(got, required{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			groups:  strings.Join(dependency.Groups, ","),
+			url:     dependency.URL,
+			line:    dependency.Line,
+		})
+	}
+
+	expected := []required{
+		{name: "example.com/shared", groups: "main", url: filepath.Join("testdata", "local"), line: 6},
+		{name: "github.com/google/uuid", version: "v1.4.0", groups: "main", line: 7},
+		{name: "github.com/pkg/errors", version: "v0.9.1", groups: "main", line: 8},
+		{name: "golang.org/x/text", version: "v0.14.0", groups: "indirect", line: 9},
+		{name: "github.com/new/fork", version: "v1.3.0", groups: "main", line: 13},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(required{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	sums := map[string]string{
+		"github.com/google/uuid v1.4.0": "h1:MtMxsa51/r9yyhkyLsVeVt0B+BGQZzpQiTQ4eHZ8bc4=",
+		"github.com/pkg/errors v0.9.1":  "h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=",
+	}
+	if diff := cmp.Diff(sums, goMod.sums); diff != "" {
+		t.Errorf("Sums mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,300 @@
+// Used to get the modules from the module proxy https://go.dev/ref/mod#goproxy-protocol
+// The module zip is downloaded from <proxy>/<module>/@v/<version>.zip and the license
+// is detected from the license files in the root of the module
+// The proxy can be the local directory with the same layout (file:///path/to/proxy),
+// e.g. the module cache $GOPATH/pkg/mod/cache/download, so the modules can be checked offline
+// The GoProxy represents an implementation of interface packagerepository
+package golang
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/spdx"
+)
+
+// DefaultProxyURL is the proxy used when the GOPROXY is not set
+const DefaultProxyURL = "https://proxy.golang.org"
+
+var licenseFilePattern = regexp.MustCompile(`(?i)^(licen[cs]e|copying|unlicense)([-._].*)?$`)
+
+// errNotFound is returned by the proxy which does not have the module,
+// the next proxy of the list is tried
+var errNotFound = errors.New("module not found")
+
+// proxyURL is the single proxy of the GOPROXY list
+type proxyURL struct {
+	url string
+	// fallback is set when the next proxy is tried on any error (the proxies separated by |),
+	// otherwise only when the module is not found (the proxies separated by ,)
+	fallback bool
+}
+
+type GoProxy struct {
+	name    string
+	proxies []proxyURL
+	// sums are the checksums of the module zips keyed by <module> <version>
+	sums map[string]string
+}
+
+// NewGoProxy creates a new instance of the GoProxy struct, the proxy
+// is the list of the proxies in the GOPROXY format e.g. https://goproxy.example.com,https://proxy.golang.org
+func NewGoProxy(proxy string) *GoProxy {
+	return newGoProxy(proxyURLs(proxy), map[string]string{})
+}
+
+func newGoProxy(proxies []proxyURL, sums map[string]string) *GoProxy {
+	return &GoProxy{name: "goproxy", proxies: proxies, sums: sums}
+}
+
+// proxyURLs parses the GOPROXY list, the direct and off entries end the list
+// as the modules are not downloaded from their version control systems
+func proxyURLs(value string) []proxyURL {
+	if strings.TrimSpace(value) == "" {
+		value = DefaultProxyURL
+	}
+	var proxies []proxyURL
+	for value != "" {
+		var entry string
+		fallback := false
+		if index := strings.IndexAny(value, ",|"); index >= 0 {
+			entry, fallback, value = value[:index], value[index] == '|', value[index+1:]
+		} else {
+			entry, value = value, ""
+		}
+		entry = strings.TrimSpace(entry)
+		if entry == "direct" || entry == "off" {
+			break
+		}
+		if entry != "" {
+			proxies = append(proxies, proxyURL{url: strings.TrimSuffix(entry, "/"), fallback: fallback})
+		}
+	}
+	return proxies
+}
+
+func (g *GoProxy) GetRepositoryName() string {
+	return g.name
+}
+
+// GetPackageInfo returns the module pinned by the dependency, the latest version
+// is used when it is not pinned, the modules with the url are read from the local directory
+func (g *GoProxy) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	if dependency.URL != "" {
+		return readModuleDirectory(dependency)
+	}
+
+	escapedPath, err := module.EscapePath(dependency.Name)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	version := dependency.PinnedVersion()
+	if version == "" {
+		data, _, err := g.download(ctx, escapedPath+"/@latest")
+		if err != nil {
+			return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+		}
+		var latest struct {
+			Version string
+		}
+		if err := json.Unmarshal(data, &latest); err != nil {
+			return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+		}
+		version = latest.Version
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	data, proxy, err := g.download(ctx, escapedPath+"/@v/"+escapedVersion+".zip")
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+	}
+	if sum, ok := g.sums[dependency.Name+" "+version]; ok {
+		if err := verifyZip(archive, sum); err != nil {
+			return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+		}
+	}
+
+	// the files of the module are in the <module>@<version> directory
+	prefix := dependency.Name + "@" + version + "/"
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		name := strings.TrimPrefix(file.Name, prefix)
+		if name == file.Name || strings.Contains(name, "/") || !isModuleMetaFile(name) {
+			continue
+		}
+		content, err := readZipFile(file)
+		if err != nil {
+			return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+		}
+		files[name] = content
+	}
+
+	meta := moduleMeta(dependency.Name, version, files)
+	meta.Index = proxy
+	return meta, nil
+}
+
+// download returns the file of the first proxy having it and the url of the proxy
+func (g *GoProxy) download(ctx context.Context, name string) ([]byte, string, error) {
+	if len(g.proxies) == 0 {
+		return nil, "", fmt.Errorf("no module proxy is configured")
+	}
+	var err error
+	for _, proxy := range g.proxies {
+		var data []byte
+		data, err = fetchProxyFile(ctx, proxy.url, name)
+		if err == nil {
+			return data, proxy.url, nil
+		}
+		if !errors.Is(err, errNotFound) && !proxy.fallback {
+			return nil, "", err
+		}
+	}
+	return nil, "", err
+}
+
+// fetchProxyFile downloads the file from the proxy, the file:// proxy is read from the disk
+func fetchProxyFile(ctx context.Context, proxy string, name string) ([]byte, error) {
+	if directory, ok := strings.CutPrefix(proxy, "file://"); ok {
+		data, err := os.ReadFile(filepath.Join(filepath.FromSlash(directory), filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %w", name, errNotFound)
+		}
+		return data, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, proxy+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%s: %w", name, errNotFound)
+	}
+	return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+}
+
+// verifyZip compares the checksum of the module zip with the checksum of the go.sum
+func verifyZip(archive *zip.Reader, sum string) error {
+	files := map[string]*zip.File{}
+	var names []string
+	for _, file := range archive.File {
+		files[file.Name] = file
+		names = append(names, file.Name)
+	}
+	hash, err := dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return files[name].Open()
+	})
+	if err != nil {
+		return err
+	}
+	if hash != sum {
+		return fmt.Errorf("checksum mismatch, downloaded %s, go.sum %s", hash, sum)
+	}
+	return nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// readModuleDirectory reads the module of the local directory, e.g. the directory
+// the module is replaced by or the vendored module
+func readModuleDirectory(dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	entries, err := os.ReadDir(dependency.URL)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	files := map[string][]byte{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isModuleMetaFile(entry.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dependency.URL, entry.Name()))
+		if err != nil {
+			return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+		}
+		files[entry.Name()] = content
+	}
+	return moduleMeta(dependency.Name, dependency.PinnedVersion(), files), nil
+}
+
+// isModuleMetaFile reports whether the file of the module root is read, the go.mod and the license files
+func isModuleMetaFile(name string) bool {
+	return name == "go.mod" || licenseFilePattern.MatchString(name)
+}
+
+// moduleMeta converts the files of the module root to the PackageMeta struct,
+// the license is combined from all license files, see spdx.ClassifyFiles
+// The requirements are read from the go.mod of the module
+func moduleMeta(name string, version string, files map[string][]byte) *interfaces.PackageMeta {
+	meta := &interfaces.PackageMeta{
+		Name:     name,
+		Version:  version,
+		Homepage: "https://pkg.go.dev/" + name,
+		Language: "go",
+	}
+
+	var licenseFiles []string
+	for fileName := range files {
+		if fileName != "go.mod" {
+			licenseFiles = append(licenseFiles, fileName)
+		}
+	}
+	sort.Strings(licenseFiles)
+	meta.LicenseFiles = licenseFiles
+	licenses := make([]spdx.LicenseFile, 0, len(licenseFiles))
+	for _, fileName := range licenseFiles {
+		licenses = append(licenses, spdx.LicenseFile{Name: fileName, Text: string(files[fileName])})
+	}
+	spdx.DetectLicense(meta, licenses)
+
+	if data, ok := files["go.mod"]; ok {
+		if file, err := modfile.ParseLax(path.Join(name, "go.mod"), data, nil); err == nil {
+			for _, require := range file.Require {
+				meta.Requires = append(meta.Requires, interfaces.Dependency{
+					Name:     require.Mod.Path,
+					Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: require.Mod.Version}},
+				})
+			}
+		}
+	}
+	return meta
+}
@@ -0,0 +1,234 @@
+package golang
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// writeModuleZip writes the module zip to the proxy directory and returns its checksum
+func writeModuleZip(t *testing.T, proxy string, path string, version string, files map[string]string) string {
+	t.Helper()
+	escapedPath, err := module.EscapePath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	directory := filepath.Join(proxy, filepath.FromSlash(escapedPath), "@v")
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(directory, version+".zip")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(file)
+	for name, content := range files {
+		w, err := writer.Create(path + "@" + version + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	sum, err := dirhash.HashZip(fileName, dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sum
+}
+
+func readLicense(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "local", "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// TestGoProxyFile tests that the module is read from the file based proxy
+// and its license is detected from the license file in the module root
+func TestGoProxyFile(t *testing.T) {
+	proxy := t.TempDir()
+	sum := writeModuleZip(t, proxy, "github.com/Example/errors", "v0.9.1", map[string]string{
+		"LICENSE":             readLicense(t),
+		"go.mod":              "module github.com/Example/errors\n\nrequire github.com/pkg/errors v0.9.1\n",
+		"errors.go":           "package errors\n",
+		"internal/LICENSE.md": "not the module license",
+	})
+
+	goProxy := newGoProxy(proxyURLs("file://"+filepath.ToSlash(proxy)), map[string]string{"github.com/Example/errors v0.9.1": sum})
+	meta, err := goProxy.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "github.com/Example/errors",
+		Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "v0.9.1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.License != "MIT" || meta.LicenseSPDX != "MIT" || meta.LicenseSource != interfaces.LicenseSourceFile || meta.LicenseConfidence < 0.99 {
+		t.Errorf("Expected MIT license file, got %s (%s, %f)", meta.License, meta.LicenseSource, meta.LicenseConfidence)
+	}
+	if diff := cmp.Diff([]string{"LICENSE"}, meta.LicenseFiles); diff != "" {
+		t.Errorf("License files mismatch (-want +got):\n%s", diff)
+	}
+	requires := []interfaces.Dependency{{Name: "github.com/pkg/errors", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "v0.9.1"}}}}
+	if diff := cmp.Diff(requires, meta.Requires); diff != "" {
+		t.Errorf("Requires mismatch (-want +got):\n%s", diff)
+	}
+	if meta.Version != "v0.9.1" || meta.Language != "go" || meta.Index != "file://"+filepath.ToSlash(proxy) {
+		t.Errorf("Unexpected module %s %s %s", meta.Version, meta.Language, meta.Index)
+	}
+
+	// the checksum of the go.sum does not match the downloaded zip
+	goProxy.sums["github.com/Example/errors v0.9.1"] = "h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4="
+	_, err = goProxy.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "github.com/Example/errors",
+		Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "v0.9.1"}},
+	})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch package error, got %v", err)
+	}
+}
+
+// TestGoProxyLicenseFiles tests that the licenses of all license files of the module are combined
+func TestGoProxyLicenseFiles(t *testing.T) {
+	readText := func(id string) string {
+		data, err := os.ReadFile(filepath.Join("..", "..", "spdx", "texts", id+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	modules := []struct {
+		path     string
+		files    map[string]string
+		license  string
+		licenses []string
+	}{
+		{
+			path:    "example.com/lesser",
+			files:   map[string]string{"COPYING": readText("GPL-3.0-only"), "COPYING.LESSER": readText("LGPL-3.0-only")},
+			license: "LGPL-3.0-only",
+		},
+		{
+			path:     "example.com/dual",
+			files:    map[string]string{"LICENSE-APACHE": readText("Apache-2.0"), "LICENSE-MIT": readLicense(t)},
+			license:  "Apache-2.0 OR MIT",
+			licenses: []string{"Apache-2.0", "MIT"},
+		},
+		{
+			// the notice of the vendored code does not relax the copyleft license of the module
+			path:    "example.com/vendored",
+			files:   map[string]string{"LICENSE": readText("GPL-3.0-only"), "LICENSE-THIRD-PARTY": readLicense(t)},
+			license: "GPL-3.0-only AND MIT",
+		},
+	}
+	for _, module := range modules {
+		proxy := t.TempDir()
+		writeModuleZip(t, proxy, module.path, "v1.0.0", module.files)
+		meta, err := newGoProxy(proxyURLs("file://"+filepath.ToSlash(proxy)), nil).GetPackageInfo(context.Background(), interfaces.Dependency{
+			Name:     module.path,
+			Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "v1.0.0"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.License != module.license || meta.LicenseSPDX != module.license || meta.LicenseSource != interfaces.LicenseSourceFile {
+			t.Errorf("%s: expected %s license file, got %s (%s)", module.path, module.license, meta.License, meta.LicenseSource)
+		}
+		if diff := cmp.Diff(module.licenses, meta.Licenses); diff != "" {
+			t.Errorf("%s: licenses mismatch (-want +got):\n%s", module.path, diff)
+		}
+	}
+}
+
+// TestGoProxyFallback tests that the next proxy of the list is used when the module is not found
+// and that the latest version is used for the module without the version
+func TestGoProxyFallback(t *testing.T) {
+	proxy := t.TempDir()
+	writeModuleZip(t, proxy, "example.com/latest", "v1.2.0", map[string]string{"LICENSE": readLicense(t)})
+
+	var requested []string
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer empty.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path == "/example.com/latest/@latest" {
+			w.Write([]byte(`{"Version": "v1.2.0", "Time": "2023-10-01T00:00:00Z"}`))
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(proxy, filepath.FromSlash(r.URL.Path)))
+	}))
+	defer server.Close()
+
+	goProxy := NewGoProxy(empty.URL + "," + server.URL + ",direct")
+	meta, err := goProxy.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "example.com/latest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Version != "v1.2.0" || meta.License != "MIT" || meta.Index != server.URL {
+		t.Errorf("Expected MIT v1.2.0 from %s, got %s %s from %s", server.URL, meta.License, meta.Version, meta.Index)
+	}
+	expected := []string{
+		"/example.com/latest/@latest", "/example.com/latest/@latest",
+		"/example.com/latest/@v/v1.2.0.zip", "/example.com/latest/@v/v1.2.0.zip",
+	}
+	if diff := cmp.Diff(expected, requested); diff != "" {
+		t.Errorf("Requested paths mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = goProxy.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "example.com/missing"})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch package error, got %v", err)
+	}
+}
+
+// TestGoProxyLocalDirectory tests that the module replaced by the local directory is read from the disk
+func TestGoProxyLocalDirectory(t *testing.T) {
+	meta, err := NewGoProxy("off").GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name: "example.com/shared",
+		URL:  filepath.Join("testdata", "local"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Name != "example.com/shared" || meta.License != "MIT" || len(meta.Requires) != 1 {
+		t.Errorf("Expected example.com/shared MIT with single requirement, got %s %s %v", meta.Name, meta.License, meta.Requires)
+	}
+}
+
+func TestProxyURLs(t *testing.T) {
+	expected := []proxyURL{
+		{url: "https://goproxy.example.com", fallback: true},
+		{url: "file:///var/cache/goproxy"},
+		{url: "https://proxy.golang.org"},
+	}
+	got := proxyURLs("https://goproxy.example.com/|file:///var/cache/goproxy,https://proxy.golang.org,direct,https://ignored.example.com")
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(proxyURL{})); diff != "" {
+		t.Errorf("Proxies mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]proxyURL{{url: DefaultProxyURL}}, proxyURLs(""), cmp.AllowUnexported(proxyURL{})); diff != "" {
+		t.Errorf("Default proxies mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,21 @@
+module example.com/service
+
+go 1.21
+
+require (
+	example.com/shared v0.0.0-00010101000000-000000000000
+	github.com/google/uuid v1.4.0
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
+
+require github.com/old/fork v1.2.0
+
+exclude gopkg.in/yaml.v2 v2.4.0
+
+replace (
+	example.com/shared => ./local
+	github.com/old/fork v1.2.0 => github.com/new/fork v1.3.0
+	github.com/old/fork v1.1.0 => github.com/other/fork v1.1.0
+)
@@ -0,0 +1,5 @@
+module example.com/shared
+
+go 1.20
+
+require github.com/pkg/errors v0.9.1
@@ -0,0 +1,154 @@
+// Reads the modules vendored by go mod vendor https://go.dev/ref/mod#vendoring
+// The vendor/modules.txt lists the vendored modules with their packages, the packages
+// are copied to the vendor directory under their import path together with the license files
+// of the module root, so the modules are checked without downloading them
+package golang
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// VendorModules represents the vendor/modules.txt file
+type VendorModules struct{}
+
+// NewVendorModules creates a new instance of the VendorModules struct
+func NewVendorModules() *VendorModules {
+	return &VendorModules{}
+}
+
+func (v *VendorModules) GetDepFileType() string {
+	return "golang/vendor/modules.txt"
+}
+
+// GetRepository returns the repository reading the modules from the vendor directory
+func (v *VendorModules) GetRepository() interfaces.PackageRepository {
+	return NewVendorRepository()
+}
+
+// vendoredModule is the module of the modules.txt
+type vendoredModule struct {
+	dependency interfaces.Dependency
+	// packages are the vendored packages of the module, the modules without them are not built
+	packages int
+}
+
+// GetDependencies returns the vendored modules with the url of their directory in the vendor directory,
+// the file is the modules.txt or the vendor directory containing it
+// The replaced modules are returned as their replacement, the modules without
+// any vendored package are not part of the build, they are left out
+func (v *VendorModules) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	if info, err := os.Stat(fileName); err == nil && info.IsDir() {
+		fileName = filepath.Join(fileName, "modules.txt")
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	directory := filepath.Dir(fileName)
+
+	// # <module> <version> [=> <replacement> [<version>]]
+	// ## explicit; go 1.20
+	// <package>
+	var modules []vendoredModule
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "##"):
+			continue
+		case strings.HasPrefix(text, "#"):
+			fields := strings.Fields(strings.TrimPrefix(text, "#"))
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("%s:%d: missing module path", fileName, line)
+			}
+			// the packages are vendored under the import path of the replaced module
+			dependency := interfaces.Dependency{
+				Name: fields[0],
+				File: fileName,
+				Line: line,
+				URL:  filepath.Join(directory, filepath.FromSlash(fields[0])),
+			}
+			if len(fields) > 1 && fields[1] != "=>" {
+				dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: fields[1]}}
+			}
+			for i, field := range fields {
+				if field != "=>" || i+1 >= len(fields) {
+					continue
+				}
+				if len(fields) > i+2 {
+					dependency.Name = fields[i+1]
+					dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: fields[i+2]}}
+				} else {
+					// the module is built from the local directory, its version is a placeholder
+					dependency.Versions = nil
+				}
+				break
+			}
+			modules = append(modules, vendoredModule{dependency: dependency})
+		case len(modules) > 0:
+			modules[len(modules)-1].packages++
+		default:
+			return nil, fmt.Errorf("%s:%d: package outside of the module", fileName, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var dependencies []interfaces.Dependency
+	for _, module := range modules {
+		if module.packages > 0 {
+			dependencies = append(dependencies, module.dependency)
+		}
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// VendorRepository represents the repository reading the modules from the local directories,
+// it does not access the network
+type VendorRepository struct {
+	name string
+}
+
+// NewVendorRepository creates a new instance of the VendorRepository struct
+func NewVendorRepository() *VendorRepository {
+	return &VendorRepository{name: "vendor"}
+}
+
+func (v *VendorRepository) GetRepositoryName() string {
+	return v.name
+}
+
+// GetPackageInfo reads the module from the directory of the dependency url,
+// the license is detected from the license files of the module root
+func (v *VendorRepository) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	if dependency.URL == "" {
+		err := fmt.Errorf("module %s is not vendored", dependency.Name)
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	return readModuleDirectory(dependency)
+}
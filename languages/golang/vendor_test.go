@@ -0,0 +1,82 @@
+package golang
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestVendorModules(t *testing.T) {
+	vendorModules := NewVendorModules()
+	directory := filepath.Join("testdata", "vendor")
+	dependencies, err := vendorModules.GetDependencies(context.Background(), directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type vendored struct {
+		name, version, url string
+		line               int
+	}
+	var got []vendored
+	var metas []*interfaces.PackageMeta
+	repository := vendorModules.GetRepository()
+	for dependency := range dependencies {
+		got = append(got, vendored{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			url:     dependency.URL,
+			line:    dependency.Line,
+		})
+		if dependency.Name == "golang.org/x/text" {
+			continue
+		}
+		meta, err := repository.GetPackageInfo(context.Background(), dependency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		metas = append(metas, meta)
+	}
+
+	// the module without the packages is not vendored, the replaced
+	// module is vendored under the path of the original module
+	expected := []vendored{
+		{name: "example.com/shared", url: filepath.Join(directory, "example.com", "shared"), line: 1},
+		{name: "github.com/new/fork", version: "v1.3.0", url: filepath.Join(directory, "github.com", "old", "fork"), line: 6},
+		{name: "github.com/pkg/errors", version: "v0.9.1", url: filepath.Join(directory, "github.com", "pkg", "errors"), line: 9},
+		{name: "golang.org/x/text", version: "v0.14.0", url: filepath.Join(directory, "golang.org", "x", "text"), line: 12},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(vendored{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	licenses := map[string]string{}
+	for _, meta := range metas {
+		licenses[meta.Name+"@"+meta.Version] = meta.License + " " + meta.LicenseSource
+	}
+	expectedLicenses := map[string]string{
+		"example.com/shared@":          "MIT file",
+		"github.com/new/fork@v1.3.0":   "MIT file",
+		"github.com/pkg/errors@v0.9.1": "BSD-2-Clause file",
+	}
+	if diff := cmp.Diff(expectedLicenses, licenses); diff != "" {
+		t.Errorf("Licenses mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestVendorRepositoryMissing tests that the module missing in the vendor directory is the fetch error
+func TestVendorRepositoryMissing(t *testing.T) {
+	_, err := NewVendorRepository().GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name: "golang.org/x/text",
+		URL:  filepath.Join("testdata", "vendor", "golang.org", "x", "text"),
+	})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch package error, got %v", err)
+	}
+}
@@ -0,0 +1,326 @@
+// Used to get the packages from the npm registry (registry.npmjs.org)
+// Uses the registry API https://github.com/npm/registry/blob/master/docs/REGISTRY-API.md
+// using the url https://registry.npmjs.org/<package_name>/<version> for the pinned versions
+// and https://registry.npmjs.org/<package_name> listing all versions otherwise
+// The NpmRegistry represents an implementation of interface packagerepository
+package javascript
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/spdx"
+)
+
+type NpmRegistry struct {
+	name   string
+	config registryConfig
+	// directory is the directory of the depfile, the local references are relative to it
+	directory string
+}
+
+// npmPackument is the document of the package listing all its versions
+type npmPackument struct {
+	DistTags map[string]string     `json:"dist-tags"`
+	Versions map[string]npmVersion `json:"versions"`
+}
+
+// npmVersion is the package.json of the released version
+// The license is the SPDX expression, the older packages have the license object
+// or the licenses array instead https://docs.npmjs.com/cli/configuring-npm/package-json#license
+type npmVersion struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Description          string            `json:"description"`
+	Homepage             string            `json:"homepage"`
+	License              json.RawMessage   `json:"license"`
+	Licenses             json.RawMessage   `json:"licenses"`
+	Repository           json.RawMessage   `json:"repository"`
+	Author               json.RawMessage   `json:"author"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]struct {
+		Optional bool `json:"optional"`
+	} `json:"peerDependenciesMeta"`
+}
+
+// NewNpmRegistry creates a new instance of the NpmRegistry struct
+// querying the registry with the given url
+func NewNpmRegistry(registryURL string) *NpmRegistry {
+	return newNpmRegistry(registryConfig{
+		registry:    strings.TrimSuffix(registryURL, "/"),
+		scopes:      map[string]string{},
+		credentials: map[string]map[string]string{},
+	}, "")
+}
+
+func newNpmRegistry(config registryConfig, directory string) *NpmRegistry {
+	return &NpmRegistry{name: "npm", config: config, directory: directory}
+}
+
+func (n *NpmRegistry) GetRepositoryName() string {
+	return n.name
+}
+
+// GetPackageInfo returns the release pinned by the dependency, otherwise the release
+// with the latest tag when it satisfies the version range and the highest satisfying release
+// when it does not, the same way npm chooses the release to install
+// The dependencies with the url are read from the package.json of their tarball or directory
+func (n *NpmRegistry) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	if dependency.URL != "" {
+		return n.readReference(ctx, dependency)
+	}
+
+	registry := n.config.registryURL(dependency.Name)
+	if version := dependency.PinnedVersion(); version != "" {
+		var response npmVersion
+		if err := n.fetch(ctx, dependency, registry+"/"+escapePackageName(dependency.Name)+"/"+version, &response); err != nil {
+			return nil, err
+		}
+		meta := convertNpmVersionToPackageMeta(response)
+		meta.Index = registry
+		return meta, nil
+	}
+
+	var packument npmPackument
+	if err := n.fetch(ctx, dependency, registry+"/"+escapePackageName(dependency.Name), &packument); err != nil {
+		return nil, err
+	}
+	version := bestVersion(packument, dependency.Versions)
+	if version == "" {
+		err := fmt.Errorf("no release matches the version specifiers")
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	meta := convertNpmVersionToPackageMeta(packument.Versions[version])
+	meta.Index = registry
+	return meta, nil
+}
+
+// escapePackageName escapes the slash of the scoped package name, e.g. @scope%2fpkg,
+// the registries serve the scoped packages only at the escaped path
+func escapePackageName(name string) string {
+	return strings.Replace(name, "/", "%2f", 1)
+}
+
+// bestVersion returns the version with the latest tag when it satisfies the specifiers,
+// otherwise the highest satisfying version, empty when no version satisfies them
+func bestVersion(packument npmPackument, specifiers []interfaces.VersionSpecifier) string {
+	if latest, ok := packument.DistTags["latest"]; ok {
+		if version, err := parseSemver(latest); err == nil && satisfiesSpecifiers(version, specifiers) {
+			if _, ok := packument.Versions[latest]; ok {
+				return latest
+			}
+		}
+	}
+
+	var best string
+	var bestVersion semver
+	for value := range packument.Versions {
+		version, err := parseSemver(value)
+		if err != nil || !satisfiesSpecifiers(version, specifiers) {
+			continue
+		}
+		if best == "" || version.compare(bestVersion) > 0 {
+			best, bestVersion = value, version
+		}
+	}
+	return best
+}
+
+// fetch downloads and decodes the JSON response of the registry
+func (n *NpmRegistry) fetch(ctx context.Context, dependency interfaces.Dependency, url string, response interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if authorization := n.config.authorization(url); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("unexpected response status %s", resp.Status)
+		return &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+	}
+	return nil
+}
+
+// convertNpmVersionToPackageMeta converts the package.json to the PackageMeta struct
+// The optional dependencies and the peer dependencies not marked as optional
+// are installed by npm, so they are required as well
+func convertNpmVersionToPackageMeta(version npmVersion) *interfaces.PackageMeta {
+	meta := &interfaces.PackageMeta{
+		Name:        version.Name,
+		Version:     version.Version,
+		Description: version.Description,
+		Homepage:    version.Homepage,
+		Author:      personName(version.Author),
+		Repository:  repositoryURL(version.Repository),
+		Language:    "javascript",
+	}
+
+	licenses := licenseNames(version.License)
+	if len(licenses) > 0 {
+		meta.License = strings.Join(licenses, " OR ")
+		meta.LicenseSource = interfaces.LicenseSourceField
+		if len(licenses) == 1 && spdx.Normalize(licenses[0]) != "" {
+			meta.LicenseSource = interfaces.LicenseSourceExpression
+		}
+	} else if licenses = licenseNames(version.Licenses); len(licenses) > 0 {
+		meta.License = strings.Join(licenses, " OR ")
+		meta.LicenseSource = interfaces.LicenseSourceField
+	}
+	meta.Licenses = licenseAlternatives(licenses)
+
+	peers := map[string]string{}
+	for name, value := range version.PeerDependencies {
+		if !version.PeerDependenciesMeta[name].Optional {
+			peers[name] = value
+		}
+	}
+	// the requirements that cannot be resolved from the registry are skipped
+	for _, dependencies := range []map[string]string{version.Dependencies, version.OptionalDependencies, peers} {
+		for _, name := range sortedKeys(dependencies) {
+			if dependency, ok := npmRequirement(name, dependencies[name]); ok {
+				meta.Requires = append(meta.Requires, dependency)
+			}
+		}
+	}
+	return meta
+}
+
+// npmRequirement converts the dependency of the package.json to the dependency,
+// the aliases (npm:<name>@<range>) are replaced by the aliased package
+// The urls, git repositories and local paths are not in the registry, they are skipped,
+// the tags (e.g. latest) match any version
+func npmRequirement(name string, value string) (interfaces.Dependency, bool) {
+	if alias, ok := strings.CutPrefix(value, "npm:"); ok {
+		index := strings.LastIndex(alias, "@")
+		if index <= 0 {
+			return interfaces.Dependency{Name: alias}, true
+		}
+		name, value = alias[:index], alias[index+1:]
+	}
+	if strings.Contains(value, ":") || strings.Contains(value, "/") {
+		return interfaces.Dependency{}, false
+	}
+	dependency := interfaces.Dependency{Name: name}
+	if versions, err := rangeSpecifiers(value); err == nil {
+		dependency.Versions = versions
+	}
+	return dependency, true
+}
+
+// licenseNames returns the licenses of the license field, the license
+// can be the string, the object with the type or the array of them
+// licenseAlternatives returns the licenses the package can be used under, one for every
+// branch of the top level OR of the expression, empty for the single license
+// The legacy list of the licenses are the alternatives as well
+func licenseAlternatives(licenses []string) []string {
+	if len(licenses) > 1 {
+		return licenses
+	}
+	if len(licenses) == 0 {
+		return nil
+	}
+	expression, err := spdx.Parse(licenses[0])
+	if err != nil {
+		return nil
+	}
+	or, ok := expression.(*spdx.Or)
+	if !ok {
+		return nil
+	}
+	var alternatives []string
+	for _, branch := range or.Expressions {
+		alternatives = append(alternatives, branch.String())
+	}
+	return alternatives
+}
+
+func licenseNames(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var license string
+	if err := json.Unmarshal(raw, &license); err == nil {
+		if license = strings.TrimSpace(license); license != "" {
+			return []string{license}
+		}
+		return nil
+	}
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		if object.Type != "" {
+			return []string{object.Type}
+		}
+		return nil
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil
+	}
+	var licenses []string
+	for _, item := range list {
+		licenses = append(licenses, licenseNames(item)...)
+	}
+	return licenses
+}
+
+// personName returns the name of the person, the string form is Name <email> (url)
+func personName(raw json.RawMessage) string {
+	var person string
+	if err := json.Unmarshal(raw, &person); err == nil {
+		name, _, _ := strings.Cut(person, "<")
+		name, _, _ = strings.Cut(name, "(")
+		return strings.TrimSpace(name)
+	}
+	var object struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return object.Name
+	}
+	return ""
+}
+
+// repositoryURL returns the url of the repository given by the string or the object
+func repositoryURL(raw json.RawMessage) string {
+	var repository string
+	if err := json.Unmarshal(raw, &repository); err == nil {
+		return repository
+	}
+	var object struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return object.URL
+	}
+	return ""
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,234 @@
+package javascript
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// newRegistryServer serves the registry documents from the testdata/registry
+// the pinned versions are served from <name>-<version>.json
+func newRegistryServer(t *testing.T, requests *[]*http.Request) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		fileName := filepath.Join("testdata", "registry", filepath.Base(r.URL.Path)+".json")
+		if dir, name := filepath.Split(r.URL.Path); dir != "/" {
+			fileName = filepath.Join("testdata", "registry", filepath.Base(dir)+"-"+name+".json")
+		}
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestNpmGetPackageInfo tests that the pinned release is converted to the PackageMeta struct
+func TestNpmGetPackageInfo(t *testing.T) {
+	var requests []*http.Request
+	server := newRegistryServer(t, &requests)
+
+	registry := NewNpmRegistry(server.URL)
+	meta, err := registry.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "express",
+		Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "4.18.2"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests[0].URL.Path != "/express/4.18.2" {
+		t.Errorf("Expected /express/4.18.2, got %s", requests[0].URL.Path)
+	}
+
+	expected := &interfaces.PackageMeta{
+		Author:        "TJ Holowaychuk",
+		Name:          "express",
+		Version:       "4.18.2",
+		License:       "MIT",
+		Description:   "Fast, unopinionated, minimalist web framework",
+		Homepage:      "http://expressjs.com/",
+		Repository:    "git+https://github.com/expressjs/express.git",
+		Language:      "javascript",
+		LicenseSource: interfaces.LicenseSourceExpression,
+		Index:         server.URL,
+		Requires: []interfaces.Dependency{
+			{Name: "cookie-parser-fork", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "1.4.0"}, {Operator: "<", Value: "2.0.0-0"}}},
+			{Name: "debug", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "2.6.9"}}},
+			{Name: "qs", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "6.11.0"}}},
+			{Name: "send", Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "0.18.0"}}},
+			{Name: "fsevents", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "2.3.2"}, {Operator: "<", Value: "2.4.0-0"}}},
+			{Name: "typescript", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "4.0.0"}, {Operator: "<", Value: "6.0.0-0"}}},
+		},
+	}
+	if diff := cmp.Diff(expected, meta); diff != "" {
+		t.Errorf("Package meta mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestNpmGetPackageInfoVersionRange tests that the latest release is used when it satisfies the range,
+// the highest satisfying release otherwise, and that the legacy license fields are read
+func TestNpmGetPackageInfoVersionRange(t *testing.T) {
+	var requests []*http.Request
+	server := newRegistryServer(t, &requests)
+	registry := NewNpmRegistry(server.URL + "/")
+
+	tests := []struct {
+		value, version, license, source string
+	}{
+		{"^2.0.0", "2.1.3", "MIT", interfaces.LicenseSourceExpression},
+		{"~2.0.0", "2.0.0", "MIT", interfaces.LicenseSourceExpression},
+		{"<1", "0.7.3", "MIT", interfaces.LicenseSourceField},
+		{"*", "2.1.3", "MIT", interfaces.LicenseSourceExpression},
+	}
+	for _, test := range tests {
+		versions, err := rangeSpecifiers(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		meta, err := registry.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "ms", Versions: versions})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.Version != test.version || meta.License != test.license || meta.LicenseSource != test.source {
+			t.Errorf("Expected %s %s (%s) for %s, got %s %s (%s)", test.version, test.license, test.source,
+				test.value, meta.Version, meta.License, meta.LicenseSource)
+		}
+	}
+
+	_, err := registry.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "ms",
+		Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "4.0.0"}},
+	})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch package error, got %v", err)
+	}
+
+	_, err = registry.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "missing"})
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch package error, got %v", err)
+	}
+}
+
+// TestNpmPrivateRegistry tests that the scoped packages are downloaded from the registry
+// of their scope configured by the .npmrc of the project with its token
+func TestNpmPrivateRegistry(t *testing.T) {
+	var requests []*http.Request
+	server := newRegistryServer(t, &requests)
+
+	directory := t.TempDir()
+	t.Setenv("NPM_CONFIG_USERCONFIG", filepath.Join(directory, "missing"))
+	t.Setenv("NPM_CONFIG_REGISTRY", "")
+	t.Setenv("npm_config_registry", "")
+	t.Setenv("NPM_TOKEN", "secret")
+	npmrc := "registry=https://registry.example.com/\n" +
+		"@company:registry=" + server.URL + "/npm/\n" +
+		"//" + server.URL[len("http://"):] + "/npm/:_authToken=${NPM_TOKEN}\n"
+	if err := os.WriteFile(filepath.Join(directory, ".npmrc"), []byte(npmrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := readRegistryConfig(directory)
+	if config.registryURL("ms") != "https://registry.example.com" {
+		t.Errorf("Expected https://registry.example.com, got %s", config.registryURL("ms"))
+	}
+
+	registry := newNpmRegistry(config, directory)
+	_, err := registry.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "@company/ms"})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) {
+		t.Fatalf("Expected package error, got %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("Expected single request, got %d", len(requests))
+	}
+	if requests[0].URL.EscapedPath() != "/npm/@company%2fms" {
+		t.Errorf("Expected /npm/@company%%2fms, got %s", requests[0].URL.EscapedPath())
+	}
+	if authorization := requests[0].Header.Get("Authorization"); authorization != "Bearer secret" {
+		t.Errorf("Expected Bearer secret, got %q", authorization)
+	}
+
+	// the pinned release of the scoped package has the escaped name as well
+	_, err = registry.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "@company/ms",
+		Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "2.1.3"}},
+	})
+	if !errors.As(err, &pkgErr) {
+		t.Fatalf("Expected package error, got %v", err)
+	}
+	if requests[1].URL.EscapedPath() != "/npm/@company%2fms/2.1.3" {
+		t.Errorf("Expected /npm/@company%%2fms/2.1.3, got %s", requests[1].URL.EscapedPath())
+	}
+}
+
+// TestNpmLocalTarball tests that the package installed from the tarball is read from its package.json
+func TestNpmLocalTarball(t *testing.T) {
+	directory := t.TempDir()
+	file, err := os.Create(filepath.Join(directory, "local-1.0.0.tgz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	data := []byte(`{"name": "local", "version": "1.0.0", "license": "Apache-2.0"}`)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "package/package.json", Mode: 0o644, Size: int64(len(data))}); err != nil {
+		t.Fatal(err)
+	}
+	tarWriter.Write(data)
+	tarWriter.Close()
+	gzipWriter.Close()
+	file.Close()
+
+	registry := newNpmRegistry(registryConfig{registry: DefaultRegistryURL}, directory)
+	meta, err := registry.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "local", URL: "file:local-1.0.0.tgz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Name != "local" || meta.Version != "1.0.0" || meta.License != "Apache-2.0" {
+		t.Errorf("Expected local 1.0.0 Apache-2.0, got %s %s %s", meta.Name, meta.Version, meta.License)
+	}
+
+	_, err = registry.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "toolbelt", URL: "github:example/toolbelt"})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch package error, got %v", err)
+	}
+}
+
+// TestNpmLicenseAlternatives tests that the licenses the package can be used under
+// are the branches of the top level OR of the license expression
+func TestNpmLicenseAlternatives(t *testing.T) {
+	tests := []struct {
+		license  string
+		expected []string
+	}{
+		{`"MIT"`, nil},
+		{`"(MIT OR Apache-2.0)"`, []string{"MIT", "Apache-2.0"}},
+		{`"MIT OR (GPL-3.0-only AND BSD-3-Clause)"`, []string{"MIT", "GPL-3.0-only AND BSD-3-Clause"}},
+		{`"(MIT OR GPL-3.0-only) AND BSD-3-Clause"`, nil},
+		{`"MIT OR GPL-3.0-only) AND BSD-3-Clause"`, nil},
+	}
+	for _, test := range tests {
+		meta := convertNpmVersionToPackageMeta(npmVersion{License: json.RawMessage(test.license)})
+		if diff := cmp.Diff(test.expected, meta.Licenses); diff != "" {
+			t.Errorf("%s: licenses mismatch (-want +got):\n%s", test.license, diff)
+		}
+	}
+}
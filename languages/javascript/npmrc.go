@@ -0,0 +1,135 @@
+// Reads the registry configuration of npm https://docs.npmjs.com/cli/configuring-npm/npmrc
+// The configuration is read from the user .npmrc (~/.npmrc or NPM_CONFIG_USERCONFIG),
+// the project .npmrc next to the depfile overrides it and the NPM_CONFIG_REGISTRY
+// environment variable overrides the default registry of both
+package javascript
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRegistryURL is the registry used when no other is configured
+const DefaultRegistryURL = "https://registry.npmjs.org"
+
+// registryConfig are the registries and their credentials
+type registryConfig struct {
+	registry string
+	// scopes map the package scopes (e.g. @company) to their registries
+	scopes map[string]string
+	// credentials map the registry urls without the scheme (e.g. //npm.example.com/)
+	// to the credentials settings (_authToken, _auth)
+	credentials map[string]map[string]string
+}
+
+// readRegistryConfig reads the npm configuration for the project in the directory
+func readRegistryConfig(directory string) registryConfig {
+	config := registryConfig{
+		registry:    DefaultRegistryURL,
+		scopes:      map[string]string{},
+		credentials: map[string]map[string]string{},
+	}
+	userConfig := os.Getenv("NPM_CONFIG_USERCONFIG")
+	if userConfig == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			userConfig = filepath.Join(home, ".npmrc")
+		}
+	}
+	if userConfig != "" {
+		config.read(userConfig)
+	}
+	if directory != "" {
+		config.read(filepath.Join(directory, ".npmrc"))
+	}
+	for _, name := range []string{"NPM_CONFIG_REGISTRY", "npm_config_registry"} {
+		if registry := os.Getenv(name); registry != "" {
+			config.registry = registry
+			break
+		}
+	}
+	config.registry = strings.TrimSuffix(config.registry, "/")
+	return config
+}
+
+// read reads the settings of the .npmrc file, the missing file is skipped
+// The values can contain the environment variables e.g. ${NPM_TOKEN}
+func (c *registryConfig) read(fileName string) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = os.ExpandEnv(strings.Trim(strings.TrimSpace(value), `"'`))
+
+		switch {
+		case key == "registry":
+			c.registry = value
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			c.scopes[strings.TrimSuffix(key, ":registry")] = strings.TrimSuffix(value, "/")
+		case strings.HasPrefix(key, "//"):
+			// e.g. //npm.example.com/:_authToken=<token>
+			index := strings.LastIndex(key, ":")
+			if index < 0 {
+				continue
+			}
+			prefix := key[:index]
+			if c.credentials[prefix] == nil {
+				c.credentials[prefix] = map[string]string{}
+			}
+			c.credentials[prefix][key[index+1:]] = value
+		}
+	}
+}
+
+// registryURL returns the registry of the package, the scoped packages
+// can have their own registry
+func (c registryConfig) registryURL(name string) string {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		if registry, ok := c.scopes[scope]; ok {
+			return registry
+		}
+	}
+	return c.registry
+}
+
+// authorization returns the authorization header for the url, the credentials
+// of the longest matching registry prefix are used, empty when there are none
+func (c registryConfig) authorization(url string) string {
+	_, withoutScheme, ok := strings.Cut(url, "//")
+	if !ok {
+		return ""
+	}
+	withoutScheme = "//" + withoutScheme
+
+	var matched string
+	for prefix := range c.credentials {
+		if strings.HasPrefix(withoutScheme, prefix) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	if matched == "" {
+		return ""
+	}
+	settings := c.credentials[matched]
+	if token := settings["_authToken"]; token != "" {
+		return "Bearer " + token
+	}
+	if auth := settings["_auth"]; auth != "" {
+		return "Basic " + auth
+	}
+	return ""
+}
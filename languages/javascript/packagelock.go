@@ -0,0 +1,372 @@
+// Reads the dependencies locked by npm https://docs.npmjs.com/cli/configuring-npm/package-lock-json
+// The lockfile version 1 nests the packages in the dependencies tree, the versions 2 and 3
+// list the packages keyed by their location in node_modules, e.g. node_modules/a/node_modules/b
+// The packages are resolved the same way node resolves them, every package sees the packages
+// in its own node_modules and in the node_modules of all its parents
+package javascript
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/lockgraph"
+)
+
+// Groups of the locked packages, they are given by the dev and optional flags of the lock
+const (
+	MainGroup     = "main"
+	DevGroup      = "dev"
+	OptionalGroup = "optional"
+)
+
+type packageLockFile struct {
+	LockfileVersion int `json:"lockfileVersion"`
+	// Packages are keyed by the location, the root project is the empty location
+	Packages map[string]lockEntry `json:"packages"`
+	// Dependencies is the nested tree of the lockfile version 1
+	Dependencies map[string]lockV1Entry `json:"dependencies"`
+}
+
+type lockEntry struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Optional             bool              `json:"optional"`
+	DevOptional          bool              `json:"devOptional"`
+	Extraneous           bool              `json:"extraneous"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type lockV1Entry struct {
+	Version      string                 `json:"version"`
+	Resolved     string                 `json:"resolved"`
+	Dev          bool                   `json:"dev"`
+	Optional     bool                   `json:"optional"`
+	Requires     map[string]string      `json:"requires"`
+	Dependencies map[string]lockV1Entry `json:"dependencies"`
+}
+
+// PackageLock represents the package-lock.json file
+type PackageLock struct {
+	fileType string
+	config   registryConfig
+	// directory is the directory of the lock, the local packages are relative to it
+	directory string
+}
+
+// NewPackageLock creates a new instance of the PackageLock struct
+func NewPackageLock() *PackageLock {
+	return &PackageLock{fileType: "javascript/package-lock.json"}
+}
+
+// NewNpmShrinkwrap creates the PackageLock reading the npm-shrinkwrap.json,
+// it has the same format as the package-lock.json
+func NewNpmShrinkwrap() *PackageLock {
+	return &PackageLock{fileType: "javascript/npm-shrinkwrap.json"}
+}
+
+func (p *PackageLock) GetDepFileType() string {
+	return p.fileType
+}
+
+// GetRepository returns the npm registry configured by the .npmrc of the project
+func (p *PackageLock) GetRepository() interfaces.PackageRepository {
+	return newNpmRegistry(p.config, p.directory)
+}
+
+// GetDependencies returns the locked packages, the package locked in several locations
+// with the same version is returned once with the groups of all its locations and the shortest path
+// The workspace members and the links to them are not returned, their dependencies are direct
+func (p *PackageLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var lock packageLockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid %s: %w", fileName, filepath.Base(fileName), err)
+	}
+
+	p.directory = filepath.Dir(fileName)
+	p.config = readRegistryConfig(p.directory)
+
+	packages := lock.Packages
+	if lock.LockfileVersion < 2 || len(packages) == 0 {
+		packages = lockV1Packages(lock.Dependencies, readManifest(filepath.Join(p.directory, "package.json")))
+	}
+	paths := lockPaths(packages)
+
+	var dependencies []interfaces.Dependency
+	seen := map[string]int{}
+	for _, location := range sortedLocations(packages) {
+		entry := packages[location]
+		if !isInstalled(location) || entry.Link || entry.Extraneous {
+			continue
+		}
+		dependency := interfaces.Dependency{
+			Name:   entry.Name,
+			Path:   paths[location],
+			File:   fileName,
+			Groups: lockGroups(entry),
+		}
+		if dependency.Name == "" {
+			dependency.Name = locationName(location)
+		}
+		if isReference(entry.Resolved) {
+			dependency.URL = entry.Resolved
+		}
+		if entry.Version != "" {
+			dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: entry.Version}}
+		}
+
+		key := dependency.Name + "@" + entry.Version
+		if index, ok := seen[key]; ok {
+			if len(dependency.Path) < len(dependencies[index].Path) {
+				dependencies[index].Path = dependency.Path
+			}
+			for _, group := range dependency.Groups {
+				if !containsString(dependencies[index].Groups, group) {
+					dependencies[index].Groups = append(dependencies[index].Groups, group)
+				}
+			}
+			continue
+		}
+		seen[key] = len(dependencies)
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// lockGroups returns the groups of the locked package given by its flags
+// the devOptional package is required by the development and the optional dependencies
+func lockGroups(entry lockEntry) []string {
+	switch {
+	case entry.DevOptional:
+		return []string{DevGroup, OptionalGroup}
+	case entry.Dev:
+		return []string{DevGroup}
+	case entry.Optional:
+		return []string{OptionalGroup}
+	}
+	return []string{MainGroup}
+}
+
+// lockPaths returns the shortest path of the packages from the direct dependencies keyed by the location,
+// the direct dependencies are required by the root project and the workspace members
+func lockPaths(packages map[string]lockEntry) map[string][]string {
+	graph := map[string]lockgraph.Package{}
+	var roots []lockgraph.Root
+	for _, location := range sortedLocations(packages) {
+		entry := packages[location]
+		if !isInstalled(location) {
+			for _, dependencies := range []map[string]string{entry.Dependencies, entry.DevDependencies, entry.OptionalDependencies, entry.PeerDependencies} {
+				for _, name := range sortedKeys(dependencies) {
+					if resolved, ok := resolveLocation(packages, location, name); ok {
+						roots = append(roots, lockgraph.Root{ID: resolved})
+					}
+				}
+			}
+		}
+
+		pkg := lockgraph.Package{Name: entry.Name}
+		if pkg.Name == "" {
+			pkg.Name = locationName(location)
+		}
+		// the links point to the workspace members, their dependencies are direct
+		if !entry.Link {
+			for _, dependencies := range []map[string]string{entry.Dependencies, entry.OptionalDependencies, entry.PeerDependencies} {
+				for _, name := range sortedKeys(dependencies) {
+					if resolved, ok := resolveLocation(packages, location, name); ok {
+						pkg.Dependencies = append(pkg.Dependencies, lockgraph.Edge{ID: resolved})
+					}
+				}
+			}
+		}
+		graph[location] = pkg
+	}
+
+	walked := lockgraph.New(graph)
+	walked.Walk(roots)
+	paths := map[string][]string{}
+	for location := range packages {
+		if node, ok := walked.Node(location); ok {
+			paths[location] = node.Path
+		}
+	}
+	return paths
+}
+
+// resolveLocation returns the location of the package required from the location,
+// the node_modules of the location are searched first and then the node_modules of its parents
+func resolveLocation(packages map[string]lockEntry, from string, name string) (string, bool) {
+	for {
+		candidate := "node_modules/" + name
+		if from != "" {
+			candidate = from + "/" + candidate
+		}
+		if _, ok := packages[candidate]; ok {
+			return candidate, true
+		}
+		if from == "" {
+			return "", false
+		}
+		if index := strings.LastIndex(from, "/node_modules/"); index >= 0 {
+			from = from[:index]
+		} else {
+			from = ""
+		}
+	}
+}
+
+// isInstalled reports whether the location is in node_modules,
+// the other locations are the root project and the workspace members
+func isInstalled(location string) bool {
+	return strings.HasPrefix(location, "node_modules/") || strings.Contains(location, "/node_modules/")
+}
+
+// locationName returns the package name of the location, e.g. @scope/b for node_modules/a/node_modules/@scope/b
+func locationName(location string) string {
+	index := strings.LastIndex(location, "node_modules/")
+	if index < 0 {
+		return location
+	}
+	return location[index+len("node_modules/"):]
+}
+
+// isReference reports whether the package is not installed from the registry,
+// e.g. the git repository or the local tarball
+func isReference(resolved string) bool {
+	if resolved == "" {
+		return false
+	}
+	if strings.HasPrefix(resolved, "http://") || strings.HasPrefix(resolved, "https://") {
+		// the registry tarballs are at <registry>/<name>/-/<name>-<version>.tgz
+		return !strings.Contains(resolved, "/-/")
+	}
+	return true
+}
+
+func sortedLocations(packages map[string]lockEntry) []string {
+	locations := make([]string, 0, len(packages))
+	for location := range packages {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	return locations
+}
+
+// packageManifest is the package.json of the project
+type packageManifest struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// readManifest reads the package.json of the project, nil when it cannot be read
+func readManifest(fileName string) *packageManifest {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+	var manifest packageManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+	return &manifest
+}
+
+// lockV1Packages converts the dependencies tree of the lockfile version 1 to the packages keyed by the location
+// The direct dependencies are read from the package.json next to the lock, without it
+// the packages not required by any other package are direct
+func lockV1Packages(dependencies map[string]lockV1Entry, manifest *packageManifest) map[string]lockEntry {
+	packages := map[string]lockEntry{}
+	var add func(parent string, dependencies map[string]lockV1Entry)
+	add = func(parent string, dependencies map[string]lockV1Entry) {
+		for name, dependency := range dependencies {
+			location := "node_modules/" + name
+			if parent != "" {
+				location = parent + "/" + location
+			}
+			entry := lockEntry{
+				Version:      dependency.Version,
+				Resolved:     dependency.Resolved,
+				Dev:          dependency.Dev,
+				Optional:     dependency.Optional,
+				Dependencies: dependency.Requires,
+			}
+			if alias, ok := strings.CutPrefix(dependency.Version, "npm:"); ok {
+				// e.g. "npm:string-width@4.2.3" of the package installed under the alias
+				if index := strings.LastIndex(alias, "@"); index > 0 {
+					entry.Name, entry.Version = alias[:index], alias[index+1:]
+				}
+			} else if _, err := parseSemver(dependency.Version); err != nil {
+				// the version of the git and the local packages is their url
+				entry.Version = ""
+				if entry.Resolved == "" {
+					entry.Resolved = dependency.Version
+				}
+			}
+			packages[location] = entry
+			add(location, dependency.Dependencies)
+		}
+	}
+	add("", dependencies)
+
+	root := lockEntry{}
+	if manifest != nil {
+		root.Dependencies = manifest.Dependencies
+		root.DevDependencies = manifest.DevDependencies
+		root.OptionalDependencies = manifest.OptionalDependencies
+		root.PeerDependencies = manifest.PeerDependencies
+	} else {
+		required := map[string]bool{}
+		for _, entry := range packages {
+			for name := range entry.Dependencies {
+				required[name] = true
+			}
+		}
+		root.Dependencies = map[string]string{}
+		for name, dependency := range dependencies {
+			if !required[name] {
+				root.Dependencies[name] = dependency.Version
+			}
+		}
+	}
+	packages[""] = root
+	return packages
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package javascript
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type locked struct {
+	name, version, groups, path, url string
+}
+
+func readPackageLock(t *testing.T, fileName string) []locked {
+	t.Helper()
+	dependencies, err := NewPackageLock().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []locked
+	for dependency := range dependencies {
+		got = append(got, locked{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			groups:  strings.Join(dependency.Groups, ","),
+			path:    strings.Join(dependency.Path, " > "),
+			url:     dependency.URL,
+		})
+	}
+	return got
+}
+
+func TestPackageLock(t *testing.T) {
+	got := readPackageLock(t, filepath.Join("testdata", "v3", "package-lock.json"))
+	expected := []locked{
+		{name: "@types/node", version: "20.8.0", groups: "dev"},
+		{name: "ms", version: "2.1.2", groups: "dev,main"},
+		{name: "debug", version: "4.3.4", groups: "dev", path: "mocha"},
+		{name: "express", version: "4.18.2", groups: "main"},
+		{name: "debug", version: "2.6.9", groups: "main", path: "express"},
+		{name: "ms", version: "2.0.0", groups: "main", path: "express"},
+		{name: "fsevents", version: "2.3.3", groups: "optional"},
+		{name: "local", version: "1.0.0", groups: "main", url: "file:vendor/local-1.0.0.tgz"},
+		{name: "mocha", version: "10.2.0", groups: "dev"},
+		{name: "ms", version: "2.1.3", groups: "dev", path: "mocha"},
+		{name: "nan", version: "2.17.0", groups: "dev,optional", path: "mocha"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPackageLockV1(t *testing.T) {
+	got := readPackageLock(t, filepath.Join("testdata", "v1", "package-lock.json"))
+	expected := []locked{
+		{name: "ansi-styles", version: "3.2.1", groups: "dev", path: "chalk"},
+		{name: "chalk", version: "2.4.2", groups: "dev"},
+		{name: "supports-color", version: "5.5.0", groups: "dev", path: "chalk"},
+		{name: "color-convert", version: "1.9.3", groups: "dev", path: "chalk > ansi-styles"},
+		{name: "lodash", version: "4.17.21", groups: "main"},
+		{name: "string-width", version: "4.2.3", groups: "main"},
+		{name: "toolbelt", groups: "main", url: "github:example/toolbelt#4f1c2d3e5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLockV1PackagesWithoutManifest(t *testing.T) {
+	packages := lockV1Packages(map[string]lockV1Entry{
+		"a": {Version: "1.0.0", Requires: map[string]string{"b": "^1.0.0"}},
+		"b": {Version: "1.0.0"},
+	}, nil)
+	if diff := cmp.Diff(map[string]string{"a": "1.0.0"}, packages[""].Dependencies); diff != "" {
+		t.Errorf("Root dependencies mismatch (-want +got):\n%s", diff)
+	}
+	if location, ok := resolveLocation(packages, "node_modules/a", "b"); !ok || location != "node_modules/b" {
+		t.Errorf("Expected node_modules/b, got %q", location)
+	}
+}
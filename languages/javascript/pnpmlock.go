@@ -0,0 +1,369 @@
+// Reads the dependencies locked by pnpm https://pnpm.io/git#lockfiles
+// The importers are the projects of the workspace (the root project is .), they list
+// their dependencies resolved to the locked packages, the packages list the dependencies
+// of every locked package (the snapshots since the lockfile version 9)
+// The lockfile versions differ in the keys of the packages:
+// /ms/2.1.3 (version 5), /ms@2.1.3 (version 6) and ms@2.1.3 (version 9),
+// the packages with the peer dependencies have the suffix _react@18.2.0 (version 5)
+// or (react@18.2.0) (version 6 and later)
+package javascript
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/lockgraph"
+)
+
+type pnpmLockFile struct {
+	LockfileVersion string                  `yaml:"lockfileVersion"`
+	Importers       map[string]pnpmImporter `yaml:"importers"`
+	// the lock of the single project has the dependencies of the project at the top level
+	pnpmImporter `yaml:",inline"`
+	Packages     map[string]pnpmPackage  `yaml:"packages"`
+	Snapshots    map[string]pnpmSnapshot `yaml:"snapshots"`
+}
+
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmReference `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmReference `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmReference `yaml:"optionalDependencies"`
+}
+
+// pnpmReference is the locked version of the dependency, the lockfile version 5
+// has just the version, the later versions the specifier and the version
+type pnpmReference struct {
+	Version string
+}
+
+func (r *pnpmReference) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Version = node.Value
+		return nil
+	}
+	var reference struct {
+		Version string `yaml:"version"`
+	}
+	if err := node.Decode(&reference); err != nil {
+		return err
+	}
+	r.Version = reference.Version
+	return nil
+}
+
+type pnpmPackage struct {
+	// Name and Version are set for the packages not installed from the registry
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Resolution struct {
+		Tarball   string `yaml:"tarball"`
+		Type      string `yaml:"type"`
+		Repo      string `yaml:"repo"`
+		Commit    string `yaml:"commit"`
+		Directory string `yaml:"directory"`
+	} `yaml:"resolution"`
+	pnpmSnapshot `yaml:",inline"`
+}
+
+// pnpmSnapshot are the dependencies of the package, they are part of the package before the lockfile version 9
+type pnpmSnapshot struct {
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// PnpmLock represents the pnpm-lock.yaml file
+type PnpmLock struct {
+	config registryConfig
+	// directory is the directory of the lock, the local packages are relative to it
+	directory string
+	// projects are the selected importers, all importers are read when empty
+	projects []string
+}
+
+// NewPnpmLock creates a new instance of the PnpmLock struct
+func NewPnpmLock() *PnpmLock {
+	return &PnpmLock{}
+}
+
+// SelectProjects selects the importers the packages are read for,
+// they are the directories of the projects relative to the lock, e.g. packages/app
+func (p *PnpmLock) SelectProjects(projects []string) {
+	p.projects = nil
+	for _, project := range projects {
+		p.projects = append(p.projects, path.Clean(filepath.ToSlash(project)))
+	}
+}
+
+func (p *PnpmLock) GetDepFileType() string {
+	return "javascript/pnpm-lock.yaml"
+}
+
+// GetRepository returns the npm registry configured by the .npmrc of the project
+func (p *PnpmLock) GetRepository() interfaces.PackageRepository {
+	return newNpmRegistry(p.config, p.directory)
+}
+
+// GetDependencies returns the locked packages, the package locked with several sets
+// of the peer dependencies is returned once
+// The packages of the workspace with more projects list the projects requiring them,
+// with the selected projects only the packages they require are returned
+func (p *PnpmLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var lock pnpmLockFile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid pnpm-lock.yaml: %w", fileName, err)
+	}
+	p.directory = filepath.Dir(fileName)
+	p.config = readRegistryConfig(p.directory)
+
+	format := pnpmFormat(lock.LockfileVersion)
+	importers := lock.Importers
+	if len(importers) == 0 {
+		importers = map[string]pnpmImporter{".": lock.pnpmImporter}
+	}
+
+	// the graph of the snapshots, they are the packages before the lockfile version 9
+	snapshots := lock.Snapshots
+	if format < 9 {
+		snapshots = map[string]pnpmSnapshot{}
+		for key, pkg := range lock.Packages {
+			snapshots[key] = pkg.pnpmSnapshot
+		}
+	}
+	packages := map[string]lockgraph.Package{}
+	for key, snapshot := range snapshots {
+		name, _ := pnpmPackageName(format, key, lock.Packages[pnpmPackageKey(format, key)])
+		pkg := lockgraph.Package{Name: name}
+		for _, dependencies := range []map[string]string{snapshot.Dependencies, snapshot.OptionalDependencies} {
+			for _, dependency := range sortedKeys(dependencies) {
+				if id, ok := pnpmSnapshotKey(format, dependency, dependencies[dependency]); ok {
+					pkg.Dependencies = append(pkg.Dependencies, lockgraph.Edge{ID: id})
+				}
+			}
+		}
+		packages[key] = pkg
+	}
+
+	var projects []string
+	for project := range importers {
+		if len(p.projects) == 0 || containsString(p.projects, project) {
+			projects = append(projects, project)
+		}
+	}
+	sort.Strings(projects)
+	for _, project := range p.projects {
+		if !containsString(projects, project) {
+			return nil, fmt.Errorf("%s: project %s is not in the lock", fileName, project)
+		}
+	}
+
+	var roots []lockgraph.Root
+	required := map[string][]string{}
+	for _, project := range projects {
+		var projectRoots []lockgraph.Root
+		importer := importers[project]
+		add := func(dependencies map[string]pnpmReference, group string) {
+			for _, name := range sortedPnpmKeys(dependencies) {
+				if id, ok := pnpmSnapshotKey(format, name, dependencies[name].Version); ok {
+					projectRoots = append(projectRoots, lockgraph.Root{ID: id, Groups: []string{group}})
+				}
+			}
+		}
+		add(importer.Dependencies, MainGroup)
+		add(importer.OptionalDependencies, OptionalGroup)
+		add(importer.DevDependencies, DevGroup)
+		roots = append(roots, projectRoots...)
+
+		// the packages required by the project
+		projectGraph := lockgraph.New(packages)
+		projectGraph.Walk(projectRoots)
+		for id := range snapshots {
+			if _, ok := projectGraph.Node(id); ok {
+				required[id] = append(required[id], project)
+			}
+		}
+	}
+	graph := lockgraph.New(packages)
+	graph.Walk(roots)
+
+	var keys []string
+	for key := range snapshots {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var dependencies []interfaces.Dependency
+	seen := map[string]int{}
+	for _, key := range keys {
+		pkg := lock.Packages[pnpmPackageKey(format, key)]
+		name, version := pnpmPackageName(format, key, pkg)
+		dependency := interfaces.Dependency{Name: name, File: fileName, URL: pnpmPackageURL(pkg)}
+		if _, err := parseSemver(version); err == nil {
+			dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: version}}
+		}
+		node, ok := graph.Node(key)
+		if ok {
+			dependency.Path = node.Path
+			dependency.Groups = append([]string{}, node.Groups...)
+		} else if len(p.projects) > 0 {
+			// the package is required only by the projects not selected
+			continue
+		}
+		if len(projects) > 1 {
+			dependency.Projects = required[key]
+		}
+
+		id := name + "@" + version
+		if index, ok := seen[id]; ok {
+			merged := &dependencies[index]
+			if len(dependency.Path) < len(merged.Path) {
+				merged.Path = dependency.Path
+			}
+			for _, group := range dependency.Groups {
+				if !containsString(merged.Groups, group) {
+					merged.Groups = append(merged.Groups, group)
+				}
+			}
+			for _, project := range dependency.Projects {
+				if !containsString(merged.Projects, project) {
+					merged.Projects = append(merged.Projects, project)
+				}
+			}
+			sort.Strings(merged.Projects)
+			continue
+		}
+		seen[id] = len(dependencies)
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// pnpmFormat returns the major version of the lockfile e.g. 5 for 5.4 or 9 for '9.0'
+func pnpmFormat(lockfileVersion string) int {
+	major, _, _ := strings.Cut(lockfileVersion, ".")
+	format, err := strconv.Atoi(major)
+	if err != nil {
+		return 9
+	}
+	return format
+}
+
+// pnpmSnapshotKey returns the key of the package the dependency is resolved to,
+// the version is the locked version (e.g. 2.1.3 or 1.0.0(react@18.2.0)), the aliased package
+// (e.g. /string-width@4.2.3 or string-width@4.2.3) or the package outside of the registry
+// The dependencies linked to the workspace projects (link:../shared) are not packages
+func pnpmSnapshotKey(format int, name string, version string) (string, bool) {
+	if version == "" || strings.HasPrefix(version, "link:") {
+		return "", false
+	}
+	if strings.HasPrefix(version, "/") {
+		return version, true
+	}
+	separator := "("
+	if format < 6 {
+		separator = "_"
+	}
+	plain, _, _ := strings.Cut(version, separator)
+	switch {
+	case format >= 9:
+		if strings.LastIndex(plain, "@") > 0 {
+			// the aliased package or the package outside of the registry e.g. toolbelt@https://...
+			return version, true
+		}
+		return name + "@" + version, true
+	case strings.ContainsAny(plain, "/:"):
+		// the package outside of the registry e.g. github.com/example/toolbelt/<commit>
+		return version, true
+	case format >= 6:
+		return "/" + name + "@" + version, true
+	}
+	return "/" + name + "/" + version, true
+}
+
+// pnpmPackageKey returns the key of the package of the snapshot, the snapshots
+// of the lockfile version 9 have the peer dependencies suffix, the packages do not
+func pnpmPackageKey(format int, key string) string {
+	if format < 9 {
+		return key
+	}
+	if index := strings.Index(key, "("); index > 0 {
+		return key[:index]
+	}
+	return key
+}
+
+// pnpmPackageName returns the name and the version of the locked package,
+// the packages outside of the registry have them in the package fields
+func pnpmPackageName(format int, key string, pkg pnpmPackage) (string, string) {
+	if pkg.Name != "" {
+		return pkg.Name, pkg.Version
+	}
+	key = strings.TrimPrefix(key, "/")
+	if format < 6 {
+		// the scoped peer dependencies of the suffix have no slash e.g. _@types+react@18.2.0
+		index := strings.LastIndex(key, "/")
+		if index < 0 {
+			return key, pkg.Version
+		}
+		version, _, _ := strings.Cut(key[index+1:], "_")
+		return key[:index], version
+	}
+	if index := strings.Index(key, "("); index > 0 {
+		key = key[:index]
+	}
+	name, version := splitDescriptor(key)
+	if pkg.Version != "" {
+		version = pkg.Version
+	}
+	return name, version
+}
+
+// pnpmPackageURL returns the url of the package outside of the registry,
+// e.g. the git repository, the tarball or the local directory
+func pnpmPackageURL(pkg pnpmPackage) string {
+	resolution := pkg.Resolution
+	switch {
+	case resolution.Type == "git" || resolution.Repo != "":
+		return "git+" + resolution.Repo + "#" + resolution.Commit
+	case resolution.Directory != "":
+		return "file:" + resolution.Directory
+	case isReference(resolution.Tarball):
+		return resolution.Tarball
+	}
+	return ""
+}
+
+func sortedPnpmKeys(references map[string]pnpmReference) []string {
+	keys := make([]string, 0, len(references))
+	for key := range references {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,119 @@
+package javascript
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPnpmLock(t *testing.T) {
+	type pnpmLocked struct {
+		name, version, groups, path, projects, url string
+	}
+	locks := map[string][]pnpmLocked{
+		"v9.yaml": {
+			{name: "fsevents", version: "2.3.3", groups: "optional", projects: "packages/app"},
+			{name: "js-tokens", version: "4.0.0", groups: "main", path: "react-dom > loose-envify", projects: "packages/app,packages/shared"},
+			{name: "loose-envify", version: "1.4.0", groups: "main", path: "react-dom", projects: "packages/app,packages/shared"},
+			{name: "react-dom", version: "18.2.0", groups: "main", projects: "packages/app"},
+			{name: "react", version: "18.2.0", groups: "main", projects: "packages/app,packages/shared"},
+			{name: "string-width", version: "4.2.3", groups: "main", projects: "packages/app"},
+			{name: "toolbelt", version: "1.0.0", groups: "main", projects: "packages/shared", url: "https://codeload.github.com/example/toolbelt/tar.gz/4f1c2d3e5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d"},
+			{name: "typescript", version: "5.2.2", groups: "dev", projects: "."},
+		},
+		"v6.yaml": {
+			{name: "js-tokens", version: "4.0.0", groups: "main", path: "react-dom > loose-envify", projects: "packages/app"},
+			{name: "loose-envify", version: "1.4.0", groups: "main", path: "react-dom", projects: "packages/app"},
+			{name: "react-dom", version: "18.2.0", groups: "main", projects: "packages/app"},
+			{name: "react", version: "18.2.0", groups: "main", path: "react-dom", projects: "packages/app"},
+			{name: "string-width", version: "4.2.3", groups: "main", projects: "packages/app"},
+			{name: "typescript", version: "5.2.2", groups: "dev", projects: "."},
+		},
+		"v5.yaml": {
+			{name: "@types/node", version: "20.8.0", groups: "dev"},
+			{name: "react", version: "18.2.0", groups: "main", path: "use-sync"},
+			{name: "safe-buffer", version: "5.2.1", groups: "main", path: "string_decoder"},
+			{name: "string_decoder", version: "1.3.0", groups: "main"},
+			{name: "use-sync", version: "1.2.0", groups: "main"},
+		},
+	}
+	for name, expected := range locks {
+		dependencies, err := NewPnpmLock().GetDependencies(context.Background(), filepath.Join("testdata", "pnpm", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []pnpmLocked
+		for dependency := range dependencies {
+			got = append(got, pnpmLocked{
+				name:     dependency.Name,
+				version:  dependency.PinnedVersion(),
+				groups:   strings.Join(dependency.Groups, ","),
+				path:     strings.Join(dependency.Path, " > "),
+				projects: strings.Join(dependency.Projects, ","),
+				url:      dependency.URL,
+			})
+		}
+		if diff := cmp.Diff(expected, got, cmp.AllowUnexported(pnpmLocked{})); diff != "" {
+			t.Errorf("Dependencies of %s mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestPnpmLockProjects(t *testing.T) {
+	type pnpmLocked struct {
+		name, groups, path, projects string
+	}
+	selections := []struct {
+		projects []string
+		expected []pnpmLocked
+	}{
+		{
+			projects: []string{"packages/shared"},
+			expected: []pnpmLocked{
+				{name: "js-tokens", groups: "main", path: "react > loose-envify"},
+				{name: "loose-envify", groups: "main", path: "react"},
+				{name: "react", groups: "main"},
+				{name: "toolbelt", groups: "main"},
+			},
+		},
+		{
+			projects: []string{"./packages/shared", "."},
+			expected: []pnpmLocked{
+				{name: "js-tokens", groups: "main", path: "react > loose-envify", projects: "packages/shared"},
+				{name: "loose-envify", groups: "main", path: "react", projects: "packages/shared"},
+				{name: "react", groups: "main", projects: "packages/shared"},
+				{name: "toolbelt", groups: "main", projects: "packages/shared"},
+				{name: "typescript", groups: "dev", projects: "."},
+			},
+		},
+	}
+	for _, selection := range selections {
+		lock := NewPnpmLock()
+		lock.SelectProjects(selection.projects)
+		dependencies, err := lock.GetDependencies(context.Background(), filepath.Join("testdata", "pnpm", "v9.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []pnpmLocked
+		for dependency := range dependencies {
+			got = append(got, pnpmLocked{
+				name:     dependency.Name,
+				groups:   strings.Join(dependency.Groups, ","),
+				path:     strings.Join(dependency.Path, " > "),
+				projects: strings.Join(dependency.Projects, ","),
+			})
+		}
+		if diff := cmp.Diff(selection.expected, got, cmp.AllowUnexported(pnpmLocked{})); diff != "" {
+			t.Errorf("Dependencies of %v mismatch (-want +got):\n%s", selection.projects, diff)
+		}
+	}
+
+	lock := NewPnpmLock()
+	lock.SelectProjects([]string{"packages/missing"})
+	if _, err := lock.GetDependencies(context.Background(), filepath.Join("testdata", "pnpm", "v9.yaml")); err == nil {
+		t.Error("Expected an error for the project missing in the lock")
+	}
+}
@@ -0,0 +1,117 @@
+package javascript
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// readReference reads the package.json of the dependency installed from the url,
+// the tarball (local or remote) or the local directory, e.g. file:../shared
+// The git repositories are not supported
+func (n *NpmRegistry) readReference(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	location := dependency.URL
+	if isGitReference(location) {
+		err := fmt.Errorf("git dependency %s is not supported", location)
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	data, err := n.readPackageJSON(ctx, location)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+	var version npmVersion
+	if err := json.Unmarshal(data, &version); err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+	}
+	meta := convertNpmVersionToPackageMeta(version)
+	if meta.Name == "" {
+		meta.Name = dependency.Name
+	}
+	return meta, nil
+}
+
+// readPackageJSON returns the package.json of the directory or the tarball
+func (n *NpmRegistry) readPackageJSON(ctx context.Context, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		location = strings.TrimPrefix(strings.TrimPrefix(location, "file://"), "file:")
+		if !filepath.IsAbs(location) && n.directory != "" {
+			location = filepath.Join(n.directory, location)
+		}
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return os.ReadFile(filepath.Join(location, "package.json"))
+		}
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return readTarballPackageJSON(file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if authorization := n.config.authorization(location); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return readTarballPackageJSON(resp.Body)
+}
+
+// readTarballPackageJSON returns the package.json of the gzipped tarball,
+// the files of the npm tarballs are in the top level directory (usually package/)
+func readTarballPackageJSON(reader io.Reader) ([]byte, error) {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("package.json not found in the tarball")
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Split(strings.TrimPrefix(header.Name, "./"), "/")
+		if len(parts) == 2 && parts[1] == "package.json" {
+			return io.ReadAll(tarReader)
+		}
+	}
+}
+
+// isGitReference reports whether the url is the git repository,
+// e.g. git+ssh://git@github.com/org/repo.git#<commit> or github:org/repo
+func isGitReference(location string) bool {
+	for _, prefix := range []string{"git+", "git:", "git@", "github:", "gitlab:", "bitbucket:"} {
+		if strings.HasPrefix(location, prefix) {
+			return true
+		}
+	}
+	return strings.HasSuffix(location, ".git")
+}
@@ -0,0 +1,338 @@
+package javascript
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// semver is the semantic version https://semver.org, the build metadata is ignored
+type semver struct {
+	major, minor, patch uint64
+	prerelease          []string
+}
+
+var semverPattern = regexp.MustCompile(`^[v=\s]*(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+func parseSemver(version string) (semver, error) {
+	match := semverPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return semver{}, fmt.Errorf("invalid version %q", version)
+	}
+	var parsed semver
+	parsed.major, _ = strconv.ParseUint(match[1], 10, 64)
+	parsed.minor, _ = strconv.ParseUint(match[2], 10, 64)
+	parsed.patch, _ = strconv.ParseUint(match[3], 10, 64)
+	if match[4] != "" {
+		parsed.prerelease = strings.Split(match[4], ".")
+	}
+	return parsed, nil
+}
+
+// compare compares the versions, the prerelease is lower than the release
+// and the prerelease identifiers are compared one by one, the numeric ones numerically
+func (v semver) compare(other semver) int {
+	for _, pair := range [][2]uint64{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, b := v.prerelease[i], other.prerelease[i]
+		numberA, errA := strconv.ParseUint(a, 10, 64)
+		numberB, errB := strconv.ParseUint(b, 10, 64)
+		switch {
+		case errA == nil && errB == nil:
+			if numberA != numberB {
+				if numberA < numberB {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(v.prerelease) < len(other.prerelease):
+		return -1
+	case len(v.prerelease) > len(other.prerelease):
+		return 1
+	}
+	return 0
+}
+
+func (v semver) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+	if len(v.prerelease) > 0 {
+		version += "-" + strings.Join(v.prerelease, ".")
+	}
+	return version
+}
+
+// comparator is a single version constraint of the range e.g. >=1.2.3
+type comparator struct {
+	operator string
+	version  semver
+}
+
+func (c comparator) matches(version semver) bool {
+	cmp := version.compare(c.version)
+	switch c.operator {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	}
+	return cmp == 0
+}
+
+// semverRange is the npm version range https://github.com/npm/node-semver#ranges
+// the version satisfies the range when it satisfies all comparators of any set
+type semverRange [][]comparator
+
+// partialVersion is the version with the missing or wildcard (x, X, *) components e.g. 1.2.x
+type partialVersion struct {
+	// components is the number of the given components before the first wildcard
+	components int
+	version    semver
+}
+
+var partialPattern = regexp.MustCompile(`^[v=]*(?:(\d+|[xX*])(?:\.(\d+|[xX*])(?:\.(\d+|[xX*])(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?)?)?)?$`)
+
+func parsePartial(version string) (partialVersion, error) {
+	match := partialPattern.FindStringSubmatch(version)
+	if match == nil {
+		return partialVersion{}, fmt.Errorf("invalid version %q", version)
+	}
+	var partial partialVersion
+	numbers := []*uint64{&partial.version.major, &partial.version.minor, &partial.version.patch}
+	for i, component := range match[1:4] {
+		number, err := strconv.ParseUint(component, 10, 64)
+		if err != nil {
+			break
+		}
+		*numbers[i] = number
+		partial.components++
+	}
+	if partial.components == 3 && match[4] != "" {
+		partial.version.prerelease = strings.Split(match[4], ".")
+	}
+	return partial, nil
+}
+
+// next returns the lowest version above all versions matching the partial version
+// e.g. 1.3.0-0 for 1.2.x, the prerelease 0 excludes the prereleases of the upper bound
+func (p partialVersion) next(components int) semver {
+	upper := semver{major: p.version.major, minor: p.version.minor, patch: p.version.patch}
+	switch components {
+	case 1:
+		upper = semver{major: upper.major + 1}
+	case 2:
+		upper = semver{major: upper.major, minor: upper.minor + 1}
+	default:
+		upper.patch++
+	}
+	upper.prerelease = []string{"0"}
+	return upper
+}
+
+var (
+	hyphenRange      = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+	operatorSpacing  = regexp.MustCompile(`(<=|>=|<|>|=|~>|~|\^)\s+`)
+	comparatorPrefix = regexp.MustCompile(`^(<=|>=|<|>|=|~>|~|\^)?(.*)$`)
+)
+
+// parseRange parses the npm version range, the empty range and * match any release
+func parseRange(value string) (semverRange, error) {
+	var parsed semverRange
+	for _, set := range strings.Split(value, "||") {
+		var comparators []comparator
+		if match := hyphenRange.FindStringSubmatch(set); match != nil {
+			lower, err := parsePartial(match[1])
+			if err != nil {
+				return nil, err
+			}
+			upper, err := parsePartial(match[2])
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, comparator{">=", lower.version})
+			switch upper.components {
+			case 0:
+			case 3:
+				comparators = append(comparators, comparator{"<=", upper.version})
+			default:
+				comparators = append(comparators, comparator{"<", upper.next(upper.components)})
+			}
+			parsed = append(parsed, comparators)
+			continue
+		}
+
+		for _, field := range strings.Fields(operatorSpacing.ReplaceAllString(set, "$1")) {
+			expanded, err := expandComparator(field)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, expanded...)
+		}
+		parsed = append(parsed, comparators)
+	}
+	return parsed, nil
+}
+
+// expandComparator converts the tilde, caret and partial comparators to the primitive ones
+func expandComparator(field string) ([]comparator, error) {
+	match := comparatorPrefix.FindStringSubmatch(field)
+	operator := match[1]
+	partial, err := parsePartial(match[2])
+	if err != nil {
+		return nil, err
+	}
+	lower := partial.version
+	n := partial.components
+
+	if n == 0 {
+		switch operator {
+		case "<", ">":
+			// nothing is lower or higher than any version
+			return []comparator{{"<", semver{prerelease: []string{"0"}}}}, nil
+		}
+		return nil, nil
+	}
+
+	switch operator {
+	case "~", "~>":
+		if n == 1 {
+			return []comparator{{">=", lower}, {"<", partial.next(1)}}, nil
+		}
+		return []comparator{{">=", lower}, {"<", partial.next(2)}}, nil
+	case "^":
+		switch {
+		case lower.major > 0 || n == 1:
+			return []comparator{{">=", lower}, {"<", partial.next(1)}}, nil
+		case lower.minor > 0 || n == 2:
+			return []comparator{{">=", lower}, {"<", partial.next(2)}}, nil
+		}
+		return []comparator{{">=", lower}, {"<", partial.next(3)}}, nil
+	case ">":
+		if n < 3 {
+			return []comparator{{">=", partial.next(n)}}, nil
+		}
+		return []comparator{{">", lower}}, nil
+	case "<=":
+		if n < 3 {
+			return []comparator{{"<", partial.next(n)}}, nil
+		}
+		return []comparator{{"<=", lower}}, nil
+	case "<", ">=":
+		if n < 3 && operator == "<" {
+			lower.prerelease = []string{"0"}
+		}
+		return []comparator{{operator, lower}}, nil
+	}
+	if n < 3 {
+		return []comparator{{">=", lower}, {"<", partial.next(n)}}, nil
+	}
+	return []comparator{{"=", lower}}, nil
+}
+
+// satisfiedBy reports whether the version satisfies the range
+// The prerelease satisfies only the set with the prerelease of the same release
+// e.g. 1.2.3-beta.2 satisfies >=1.2.3-beta.1 but not >=1.2.0
+func (r semverRange) satisfiedBy(version semver) bool {
+	for _, set := range r {
+		if setSatisfiedBy(set, version) {
+			return true
+		}
+	}
+	return false
+}
+
+func setSatisfiedBy(set []comparator, version semver) bool {
+	for _, c := range set {
+		if !c.matches(version) {
+			return false
+		}
+	}
+	if len(version.prerelease) == 0 {
+		return true
+	}
+	for _, c := range set {
+		if len(c.version.prerelease) > 0 && c.version.major == version.major &&
+			c.version.minor == version.minor && c.version.patch == version.patch {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeSpecifiers converts the npm range to the version specifiers
+// The range with a single comparator set is converted to the comparators,
+// the range with alternatives is kept as a single specifier without the operator
+func rangeSpecifiers(value string) ([]interfaces.VersionSpecifier, error) {
+	parsed, err := parseRange(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) > 1 {
+		return []interfaces.VersionSpecifier{{Value: strings.TrimSpace(value)}}, nil
+	}
+	var specifiers []interfaces.VersionSpecifier
+	for _, c := range parsed[0] {
+		operator := c.operator
+		if operator == "=" {
+			operator = "=="
+		}
+		specifiers = append(specifiers, interfaces.VersionSpecifier{Operator: operator, Value: c.version.String()})
+	}
+	return specifiers, nil
+}
+
+// satisfiesSpecifiers reports whether the version satisfies all version specifiers
+// every specifier is read as the npm range
+func satisfiesSpecifiers(version semver, specifiers []interfaces.VersionSpecifier) bool {
+	var set []comparator
+	for _, specifier := range specifiers {
+		operator := specifier.Operator
+		if operator == "==" || operator == "===" {
+			operator = "="
+		}
+		parsed, err := parseRange(operator + specifier.Value)
+		if err != nil {
+			return false
+		}
+		if len(parsed) > 1 {
+			if !parsed.satisfiedBy(version) {
+				return false
+			}
+			continue
+		}
+		set = append(set, parsed[0]...)
+	}
+	return setSatisfiedBy(set, version)
+}
@@ -0,0 +1,97 @@
+package javascript
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestParseRange(t *testing.T) {
+	ranges := map[string]string{
+		"^1.2.3":          ">=1.2.3 <2.0.0-0",
+		"^0.2.3":          ">=0.2.3 <0.3.0-0",
+		"^0.0.3":          ">=0.0.3 <0.0.4-0",
+		"^1.2.x":          ">=1.2.0 <2.0.0-0",
+		"^0.0.x":          ">=0.0.0 <0.1.0-0",
+		"^0.x":            ">=0.0.0 <1.0.0-0",
+		"~1.2.3":          ">=1.2.3 <1.3.0-0",
+		"~1.2":            ">=1.2.0 <1.3.0-0",
+		"~1":              ">=1.0.0 <2.0.0-0",
+		"1.x":             ">=1.0.0 <2.0.0-0",
+		"1.2.*":           ">=1.2.0 <1.3.0-0",
+		"*":               "",
+		"":                "",
+		"1.2.3":           "=1.2.3",
+		"=v1.2.3":         "=1.2.3",
+		">1.2":            ">=1.3.0-0",
+		"<=1.2":           "<1.3.0-0",
+		"<1.2":            "<1.2.0-0",
+		">= 1.2.3 < 2":    ">=1.2.3 <2.0.0-0",
+		"1.2.3 - 2.3.4":   ">=1.2.3 <=2.3.4",
+		"1.2 - 2.3":       ">=1.2.0 <2.4.0-0",
+		"^1.0.0-beta.2":   ">=1.0.0-beta.2 <2.0.0-0",
+		"1.x || >=2.5.0":  ">=1.0.0 <2.0.0-0 || >=2.5.0",
+		"~1.2.1 >=1.2.3":  ">=1.2.1 <1.3.0-0 >=1.2.3",
+		"<1.0.0 || 2.0.x": "<1.0.0 || >=2.0.0 <2.1.0-0",
+	}
+	for value, expected := range ranges {
+		parsed, err := parseRange(value)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", value, err)
+			continue
+		}
+		var sets []string
+		for _, set := range parsed {
+			var comparators []string
+			for _, c := range set {
+				comparators = append(comparators, c.operator+c.version.String())
+			}
+			sets = append(sets, strings.Join(comparators, " "))
+		}
+		if got := strings.Join(sets, " || "); got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, value, got)
+		}
+	}
+
+	if _, err := parseRange("^1.2.3.4"); err == nil {
+		t.Error("Expected error for the invalid range")
+	}
+}
+
+func TestSatisfiesSpecifiers(t *testing.T) {
+	matches := []struct {
+		version  string
+		value    string
+		expected bool
+	}{
+		{"1.9.9", "^1.2.3", true},
+		{"2.0.0", "^1.2.3", false},
+		{"2.0.0-rc.1", "^1.2.3", false},
+		{"1.2.3-beta.3", "^1.2.3-beta.2", true},
+		{"1.2.4-beta.3", "^1.2.3-beta.2", false},
+		{"1.2.3-beta.10", ">=1.2.3-beta.2 <1.2.3-beta.9", false},
+		{"1.2.3-beta.3", "1.2.3-beta.3", true},
+		{"2.6.0", "1.x || >=2.5.0", true},
+		{"2.4.0", "1.x || >=2.5.0", false},
+		{"0.0.1", "*", true},
+	}
+	for _, match := range matches {
+		version, err := parseSemver(match.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specifiers, err := rangeSpecifiers(match.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := satisfiesSpecifiers(version, specifiers); got != match.expected {
+			t.Errorf("Expected %v for %s %s (%v), got %v", match.expected, match.version, match.value, specifiers, got)
+		}
+	}
+
+	version, _ := parseSemver("1.2.3")
+	if !satisfiesSpecifiers(version, []interfaces.VersionSpecifier{{Operator: "==", Value: "1.2.3"}}) {
+		t.Error("Expected the locked version to satisfy the pinned version")
+	}
+}
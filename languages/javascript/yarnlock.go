@@ -0,0 +1,450 @@
+// Reads the dependencies locked by yarn, both the classic yarn.lock (yarn 1) with its own
+// indented format https://classic.yarnpkg.com/en/docs/yarn-lock and the YAML lockfile of yarn 2+ (berry)
+// Every entry of the lock lists the ranges (descriptors) resolved to the single package,
+// the lock does not tell which packages are the development ones, so the groups are given
+// by the package.json of the project (classic) or of the workspaces (berry)
+package javascript
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/lockgraph"
+)
+
+// yarnEntry is the locked package of the yarn.lock in the format independent form
+type yarnEntry struct {
+	// descriptors are the ranges resolved to the package e.g. ms@^2.1.1 or ms@npm:^2.1.1
+	descriptors []string
+	name        string
+	version     string
+	// url is set for the packages not installed from the registry (e.g. git, local tarball)
+	url string
+	// workspace is the path of the workspace member relative to the lock, empty for the packages
+	workspace    string
+	dependencies map[string]string
+}
+
+// id returns the identifier of the locked package, the workspace members are identified by their path
+func (e yarnEntry) id() string {
+	if e.workspace != "" {
+		return "workspace:" + e.workspace
+	}
+	if e.url != "" {
+		return e.name + "@" + e.url
+	}
+	return e.name + "@" + e.version
+}
+
+// berryEntry is the entry of the yarn 2+ lockfile
+type berryEntry struct {
+	Version      string            `yaml:"version"`
+	Resolution   string            `yaml:"resolution"`
+	Dependencies map[string]string `yaml:"dependencies"`
+}
+
+// YarnLock represents the yarn.lock file of yarn 1 (classic) or yarn 2+ (berry),
+// the format is detected from the content of the lock
+type YarnLock struct {
+	config registryConfig
+	// directory is the directory of the lock, the local packages are relative to it
+	directory string
+}
+
+// NewYarnLock creates a new instance of the YarnLock struct
+func NewYarnLock() *YarnLock {
+	return &YarnLock{}
+}
+
+func (y *YarnLock) GetDepFileType() string {
+	return "javascript/yarn.lock"
+}
+
+// GetRepository returns the npm registry configured by the .npmrc of the project
+func (y *YarnLock) GetRepository() interfaces.PackageRepository {
+	return newNpmRegistry(y.config, y.directory)
+}
+
+// GetDependencies returns the locked packages resolved to the exact versions
+// The workspace members are not returned, the patched packages (patch: protocol)
+// are returned as the package they patch
+func (y *YarnLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	y.directory = filepath.Dir(fileName)
+	y.config = readRegistryConfig(y.directory)
+
+	var entries []yarnEntry
+	berry := isBerryLock(string(data))
+	if berry {
+		entries, err = parseBerryLock(data)
+	} else {
+		entries, err = parseClassicLock(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid yarn.lock: %w", fileName, err)
+	}
+
+	descriptors := map[string]string{}
+	for _, entry := range entries {
+		for _, descriptor := range entry.descriptors {
+			descriptors[descriptor] = entry.id()
+		}
+	}
+	resolve := func(name, value string) (string, bool) {
+		for _, descriptor := range []string{name + "@" + value, name + "@npm:" + value} {
+			if id, ok := descriptors[descriptor]; ok {
+				return id, true
+			}
+		}
+		return "", false
+	}
+
+	packages := map[string]lockgraph.Package{}
+	required := map[string]bool{}
+	var ids []string
+	for _, entry := range entries {
+		if entry.workspace != "" {
+			continue
+		}
+		pkg, ok := packages[entry.id()]
+		if !ok {
+			pkg = lockgraph.Package{Name: entry.name}
+			ids = append(ids, entry.id())
+		}
+		for _, name := range sortedKeys(entry.dependencies) {
+			if id, ok := resolve(name, entry.dependencies[name]); ok {
+				pkg.Dependencies = append(pkg.Dependencies, lockgraph.Edge{ID: id})
+				required[id] = true
+			}
+		}
+		packages[entry.id()] = pkg
+	}
+	sort.Strings(ids)
+
+	var roots []lockgraph.Root
+	if berry {
+		roots = berryRoots(entries, y.directory, resolve)
+	} else if manifest := readManifest(filepath.Join(y.directory, "package.json")); manifest != nil {
+		roots = manifestRoots(manifest, resolve)
+	} else {
+		// without the package.json the packages not required by any other package are direct
+		for _, id := range ids {
+			if !required[id] {
+				roots = append(roots, lockgraph.Root{ID: id, Groups: []string{MainGroup}})
+			}
+		}
+	}
+	graph := lockgraph.New(packages)
+	graph.Walk(roots)
+
+	byID := map[string]yarnEntry{}
+	for _, entry := range entries {
+		if _, ok := byID[entry.id()]; !ok {
+			byID[entry.id()] = entry
+		}
+	}
+	var dependencies []interfaces.Dependency
+	for _, id := range ids {
+		entry := byID[id]
+		dependency := interfaces.Dependency{Name: entry.name, File: fileName, URL: entry.url}
+		if entry.version != "" {
+			dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: entry.version}}
+		}
+		if node, ok := graph.Node(id); ok {
+			dependency.Path = node.Path
+			dependency.Groups = node.Groups
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// manifestRoots returns the direct dependencies of the package.json with their groups
+func manifestRoots(manifest *packageManifest, resolve func(name, value string) (string, bool)) []lockgraph.Root {
+	var roots []lockgraph.Root
+	add := func(dependencies map[string]string, group string) {
+		for _, name := range sortedKeys(dependencies) {
+			if id, ok := resolve(name, dependencies[name]); ok {
+				roots = append(roots, lockgraph.Root{ID: id, Groups: []string{group}})
+			}
+		}
+	}
+	add(manifest.Dependencies, MainGroup)
+	add(manifest.OptionalDependencies, OptionalGroup)
+	add(manifest.DevDependencies, DevGroup)
+	return roots
+}
+
+// berryRoots returns the dependencies of the workspace members, the lock merges
+// the development dependencies into the dependencies, so the groups are read
+// from the package.json of the workspace, they are in the main group without it
+func berryRoots(entries []yarnEntry, directory string, resolve func(name, value string) (string, bool)) []lockgraph.Root {
+	var roots []lockgraph.Root
+	for _, entry := range entries {
+		if entry.workspace == "" {
+			continue
+		}
+		manifest := readManifest(filepath.Join(directory, entry.workspace, "package.json"))
+		for _, name := range sortedKeys(entry.dependencies) {
+			id, ok := resolve(name, entry.dependencies[name])
+			if !ok || strings.HasPrefix(id, "workspace:") {
+				continue
+			}
+			group := MainGroup
+			if manifest != nil && manifest.Dependencies[name] == "" {
+				if manifest.OptionalDependencies[name] != "" {
+					group = OptionalGroup
+				} else if manifest.DevDependencies[name] != "" {
+					group = DevGroup
+				}
+			}
+			roots = append(roots, lockgraph.Root{ID: id, Groups: []string{group}})
+		}
+	}
+	return roots
+}
+
+// isBerryLock reports whether the lock is the YAML lockfile of yarn 2+, it starts with the metadata
+func isBerryLock(data string) bool {
+	return strings.HasPrefix(data, "__metadata:") || strings.Contains(data, "\n__metadata:")
+}
+
+// parseClassicLock parses the yarn.lock of yarn 1, the entries are
+//
+//	"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.10.4":
+//	  version "7.12.13"
+//	  resolved "https://registry.yarnpkg.com/@babel/code-frame/-/code-frame-7.12.13.tgz#<sha1>"
+//	  dependencies:
+//	    "@babel/highlight" "^7.12.13"
+func parseClassicLock(data string) ([]yarnEntry, error) {
+	var entries []yarnEntry
+	var current *yarnEntry
+	var block string
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		switch indent := len(line) - len(trimmed); {
+		case indent == 0:
+			if !strings.HasSuffix(trimmed, ":") {
+				return nil, fmt.Errorf("line %d: expected the package descriptors", number)
+			}
+			var descriptors []string
+			for _, descriptor := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				descriptors = append(descriptors, unquoteYarn(strings.TrimSpace(descriptor)))
+			}
+			entries = append(entries, yarnEntry{descriptors: descriptors, dependencies: map[string]string{}})
+			current = &entries[len(entries)-1]
+			current.name = descriptorName(descriptors[0])
+			block = ""
+		case current == nil:
+			return nil, fmt.Errorf("line %d: field outside of the package", number)
+		case indent <= 2:
+			key, value := splitYarnField(trimmed)
+			block = ""
+			switch {
+			case value == "" && strings.HasSuffix(key, ":"):
+				block = strings.TrimSuffix(key, ":")
+			case key == "version":
+				current.version = value
+			case key == "resolved":
+				if isReference(value) {
+					current.url = value
+				}
+			}
+		case block == "dependencies" || block == "optionalDependencies":
+			key, value := splitYarnField(trimmed)
+			current.dependencies[strings.TrimSuffix(key, ":")] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		// the local and the git packages may not have the resolved url
+		if _, value := splitDescriptor(entries[i].descriptors[0]); entries[i].url == "" && isReferenceRange(value) {
+			if path, ok := strings.CutPrefix(value, "link:"); ok {
+				value = "file:" + path
+			}
+			entries[i].url = value
+		}
+		if entries[i].url != "" {
+			if _, err := parseSemver(entries[i].version); err != nil {
+				entries[i].version = ""
+			}
+		}
+	}
+	return entries, nil
+}
+
+// splitYarnField splits the line of the classic lock to the key and the value,
+// both of them can be quoted
+func splitYarnField(line string) (string, string) {
+	var key, rest string
+	if strings.HasPrefix(line, `"`) {
+		end := strings.Index(line[1:], `"`)
+		if end < 0 {
+			return line, ""
+		}
+		key, rest = line[1:end+1], line[end+2:]
+	} else {
+		key, rest, _ = strings.Cut(line, " ")
+	}
+	return key, unquoteYarn(strings.TrimSpace(rest))
+}
+
+func unquoteYarn(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
+
+// parseBerryLock parses the YAML lockfile of yarn 2+, the entries are
+//
+//	"ms@npm:^2.1.1, ms@npm:^2.1.3":
+//	  version: 2.1.3
+//	  resolution: "ms@npm:2.1.3"
+//
+// The resolution tells the protocol the package is installed with
+func parseBerryLock(data []byte) ([]yarnEntry, error) {
+	var lock map[string]berryEntry
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var entries []yarnEntry
+	for key, entry := range lock {
+		if key == "__metadata" {
+			continue
+		}
+		parsed := yarnEntry{version: entry.Version, dependencies: entry.Dependencies}
+		for _, descriptor := range strings.Split(key, ",") {
+			// the local packages are bound to the workspace requiring them e.g. file:./vendor::locator=<workspace>
+			descriptor, _, _ = strings.Cut(strings.TrimSpace(descriptor), "::")
+			parsed.descriptors = append(parsed.descriptors, descriptor)
+		}
+		if !resolveBerryEntry(&parsed, entry.Resolution) {
+			continue
+		}
+		entries = append(entries, parsed)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].descriptors[0] < entries[j].descriptors[0]
+	})
+	return entries, nil
+}
+
+// resolveBerryEntry sets the name and the source of the entry from its resolution
+// e.g. ms@npm:2.1.3, app@workspace:packages/app or resolve@patch:resolve@npm%3A1.22.1#<patch>
+// The patched package is the package it patches, it is false when the resolution is invalid
+func resolveBerryEntry(entry *yarnEntry, resolution string) bool {
+	name, reference := splitDescriptor(resolution)
+	if name == "" || reference == "" {
+		return false
+	}
+	entry.name = name
+
+	protocol, value, _ := strings.Cut(reference, ":")
+	switch protocol {
+	case "npm":
+		entry.url = ""
+		return true
+	case "workspace":
+		entry.workspace = value
+		return true
+	case "patch":
+		// the patched package is escaped e.g. resolve@npm%3A1.22.1
+		patched, _, _ := strings.Cut(value, "#")
+		if unescaped, err := url.PathUnescape(patched); err == nil {
+			patched = unescaped
+		}
+		return resolveBerryEntry(entry, patched)
+	case "file", "link", "portal":
+		// the local packages are relative to the workspace in the locator
+		// e.g. file:./vendor/local.tgz#./vendor/local.tgz::hash=<hash>&locator=app%40workspace%3Apackages%2Fapp
+		location, parameters, _ := strings.Cut(value, "::")
+		location, _, _ = strings.Cut(location, "#")
+		if query, err := url.ParseQuery(parameters); err == nil {
+			if _, workspace := splitDescriptor(query.Get("locator")); strings.HasPrefix(workspace, "workspace:") {
+				location = path.Join(strings.TrimPrefix(workspace, "workspace:"), location)
+			}
+		}
+		entry.url = "file:" + location
+	default:
+		entry.url = reference
+	}
+	if _, err := parseSemver(entry.version); err != nil {
+		entry.version = ""
+	}
+	return true
+}
+
+// splitDescriptor splits the descriptor (e.g. @babel/core@^7.0.0) to the name and the range
+func splitDescriptor(descriptor string) (string, string) {
+	if descriptor == "" {
+		return "", ""
+	}
+	index := strings.Index(descriptor[1:], "@")
+	if index < 0 {
+		return descriptor, ""
+	}
+	return descriptor[:index+1], descriptor[index+2:]
+}
+
+// descriptorName returns the name of the package the descriptor resolves to,
+// the aliases (e.g. string-width-cjs@npm:string-width@^4.2.0) resolve to the aliased package
+func descriptorName(descriptor string) string {
+	name, value := splitDescriptor(descriptor)
+	if alias, ok := strings.CutPrefix(value, "npm:"); ok && strings.LastIndex(alias, "@") > 0 {
+		aliased, _ := splitDescriptor(alias)
+		return aliased
+	}
+	return name
+}
+
+// isReferenceRange reports whether the range points to the package outside of the registry
+func isReferenceRange(value string) bool {
+	for _, prefix := range []string{"file:", "link:", "http://", "https://"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return isGitReference(value)
+}
@@ -0,0 +1,75 @@
+package javascript
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func readYarnLock(t *testing.T, fileName string) []locked {
+	t.Helper()
+	dependencies, err := NewYarnLock().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []locked
+	for dependency := range dependencies {
+		got = append(got, locked{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			groups:  strings.Join(dependency.Groups, ","),
+			path:    strings.Join(dependency.Path, " > "),
+			url:     dependency.URL,
+		})
+	}
+	return got
+}
+
+func TestYarnLockClassic(t *testing.T) {
+	got := readYarnLock(t, filepath.Join("testdata", "yarn", "classic", "yarn.lock"))
+	expected := []locked{
+		{name: "ansi-regex", version: "5.0.1", groups: "main", path: "string-width > strip-ansi"},
+		{name: "debug", version: "4.3.4", groups: "main"},
+		{name: "local", version: "1.0.0", groups: "main", url: "file:vendor/local"},
+		{name: "ms", version: "2.1.2", groups: "dev,main"},
+		{name: "string-width", version: "4.2.3", groups: "main"},
+		{name: "strip-ansi", version: "6.0.1", groups: "main", path: "string-width"},
+		{name: "toolbelt", version: "1.0.0", groups: "dev", url: "https://codeload.github.com/example/toolbelt/tar.gz/4f1c2d3e5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestYarnLockBerry(t *testing.T) {
+	got := readYarnLock(t, filepath.Join("testdata", "yarn", "berry", "yarn.lock"))
+	expected := []locked{
+		{name: "debug", version: "4.3.4", groups: "main"},
+		{name: "is-core-module", version: "2.13.1", groups: "main", path: "resolve"},
+		{name: "local", version: "1.0.0", groups: "main", url: "file:packages/util/vendor/local-1.0.0.tgz"},
+		{name: "ms", version: "2.1.2", groups: "main", path: "debug"},
+		{name: "ms", version: "2.1.3", groups: "dev"},
+		{name: "resolve", version: "1.22.8", groups: "main"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDescriptorName(t *testing.T) {
+	descriptors := map[string]string{
+		"ms@^2.1.1":          "ms",
+		"@babel/core@^7.0.0": "@babel/core",
+		"string-width-cjs@npm:string-width@^4.2.0": "string-width",
+		"alias@npm:@scope/real@^1.0.0":             "@scope/real",
+		"ms@npm:^2.1.1":                            "ms",
+	}
+	for descriptor, expected := range descriptors {
+		if got := descriptorName(descriptor); got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, descriptor, got)
+		}
+	}
+}
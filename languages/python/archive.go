@@ -0,0 +1,330 @@
+// Reads the package meta directly from the distribution archives
+// It is used for the dependencies with the direct reference, e.g.
+// urllib3 @ https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip
+// The wheels, the source distributions (.tar.gz, .tar.bz2, .zip), the source archives
+// of the VCS repositories and the local project directories are supported, the metadata are read from METADATA, PKG-INFO
+// or pyproject.toml and the bundled license files (LICENSE, COPYING, ...) are detected
+package python
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+var (
+	licenseFilePattern = regexp.MustCompile(`(?i)^(licen[cs]e|copying|notice|unlicense|copyright)([-._].*)?$`)
+	// the archive of the github repository, e.g. git+https://github.com/org/repo@v1.0
+	githubRepository = regexp.MustCompile(`^git\+https?://github\.com/([^/@#]+)/([^/@#]+?)(?:\.git)?(?:@([^#]+))?(?:#.*)?$`)
+	eggFragment      = regexp.MustCompile(`[#&]egg=([A-Za-z0-9._-]+)`)
+)
+
+// ArchiveInspector represents the repository reading the package meta
+// from the archive the dependency url points to
+type ArchiveInspector struct {
+	name string
+}
+
+// NewArchiveInspector creates a new instance of the ArchiveInspector struct
+func NewArchiveInspector() interfaces.PackageRepository {
+	return &ArchiveInspector{
+		name: "archive",
+	}
+}
+
+func (a *ArchiveInspector) GetRepositoryName() string {
+	return a.name
+}
+
+func (a *ArchiveInspector) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	location, err := archiveURL(dependency.URL)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	files, err := a.readArchive(ctx, location)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	meta, err := archivePackageMeta(files)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+	}
+	if meta.Name == "" {
+		meta.Name = dependency.Name
+	}
+	if meta.Version == "" {
+		meta.Version, _ = distributionVersion(path.Base(location), meta.Name)
+	}
+	return meta, nil
+}
+
+// readArchive reads the metadata and license files of the local or remote archive
+// the remote zip archives are read partially using the range requests
+func (a *ArchiveInspector) readArchive(ctx context.Context, location string) ([]archiveFile, error) {
+	filename := path.Base(strings.SplitN(location, "?", 2)[0])
+
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		file, err := os.Open(strings.TrimPrefix(location, "file://"))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return readDirectoryFiles(file, isArchiveMetaFile)
+		}
+		if isZipArchive(filename) {
+			return readZipFiles(file, info.Size(), isArchiveMetaFile)
+		}
+		return readTarFiles(file, filename, isArchiveMetaFile)
+	}
+
+	location, auth := splitIndexURL(location)
+	if isZipArchive(filename) {
+		reader, size, err := openRemoteFile(ctx, location, auth)
+		if err != nil {
+			return nil, err
+		}
+		return readZipFiles(reader, size, isArchiveMetaFile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		auth.apply(req)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return readTarFiles(resp.Body, filename, isArchiveMetaFile)
+}
+
+// readDirectoryFiles reads the files of the local project directory accepted by the filter,
+// the files are named as the members of the source archive, e.g. project/pyproject.toml
+func readDirectoryFiles(directory *os.File, filter func(string) bool) ([]archiveFile, error) {
+	entries, err := directory.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Base(directory.Name())
+	var files []archiveFile
+	for _, entry := range entries {
+		name := root + "/" + entry.Name()
+		if !entry.Type().IsRegular() || !filter(name) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(directory.Name(), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxArchiveFileSize {
+			data = data[:maxArchiveFileSize]
+		}
+		files = append(files, archiveFile{name: name, data: data})
+	}
+	return files, nil
+}
+
+func isZipArchive(filename string) bool {
+	return strings.HasSuffix(filename, ".whl") || strings.HasSuffix(filename, ".zip")
+}
+
+// archivePackageMeta converts the archive files to the package meta
+// The core metadata are preferred to the pyproject.toml, which is the only
+// source of the metadata in the VCS archives
+// The detected license files are used when the metadata do not list them
+// and their texts are classified when the metadata do not contain the license
+func archivePackageMeta(files []archiveFile) (*interfaces.PackageMeta, error) {
+	var info pypiInfo
+	var metadata, pyproject []byte
+	var licenseFiles []string
+	var licenseTexts []archiveFile
+	for _, file := range files {
+		switch {
+		case isMetadataFile(file.name) && metadata == nil:
+			metadata = file.data
+		case isPyprojectFile(file.name) && pyproject == nil:
+			pyproject = file.data
+		case isLicenseFile(file.name):
+			licenseFiles = append(licenseFiles, archiveRelativePath(file.name))
+			licenseTexts = append(licenseTexts, file)
+		}
+	}
+
+	var err error
+	switch {
+	case metadata != nil:
+		info, err = parseMetadata(bytes.NewReader(metadata))
+	case pyproject != nil:
+		info, err = parsePyproject(pyproject)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(info.LicenseFiles) == 0 {
+		info.LicenseFiles = licenseFiles
+	}
+
+	meta := convertPyPIResponseToPackageMeta(pypiResponse{Info: info})
+	detectLicense(meta, licenseTexts)
+	return meta, nil
+}
+
+// isArchiveMetaFile accepts the files the package meta is read from
+func isArchiveMetaFile(name string) bool {
+	return isMetadataFile(name) || isPyprojectFile(name) || isLicenseFile(name)
+}
+
+// isPyprojectFile accepts the pyproject.toml in the top level directory of the archive
+func isPyprojectFile(name string) bool {
+	parts := strings.Split(strings.TrimPrefix(name, "./"), "/")
+	return len(parts) == 2 && parts[1] == "pyproject.toml"
+}
+
+// isLicenseFile accepts the license files in the top level directory of the source archive
+// and in the dist-info directory of the wheel, including the PEP 639 licenses directory
+func isLicenseFile(name string) bool {
+	parts := strings.Split(strings.TrimPrefix(name, "./"), "/")
+	if len(parts) >= 3 && strings.HasSuffix(parts[0], ".dist-info") && parts[1] == "licenses" {
+		return true
+	}
+	return len(parts) == 2 && licenseFilePattern.MatchString(parts[1])
+}
+
+// archiveRelativePath removes the top level directory from the archive member name
+// the dist-info directory of the wheels is kept, so the file can be found in the wheel
+func archiveRelativePath(name string) string {
+	name = strings.TrimPrefix(name, "./")
+	dir, file, _ := strings.Cut(name, "/")
+	if strings.HasSuffix(dir, ".dist-info") {
+		return name
+	}
+	return file
+}
+
+// archiveURL returns the location of the archive of the direct reference
+// The VCS urls of the github repositories are converted to the source archive urls,
+// the other VCS urls cannot be read without the VCS checkout
+func archiveURL(reference string) (string, error) {
+	if match := githubRepository.FindStringSubmatch(reference); match != nil {
+		ref := match[3]
+		if ref == "" {
+			ref = "HEAD"
+		}
+		return fmt.Sprintf("https://github.com/%s/%s/archive/%s.tar.gz", match[1], match[2], ref), nil
+	}
+	scheme, _, found := strings.Cut(reference, "://")
+	if found && strings.Contains(scheme, "+") {
+		return "", fmt.Errorf("unsupported VCS url %s", reference)
+	}
+	// the fragment contains the egg name or the hash of the archive
+	location, _, _ := strings.Cut(reference, "#")
+	return location, nil
+}
+
+// directReferenceName returns the package name of the direct reference without the name
+// e.g. https://example.com/requests-2.31.0-py3-none-any.whl is requests
+// The egg fragment and the repository of the VCS url are used when the file name
+// does not contain the package name
+func directReferenceName(reference string) string {
+	if match := eggFragment.FindStringSubmatch(reference); match != nil {
+		return match[1]
+	}
+	if match := githubRepository.FindStringSubmatch(reference); match != nil {
+		return strings.TrimSuffix(match[2], ".git")
+	}
+
+	location, _, _ := strings.Cut(reference, "#")
+	location, _, _ = strings.Cut(location, "?")
+	filename := path.Base(location)
+	if strings.HasSuffix(filename, ".whl") {
+		return strings.Split(filename, "-")[0]
+	}
+	for _, extension := range sdistExtensions {
+		stem, ok := strings.CutSuffix(filename, extension)
+		if !ok {
+			continue
+		}
+		// the name ends before the version, e.g. python-dateutil-2.8.2
+		for i := 0; i < len(stem)-1; i++ {
+			if stem[i] == '-' && stem[i+1] >= '0' && stem[i+1] <= '9' {
+				return stem[:i]
+			}
+		}
+		if stem != "" && (stem[0] < '0' || stem[0] > '9') {
+			return stem
+		}
+	}
+
+	// the source archives of the repositories are named by the version,
+	// e.g. https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip
+	if parsed, err := url.Parse(location); err == nil {
+		parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		if len(parts) >= 2 && (parsed.Host == "github.com" || parsed.Host == "gitlab.com") {
+			return parts[1]
+		}
+	}
+	return reference
+}
+
+// directReferenceRepository reads the dependencies with the direct reference
+// from their archives, the dependencies with the explicit index from that index
+// and the other dependencies from the package indexes
+type directReferenceRepository struct {
+	archives interfaces.PackageRepository
+	indexes  interfaces.PackageRepository
+	// sources are the repositories of the explicit indexes keyed by the index url
+	sources map[string]interfaces.PackageRepository
+}
+
+// newDirectReferenceRepository creates the repository querying the indexes in the given order,
+// the repositories of the explicit indexes of the dependencies are created up front,
+// so the repository is not modified by the concurrent downloads
+func newDirectReferenceRepository(indexURLs []string, sourceURLs []string) *directReferenceRepository {
+	repository := &directReferenceRepository{
+		archives: NewArchiveInspector(),
+		indexes:  newIndexRepository(indexURLs),
+		sources:  map[string]interfaces.PackageRepository{},
+	}
+	for _, sourceURL := range sourceURLs {
+		if _, ok := repository.sources[sourceURL]; !ok {
+			repository.sources[sourceURL] = newIndexRepository([]string{sourceURL})
+		}
+	}
+	return repository
+}
+
+func (d *directReferenceRepository) GetRepositoryName() string {
+	return d.indexes.GetRepositoryName()
+}
+
+func (d *directReferenceRepository) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	if dependency.URL != "" {
+		return d.archives.GetPackageInfo(ctx, dependency)
+	}
+	if source, ok := d.sources[dependency.Index]; ok {
+		return source.GetPackageInfo(ctx, dependency)
+	}
+	return d.indexes.GetPackageInfo(ctx, dependency)
+}
@@ -0,0 +1,225 @@
+package python
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+const vcsPyproject = `[project]
+name = "vcs-lib"
+version = "2.0"
+license = {file = "LICENSE"}
+authors = [{name = "Jane Doe", email = "jane@example.com"}]
+classifiers = ["License :: OSI Approved :: BSD License"]
+dependencies = ["six"]
+
+[project.optional-dependencies]
+test = ["pytest; python_version >= '3.8'"]
+
+[project.urls]
+Repository = "https://github.com/example/vcs-lib"
+`
+
+func buildSourceArchive(t *testing.T, files map[string]string) []byte {
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	archive := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		archive.Write([]byte(content))
+	}
+	archive.Close()
+	gz.Close()
+	return buffer.Bytes()
+}
+
+func TestArchiveInspectorSourceArchive(t *testing.T) {
+	data := buildSourceArchive(t, map[string]string{
+		"vcs-lib-2.0/pyproject.toml":    vcsPyproject,
+		"vcs-lib-2.0/LICENSE":           "BSD 3-Clause License",
+		"vcs-lib-2.0/docs/LICENSE.txt":  "not the package license",
+		"vcs-lib-2.0/src/vcs_lib/a.py":  "",
+		"vcs-lib-2.0/COPYING.LESSER.md": "LGPL",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	meta, err := NewArchiveInspector().GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name: "vcs-lib",
+		URL:  server.URL + "/archive/v2.0.tar.gz#sha256=00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &interfaces.PackageMeta{
+		Author:        "Jane Doe",
+		Name:          "vcs-lib",
+		Version:       "2.0",
+		License:       "BSD License",
+		LicenseSource: interfaces.LicenseSourceClassifier,
+		Repository:    "https://github.com/example/vcs-lib",
+		Language:      "python",
+		Requires: []interfaces.Dependency{
+			{Name: "six"},
+			{Name: "pytest", Markers: `(python_version >= '3.8') and extra == "test"`},
+		},
+	}
+	if diff := cmp.Diff(expected, meta, cmpIgnoreLicenseFiles); diff != "" {
+		t.Errorf("PackageMeta mismatch (-want +got):\n%s", diff)
+	}
+	licenseFiles := strings.Join(meta.LicenseFiles, ",")
+	if licenseFiles != "LICENSE,COPYING.LESSER.md" && licenseFiles != "COPYING.LESSER.md,LICENSE" {
+		t.Errorf("Expected the top level license files, got %v", meta.LicenseFiles)
+	}
+}
+
+var cmpIgnoreLicenseFiles = cmp.FilterPath(func(p cmp.Path) bool {
+	return p.String() == "LicenseFiles"
+}, cmp.Ignore())
+
+func TestArchiveInspectorLocalWheel(t *testing.T) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	files := map[string]string{
+		"local_lib/__init__.py":                        "",
+		"local_lib-0.1.dist-info/METADATA":             "Metadata-Version: 2.4\nName: local-lib\nVersion: 0.1\nLicense-Expression: MIT\n",
+		"local_lib-0.1.dist-info/licenses/LICENSE.txt": "MIT License",
+	}
+	for _, name := range []string{"local_lib/__init__.py", "local_lib-0.1.dist-info/METADATA", "local_lib-0.1.dist-info/licenses/LICENSE.txt"} {
+		file, _ := archive.Create(name)
+		file.Write([]byte(files[name]))
+	}
+	archive.Close()
+
+	wheel := filepath.Join(t.TempDir(), "local_lib-0.1-py3-none-any.whl")
+	if err := os.WriteFile(wheel, buffer.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := NewArchiveInspector().GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name: directReferenceName(wheel),
+		URL:  "file://" + wheel,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Name != "local-lib" || meta.Version != "0.1" || meta.LicenseSPDX != "MIT" {
+		t.Errorf("Expected local-lib 0.1 under MIT, got %s %s under %s", meta.Name, meta.Version, meta.LicenseSPDX)
+	}
+	if diff := cmp.Diff([]string{"local_lib-0.1.dist-info/licenses/LICENSE.txt"}, meta.LicenseFiles); diff != "" {
+		t.Errorf("LicenseFiles mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDirectReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		name      string
+		location  string
+	}{
+		{"https://example.com/requests-2.31.0-py3-none-any.whl", "requests", "https://example.com/requests-2.31.0-py3-none-any.whl"},
+		{"https://example.com/python-dateutil-2.8.2.tar.gz#sha256=00", "python-dateutil", "https://example.com/python-dateutil-2.8.2.tar.gz"},
+		{"https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip", "urllib3", "https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip"},
+		{"git+https://github.com/psf/requests.git@v2.31.0#egg=requests", "requests", "https://github.com/psf/requests/archive/v2.31.0.tar.gz"},
+		{"git+https://github.com/psf/black", "black", "https://github.com/psf/black/archive/HEAD.tar.gz"},
+		{"./dist/local_lib-0.1-py3-none-any.whl", "local_lib", "./dist/local_lib-0.1-py3-none-any.whl"},
+	}
+	for _, test := range tests {
+		if name := directReferenceName(test.reference); name != test.name {
+			t.Errorf("Expected name %s for %s, got %s", test.name, test.reference, name)
+		}
+		location, err := archiveURL(test.reference)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if location != test.location {
+			t.Errorf("Expected location %s for %s, got %s", test.location, test.reference, location)
+		}
+	}
+
+	if _, err := archiveURL("hg+https://example.com/repo"); err == nil {
+		t.Error("Expected unsupported VCS url error")
+	}
+}
+
+func TestRequirementsTxtDirectReferences(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "requirements.txt")
+	data := `urllib3 [security] @ https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip ; platform_system != "AIX"
+https://example.com/files/requests-2.31.0-py3-none-any.whl
+git+https://github.com/psf/requests.git@v2.31.0#egg=requests
+./dist/local_lib-0.1-py3-none-any.whl
+six
+`
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dependencies, err := NewRequirementsTxt().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dependency := range dependencies {
+		got = append(got, dependency.Name+" "+dependency.URL)
+	}
+
+	expected := []string{
+		"urllib3 https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip",
+		"requests https://example.com/files/requests-2.31.0-py3-none-any.whl",
+		"requests git+https://github.com/psf/requests.git@v2.31.0#egg=requests",
+		"local_lib ./dist/local_lib-0.1-py3-none-any.whl",
+		"six ",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestArchiveInspectorDetectLicense(t *testing.T) {
+	license, err := os.ReadFile("testdata/LICENSE-MIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdist := filepath.Join(t.TempDir(), "plain-lib-1.0.tar.gz")
+	data := buildSourceArchive(t, map[string]string{
+		"plain-lib-1.0/PKG-INFO":   "Metadata-Version: 2.1\nName: plain-lib\nVersion: 1.0\n",
+		"plain-lib-1.0/LICENSE":    string(license),
+		"plain-lib-1.0/README.rst": "plain-lib",
+	})
+	if err := os.WriteFile(sdist, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := NewArchiveInspector().GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name: "plain-lib",
+		URL:  sdist,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.License != "MIT" || meta.LicenseSPDX != "MIT" || meta.LicenseSource != interfaces.LicenseSourceFile {
+		t.Errorf("Expected MIT detected from the license file, got %q (%s)", meta.License, meta.LicenseSource)
+	}
+	if meta.LicenseConfidence < 0.95 {
+		t.Errorf("Expected the confidence of the detected license, got %.2f", meta.LicenseConfidence)
+	}
+}
@@ -0,0 +1,125 @@
+// Reads the core metadata from the python distribution files
+// the wheels contain <name>.dist-info/METADATA
+// and the source distributions contain <name>-<version>/PKG-INFO
+package python
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxArchiveFileSize limits the size of the file read from the archive,
+// the metadata and license files are small
+const maxArchiveFileSize = 1 << 20
+
+// archiveFile is the file read from the archive
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// isMetadataFile reports whether the archive member is the core metadata
+// of the distribution, the nested PKG-INFO files (e.g. of the egg-info) are ignored
+func isMetadataFile(name string) bool {
+	dir, file, ok := strings.Cut(strings.TrimPrefix(name, "./"), "/")
+	if !ok || strings.Contains(file, "/") {
+		return false
+	}
+	return (strings.HasSuffix(dir, ".dist-info") && file == "METADATA") || file == "PKG-INFO"
+}
+
+// readZipFiles reads the files of the zip archive accepted by the filter
+// only the zip directory and the accepted files are read
+func readZipFiles(reader io.ReaderAt, size int64, filter func(string) bool) ([]archiveFile, error) {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	var files []archiveFile
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !filter(file.Name) {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(r, maxArchiveFileSize))
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{name: file.Name, data: data})
+	}
+	return files, nil
+}
+
+// readTarFiles reads the files of the tar archive accepted by the filter
+// the compression is chosen by the file name
+func readTarFiles(reader io.Reader, filename string, filter func(string) bool) ([]archiveFile, error) {
+	switch {
+	case strings.HasSuffix(filename, ".gz") || strings.HasSuffix(filename, ".tgz"):
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case strings.HasSuffix(filename, ".bz2"):
+		reader = bzip2.NewReader(reader)
+	case strings.HasSuffix(filename, ".tar"):
+	default:
+		return nil, fmt.Errorf("unsupported archive %s", filename)
+	}
+
+	var files []archiveFile
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || !filter(header.Name) {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(archive, maxArchiveFileSize))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{name: header.Name, data: data})
+	}
+}
+
+// readZipMetadata reads the metadata from the wheel or the zip source distribution
+func readZipMetadata(reader io.ReaderAt, size int64) (pypiInfo, error) {
+	files, err := readZipFiles(reader, size, isMetadataFile)
+	if err != nil {
+		return pypiInfo{}, err
+	}
+	return parseMetadataFiles(files)
+}
+
+// readTarMetadata reads the metadata from the tar source distribution
+func readTarMetadata(reader io.Reader, filename string) (pypiInfo, error) {
+	files, err := readTarFiles(reader, filename, isMetadataFile)
+	if err != nil {
+		return pypiInfo{}, err
+	}
+	return parseMetadataFiles(files)
+}
+
+func parseMetadataFiles(files []archiveFile) (pypiInfo, error) {
+	if len(files) == 0 {
+		return pypiInfo{}, fmt.Errorf("metadata not found in the archive")
+	}
+	return parseMetadata(bytes.NewReader(files[0].data))
+}
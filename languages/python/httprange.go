@@ -0,0 +1,142 @@
+package python
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// minRangeSize is the smallest part of the file downloaded by a single request,
+// the zip reader reads the central directory in small pieces
+const minRangeSize = 64 * 1024
+
+// httpReaderAt reads the remote file using the HTTP range requests
+// the downloaded parts are kept, so every part is downloaded only once
+type httpReaderAt struct {
+	ctx   context.Context
+	url   string
+	auth  *indexAuth
+	size  int64
+	parts []filePart
+}
+
+type filePart struct {
+	offset int64
+	data   []byte
+}
+
+// openRemoteFile returns the reader of the remote file and its size
+// The end of the file is downloaded first, it contains the zip central directory
+// If the server does not support the range requests, the whole file is downloaded
+func openRemoteFile(ctx context.Context, url string, auth *indexAuth) (io.ReaderAt, int64, error) {
+	reader := &httpReaderAt{ctx: ctx, url: url, auth: auth}
+
+	resp, err := reader.request(fmt.Sprintf("bytes=-%d", minRangeSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return bytes.NewReader(data), int64(len(data)), nil
+	case http.StatusPartialContent:
+		offset, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, 0, err
+		}
+		reader.size = size
+		reader.parts = append(reader.parts, filePart{offset: offset, data: data})
+		return reader, size, nil
+	}
+	return nil, 0, fmt.Errorf("unexpected response status %s", resp.Status)
+}
+
+func (h *httpReaderAt) request(byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", byteRange)
+	if h.auth != nil {
+		h.auth.apply(req)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func (h *httpReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= h.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > h.size {
+		end = h.size
+	}
+
+	for _, part := range h.parts {
+		if off >= part.offset && end <= part.offset+int64(len(part.data)) {
+			n := copy(p, part.data[off-part.offset:end-part.offset])
+			if n < len(p) {
+				return n, io.EOF
+			}
+			return n, nil
+		}
+	}
+
+	fetchEnd := end
+	if fetchEnd-off < minRangeSize {
+		fetchEnd = off + minRangeSize
+	}
+	if fetchEnd > h.size {
+		fetchEnd = h.size
+	}
+	resp, err := h.request(fmt.Sprintf("bytes=%d-%d", off, fetchEnd-1))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if int64(len(data)) < end-off {
+		return 0, io.ErrUnexpectedEOF
+	}
+	h.parts = append(h.parts, filePart{offset: off, data: data})
+
+	n := copy(p, data[:end-off])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// parseContentRange returns the first byte and the size of the file
+// from the Content-Range header, e.g. bytes 100-199/200
+func parseContentRange(contentRange string) (int64, int64, error) {
+	value, ok := strings.CutPrefix(contentRange, "bytes ")
+	if ok {
+		byteRange, size, found := strings.Cut(value, "/")
+		start, _, valid := strings.Cut(byteRange, "-")
+		if found && valid {
+			offset, err1 := strconv.ParseInt(start, 10, 64)
+			total, err2 := strconv.ParseInt(size, 10, 64)
+			if err1 == nil && err2 == nil {
+				return offset, total, nil
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid Content-Range %q", contentRange)
+}
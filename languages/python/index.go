@@ -0,0 +1,155 @@
+// Queries the package indexes configured for the requirements
+// The indexes are taken from the --index-url and --extra-index-url options
+// of the requirements file, the PIP_INDEX_URL and PIP_EXTRA_INDEX_URL
+// environment variables and PyPI is used when nothing is configured
+// Like pip, all indexes are queried and the package with the highest version is used,
+// when more indexes have the same version, the first one in the order is used
+package python
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// DefaultIndexURL is the index used when no other is configured
+const DefaultIndexURL = "https://pypi.org/simple"
+
+// packageIndexes are the index options read from the requirements file
+type packageIndexes struct {
+	indexURL       string
+	extraIndexURLs []string
+}
+
+// urls returns the index urls in the order they are queried
+// the index url from the requirements file replaces the one from the environment,
+// the extra index urls from both are used
+func (p packageIndexes) urls() []string {
+	indexURL := p.indexURL
+	if indexURL == "" {
+		indexURL = os.Getenv("PIP_INDEX_URL")
+	}
+	if indexURL == "" {
+		indexURL = DefaultIndexURL
+	}
+	urls := []string{indexURL}
+	urls = append(urls, strings.Fields(os.Getenv("PIP_EXTRA_INDEX_URL"))...)
+	urls = append(urls, p.extraIndexURLs...)
+	return urls
+}
+
+// indexRepository queries several package repositories for every package
+type indexRepository struct {
+	repositories []interfaces.PackageRepository
+}
+
+// newIndexRepository creates the repository querying the indexes in the given order
+// the JSON API next to the simple API is preferred (e.g. https://pypi.org/simple
+// and https://pypi.org/pypi/<package_name>/json), the Simple API is used
+// for the indexes that do not implement it
+func newIndexRepository(indexURLs []string) interfaces.PackageRepository {
+	repository := &indexRepository{}
+	seen := map[string]bool{}
+	for _, indexURL := range indexURLs {
+		if seen[indexURL] {
+			continue
+		}
+		seen[indexURL] = true
+		repository.repositories = append(repository.repositories, &fallbackRepository{
+			primary:  newIndexPyPI(indexURL),
+			fallback: NewSimpleAPI(indexURL),
+		})
+	}
+	if len(repository.repositories) == 1 {
+		return repository.repositories[0]
+	}
+	return repository
+}
+
+// newIndexPyPI creates the PyPI JSON API repository of the index
+// the credentials are removed from the url and they are sent in the header
+func newIndexPyPI(indexURL string) *PyPI {
+	index, auth := splitIndexURL(indexURL)
+	base := strings.TrimSuffix(strings.TrimSuffix(index, "/"), "/simple")
+	return &PyPI{
+		name:  "PyPI",
+		url:   base + "/pypi/<package_name>/json",
+		index: index,
+		auth:  auth,
+	}
+}
+
+func (i *indexRepository) GetRepositoryName() string {
+	return "PyPI"
+}
+
+func (i *indexRepository) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	var best *interfaces.PackageMeta
+	var bestVersion *pythonVersion
+	var errs []error
+	stage := interfaces.StageFetch
+	for _, repository := range i.repositories {
+		meta, err := repository.GetPackageInfo(ctx, dependency)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			var pkgErr *interfaces.PackageError
+			if errors.As(err, &pkgErr) {
+				if len(errs) == 0 {
+					stage = pkgErr.Stage
+				}
+				err = pkgErr.Err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", indexName(repository), err))
+			continue
+		}
+
+		version, err := parseVersion(meta.Version)
+		if best == nil || (err == nil && (bestVersion == nil || version.compare(bestVersion) > 0)) {
+			best, bestVersion = meta, version
+		}
+	}
+
+	if best == nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: stage, Err: errors.Join(errs...)}
+	}
+	return best, nil
+}
+
+// indexName returns the index url of the repository to be shown in the errors
+func indexName(repository interfaces.PackageRepository) string {
+	switch r := repository.(type) {
+	case *PyPI:
+		return r.index
+	case *SimpleAPI:
+		return r.url
+	case *fallbackRepository:
+		return indexName(r.fallback)
+	}
+	return repository.GetRepositoryName()
+}
+
+// fallbackRepository uses the fallback repository when the package
+// cannot be downloaded from the primary one
+type fallbackRepository struct {
+	primary  interfaces.PackageRepository
+	fallback interfaces.PackageRepository
+}
+
+func (f *fallbackRepository) GetRepositoryName() string {
+	return f.primary.GetRepositoryName()
+}
+
+func (f *fallbackRepository) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	meta, err := f.primary.GetPackageInfo(ctx, dependency)
+	var pkgErr *interfaces.PackageError
+	if err == nil || ctx.Err() != nil || !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		return meta, err
+	}
+	return f.fallback.GetPackageInfo(ctx, dependency)
+}
@@ -0,0 +1,145 @@
+// Resolves the package license from the python package metadata
+// The metadata contain up to three license sources with the following precedence
+// PEP 639 License-Expression, the trove license classifiers and the free text License field
+// When the metadata say nothing, the license is detected from the bundled license files
+package python
+
+import (
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/spdx"
+)
+
+// licenseCandidate is the license from a single metadata source
+type licenseCandidate struct {
+	source  string
+	license string
+	// spdx is the normalized license, empty when it cannot be normalized
+	spdx string
+	// licenses are set when the source lists several licenses
+	licenses []string
+}
+
+// resolveLicense sets the license of the package from the first available source
+// and records the other sources that disagree with it as conflicts
+// The sources disagree when they mention a license the selected source does not contain,
+// the licenses are compared by their SPDX identifiers when both can be normalized
+func resolveLicense(meta *interfaces.PackageMeta, expression string, classifiers []string, license string) {
+	var candidates []licenseCandidate
+	if expression = strings.TrimSpace(expression); expression != "" {
+		candidates = append(candidates, licenseCandidate{
+			source:  interfaces.LicenseSourceExpression,
+			license: expression,
+			spdx:    spdx.Normalize(expression),
+		})
+	}
+	if licenses := extractLicensesFromClassifiers(classifiers); len(licenses) > 0 {
+		candidates = append(candidates, classifierCandidate(licenses))
+	}
+	if license = licenseFieldName(license); license != "" {
+		candidates = append(candidates, licenseCandidate{
+			source:  interfaces.LicenseSourceField,
+			license: license,
+			spdx:    spdx.Normalize(license),
+		})
+	}
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	selected := candidates[0]
+	meta.License = selected.license
+	meta.LicenseSPDX = selected.spdx
+	meta.LicenseSource = selected.source
+	meta.Licenses = selected.licenses
+
+	selectedIDs := licenseIDs(selected.spdx)
+	for _, candidate := range candidates[1:] {
+		if conflicts(selectedIDs, candidate) {
+			meta.LicenseConflicts = append(meta.LicenseConflicts, candidate.source+": "+candidate.license)
+		}
+	}
+}
+
+// conflicts reports whether the candidate disagrees with the selected license,
+// the licenses disagree only when they both normalize and share no license,
+// the license that cannot be normalized is not a conflict
+func conflicts(selectedIDs map[string]bool, candidate licenseCandidate) bool {
+	ids := licenseIDs(candidate.spdx)
+	if len(selectedIDs) == 0 || len(ids) == 0 {
+		return false
+	}
+	for id := range ids {
+		if selectedIDs[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// classifierCandidate joins the license classifiers into a single disjunction
+// the dual licensed package can be used under any of the licenses
+// the SPDX expression is set only when all the classifiers can be normalized
+func classifierCandidate(licenses []string) licenseCandidate {
+	candidate := licenseCandidate{
+		source:  interfaces.LicenseSourceClassifier,
+		license: strings.Join(licenses, " OR "),
+	}
+	if len(licenses) > 1 {
+		candidate.licenses = licenses
+	}
+
+	var ids []string
+	for _, license := range licenses {
+		id := spdx.Normalize(license)
+		if id == "" {
+			return candidate
+		}
+		ids = append(ids, id)
+	}
+	candidate.spdx = spdx.Normalize(strings.Join(ids, " OR "))
+	return candidate
+}
+
+// licenseIDs returns the set of licenses in the SPDX license expression
+func licenseIDs(expression string) map[string]bool {
+	ids := map[string]bool{}
+	if expression == "" {
+		return ids
+	}
+	parsed, err := spdx.Parse(expression)
+	if err != nil {
+		return ids
+	}
+	for _, license := range spdx.Licenses(parsed) {
+		ids[license.String()] = true
+	}
+	return ids
+}
+
+// licenseFieldName returns the license name from the free text License field
+// Some packages put the whole license text into the field, in such a case
+// only the first non empty line is used, which is usually the license name
+func licenseFieldName(license string) string {
+	for _, line := range strings.Split(license, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// detectLicense sets the license of the package detected from the license files
+// when the metadata do not contain any license
+func detectLicense(meta *interfaces.PackageMeta, files []archiveFile) {
+	if meta.License != "" {
+		return
+	}
+	licenses := make([]spdx.LicenseFile, 0, len(files))
+	for _, file := range files {
+		licenses = append(licenses, spdx.LicenseFile{Name: file.name, Text: string(file.data)})
+	}
+	spdx.DetectLicense(meta, licenses)
+}
@@ -0,0 +1,151 @@
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestResolveLicense(t *testing.T) {
+	type sources struct {
+		expression  string
+		classifiers []string
+		license     string
+	}
+
+	tests := []struct {
+		name     string
+		sources  sources
+		expected interfaces.PackageMeta
+	}{
+		{
+			name: "expression takes precedence",
+			sources: sources{
+				expression:  "MIT OR Apache-2.0",
+				classifiers: []string{"License :: OSI Approved :: MIT License"},
+				license:     "MIT",
+			},
+			expected: interfaces.PackageMeta{
+				License:       "MIT OR Apache-2.0",
+				LicenseSPDX:   "MIT OR Apache-2.0",
+				LicenseSource: interfaces.LicenseSourceExpression,
+			},
+		},
+		{
+			name: "classifier before license field",
+			sources: sources{
+				classifiers: []string{"License :: OSI Approved :: Apache Software License"},
+				license:     "Apache 2.0",
+			},
+			// the classifier without the version is not normalized, so it cannot conflict
+			expected: interfaces.PackageMeta{
+				License:       "Apache Software License",
+				LicenseSource: interfaces.LicenseSourceClassifier,
+			},
+		},
+		{
+			name: "license field only",
+			sources: sources{
+				license: "BSD 3-Clause\n\nCopyright (c) 2023, the authors\nAll rights reserved.",
+			},
+			expected: interfaces.PackageMeta{
+				License:       "BSD 3-Clause",
+				LicenseSPDX:   "BSD-3-Clause",
+				LicenseSource: interfaces.LicenseSourceField,
+			},
+		},
+		{
+			name: "conflicting sources",
+			sources: sources{
+				expression:  "MIT",
+				classifiers: []string{"License :: OSI Approved :: GNU General Public License v3 (GPLv3)"},
+				license:     "Proprietary",
+			},
+			expected: interfaces.PackageMeta{
+				License:          "MIT",
+				LicenseSPDX:      "MIT",
+				LicenseSource:    interfaces.LicenseSourceExpression,
+				LicenseConflicts: []string{"classifier: GNU General Public License v3 (GPLv3)"},
+			},
+		},
+		{
+			name: "overlapping licenses",
+			sources: sources{
+				expression:  "MIT",
+				classifiers: []string{"License :: OSI Approved :: MIT License", "License :: OSI Approved :: Apache Software License 2.0"},
+			},
+			expected: interfaces.PackageMeta{
+				License:       "MIT",
+				LicenseSPDX:   "MIT",
+				LicenseSource: interfaces.LicenseSourceExpression,
+			},
+		},
+		{
+			name: "same unnormalized license",
+			sources: sources{
+				classifiers: []string{"License :: OSI Approved :: Apache Software License"},
+				license:     "apache software license",
+			},
+			expected: interfaces.PackageMeta{
+				License:       "Apache Software License",
+				LicenseSource: interfaces.LicenseSourceClassifier,
+			},
+		},
+		{
+			name:     "no license",
+			sources:  sources{},
+			expected: interfaces.PackageMeta{},
+		},
+	}
+
+	for _, test := range tests {
+		meta := interfaces.PackageMeta{}
+		resolveLicense(&meta, test.sources.expression, test.sources.classifiers, test.sources.license)
+		if diff := cmp.Diff(test.expected, meta); diff != "" {
+			t.Errorf("%s: resolved license mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	readText := func(id string) []byte {
+		data, err := os.ReadFile(filepath.Join("..", "..", "spdx", "texts", id+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		files    []archiveFile
+		license  string
+		licenses []string
+	}{
+		{
+			name:    "LGPL-3.0 shipped with the GPL-3.0 text",
+			files:   []archiveFile{{"COPYING", readText("GPL-3.0-only")}, {"COPYING.LESSER", readText("LGPL-3.0-only")}},
+			license: "LGPL-3.0-only",
+		},
+		{
+			name:     "dual licensed",
+			files:    []archiveFile{{"LICENSE-APACHE", readText("Apache-2.0")}, {"LICENSE-MIT", readText("MIT")}},
+			license:  "Apache-2.0 OR MIT",
+			licenses: []string{"Apache-2.0", "MIT"},
+		},
+	}
+	for _, test := range tests {
+		meta := interfaces.PackageMeta{}
+		detectLicense(&meta, test.files)
+		if meta.License != test.license || meta.LicenseSPDX != test.license || meta.LicenseSource != interfaces.LicenseSourceFile {
+			t.Errorf("%s: expected %s detected from the files, got %s (%s) from %s", test.name, test.license, meta.License, meta.LicenseSPDX, meta.LicenseSource)
+		}
+		if diff := cmp.Diff(test.licenses, meta.Licenses); diff != "" {
+			t.Errorf("%s: Licenses mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Adapts the locked python packages to the shared lock graph, the packages
+// are keyed by the normalized name and the extras are normalized
+package python
+
+import (
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/lockgraph"
+)
+
+// lockPackage is the locked package with the edges to the packages it requires
+type lockPackage struct {
+	name         string
+	dependencies []lockEdge
+	// optional are keyed by the normalized extra
+	optional map[string][]lockEdge
+}
+
+type lockEdge struct {
+	name    string
+	extras  []string
+	markers string
+}
+
+// lockGraph is the graph of the locked packages keyed by the normalized name
+type lockGraph struct {
+	graph *lockgraph.Graph
+}
+
+// newLockGraph creates the graph of the packages, the packages locked in several versions
+// or with several sets of extras are merged into a single node
+func newLockGraph(packages []lockPackage) *lockGraph {
+	merged := map[string]lockgraph.Package{}
+	for _, pkg := range packages {
+		key := interfaces.NormalizeName(pkg.name)
+		node, ok := merged[key]
+		if !ok {
+			node = lockgraph.Package{Name: pkg.name, Optional: map[string][]lockgraph.Edge{}}
+		}
+		node.Dependencies = append(node.Dependencies, lockEdges(pkg.dependencies)...)
+		for extra, edges := range pkg.optional {
+			node.Optional[extra] = append(node.Optional[extra], lockEdges(edges)...)
+		}
+		merged[key] = node
+	}
+	return &lockGraph{graph: lockgraph.New(merged)}
+}
+
+// node returns the visited package, ok is false when the package
+// is not reachable from the direct dependencies
+func (g *lockGraph) node(name string) (*lockgraph.Node, bool) {
+	return g.graph.Node(interfaces.NormalizeName(name))
+}
+
+// walk visits the packages reachable from the direct dependencies
+func (g *lockGraph) walk(dependencies []interfaces.Dependency) {
+	roots := make([]lockgraph.Root, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		roots = append(roots, lockgraph.Root{
+			ID:      interfaces.NormalizeName(dependency.Name),
+			Groups:  dependency.Groups,
+			Extras:  normalizeExtras(dependency.Extras),
+			Markers: dependency.Markers,
+		})
+	}
+	g.graph.Walk(roots)
+}
+
+func lockEdges(edges []lockEdge) []lockgraph.Edge {
+	converted := make([]lockgraph.Edge, 0, len(edges))
+	for _, edge := range edges {
+		converted = append(converted, lockgraph.Edge{
+			ID:      interfaces.NormalizeName(edge.name),
+			Extras:  normalizeExtras(edge.extras),
+			Markers: edge.markers,
+		})
+	}
+	return converted
+}
+
+func normalizeExtras(extras []string) []string {
+	var normalized []string
+	for _, extra := range extras {
+		normalized = append(normalized, interfaces.NormalizeName(extra))
+	}
+	return normalized
+}
@@ -0,0 +1,253 @@
+// Evaluates the PEP 508 environment markers of the python requirements
+// e.g. python_version >= "3.8" and (sys_platform == "win32" or extra == "socks")
+// https://peps.python.org/pep-0508/#environment-markers
+package python
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// DefaultPythonVersion and DefaultPlatform describe the target environment
+// the dependencies are resolved for when no other is requested
+const (
+	DefaultPythonVersion = "3.11"
+	DefaultPlatform      = "linux"
+)
+
+// platformMarkers are the marker values of the supported platforms
+var platformMarkers = map[string]map[string]string{
+	"linux": {
+		"os_name":          "posix",
+		"sys_platform":     "linux",
+		"platform_system":  "Linux",
+		"platform_machine": "x86_64",
+	},
+	"windows": {
+		"os_name":          "nt",
+		"sys_platform":     "win32",
+		"platform_system":  "Windows",
+		"platform_machine": "AMD64",
+	},
+	"darwin": {
+		"os_name":          "posix",
+		"sys_platform":     "darwin",
+		"platform_system":  "Darwin",
+		"platform_machine": "arm64",
+	},
+}
+
+// Platforms returns the names of the platforms accepted by NewEnvironment
+func Platforms() []string {
+	return []string{"linux", "windows", "darwin"}
+}
+
+// NewEnvironment returns the marker variables of the CPython interpreter
+// of the version running on the platform (linux, windows or darwin)
+func NewEnvironment(pythonVersion string, platform string) (interfaces.Environment, error) {
+	version, err := parseVersion(pythonVersion)
+	if err != nil || len(version.release) < 2 || len(version.release) > 3 {
+		return nil, fmt.Errorf("invalid python version %q, expected e.g. 3.11 or 3.11.4", pythonVersion)
+	}
+	markers, ok := platformMarkers[strings.ToLower(platform)]
+	if !ok {
+		return nil, fmt.Errorf("invalid platform %q, expected one of %s", platform, strings.Join(Platforms(), ", "))
+	}
+
+	fullVersion := fmt.Sprintf("%d.%d.%d", version.release[0], version.release[1], padRelease(version.release, 3)[2])
+	environment := interfaces.Environment{
+		"python_version":                 fmt.Sprintf("%d.%d", version.release[0], version.release[1]),
+		"python_full_version":            fullVersion,
+		"implementation_name":            "cpython",
+		"implementation_version":         fullVersion,
+		"platform_python_implementation": "CPython",
+		"platform_release":               "",
+		"platform_version":               "",
+	}
+	for name, value := range markers {
+		environment[name] = value
+	}
+	return environment, nil
+}
+
+// The grammar of the markers, the and operator has higher precedence than the or operator
+type markerOr struct {
+	And []*markerAnd `parser:"@@ ( 'or' @@ )*"`
+}
+
+type markerAnd struct {
+	Atoms []*markerAtom `parser:"@@ ( 'and' @@ )*"`
+}
+
+type markerAtom struct {
+	Group      *markerOr         `parser:"  '(' @@ ')'"`
+	Comparison *markerComparison `parser:"| @@"`
+}
+
+type markerComparison struct {
+	Left     *markerValue `parser:"@@"`
+	Operator string       `parser:"@( Operator | 'in' | 'not' 'in' )"`
+	Right    *markerValue `parser:"@@"`
+}
+
+type markerValue struct {
+	Variable *string `parser:"  @Ident"`
+	String   *string `parser:"| @String"`
+}
+
+var markerParser = participle.MustBuild[markerOr](
+	participle.Lexer(lexer.MustSimple([]lexer.SimpleRule{
+		{Name: "String", Pattern: `'[^']*'|"[^"]*"`},
+		{Name: "Operator", Pattern: `===|==|!=|~=|<=|>=|<|>`},
+		{Name: "Ident", Pattern: `[a-zA-Z_][a-zA-Z_0-9.]*`},
+		{Name: "Punct", Pattern: `[()]`},
+		{Name: "Whitespace", Pattern: `\s+`},
+	})),
+	participle.Unquote("String"),
+	participle.UseLookahead(2),
+	participle.Elide("Whitespace"),
+)
+
+// versionMarkers are compared using the PEP 440 version rules
+var versionMarkers = map[string]bool{
+	"python_version":         true,
+	"python_full_version":    true,
+	"implementation_version": true,
+}
+
+// evaluateMarkers reports whether the markers are satisfied in the environment
+// extras are the extras requested for the package declaring the requirement,
+// the extra marker is satisfied when any of them matches
+// Empty markers are always satisfied
+func evaluateMarkers(markers string, environment interfaces.Environment, extras []string) (bool, error) {
+	if strings.TrimSpace(markers) == "" {
+		return true, nil
+	}
+	parsed, err := markerParser.ParseString("", markers)
+	if err != nil {
+		return false, fmt.Errorf("invalid markers %q: %w", markers, err)
+	}
+	return parsed.evaluate(environment, extras)
+}
+
+func (o *markerOr) evaluate(environment interfaces.Environment, extras []string) (bool, error) {
+	for _, and := range o.And {
+		ok, err := and.evaluate(environment, extras)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (a *markerAnd) evaluate(environment interfaces.Environment, extras []string) (bool, error) {
+	for _, atom := range a.Atoms {
+		ok, err := atom.evaluate(environment, extras)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (a *markerAtom) evaluate(environment interfaces.Environment, extras []string) (bool, error) {
+	if a.Group != nil {
+		return a.Group.evaluate(environment, extras)
+	}
+	return a.Comparison.evaluate(environment, extras)
+}
+
+func (c *markerComparison) evaluate(environment interfaces.Environment, extras []string) (bool, error) {
+	// the extra marker is compared against every requested extra
+	if c.Left.isVariable("extra") || c.Right.isVariable("extra") {
+		if len(extras) == 0 {
+			return c.compare(environment, "")
+		}
+		for _, extra := range extras {
+			ok, err := c.compare(environment, extra)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+	return c.compare(environment, "")
+}
+
+// compare evaluates the comparison, the extra is the value of the extra marker
+func (c *markerComparison) compare(environment interfaces.Environment, extra string) (bool, error) {
+	left, err := c.Left.resolve(environment, extra)
+	if err != nil {
+		return false, err
+	}
+	right, err := c.Right.resolve(environment, extra)
+	if err != nil {
+		return false, err
+	}
+
+	// the extras are compared by their normalized names
+	if c.Left.isVariable("extra") || c.Right.isVariable("extra") {
+		left, right = interfaces.NormalizeName(left), interfaces.NormalizeName(right)
+	}
+
+	switch c.Operator {
+	case "in":
+		return strings.Contains(right, left), nil
+	case "notin":
+		return !strings.Contains(right, left), nil
+	}
+
+	if c.Left.isVersion() || c.Right.isVersion() {
+		if _, err := parseVersion(left); err == nil {
+			if ok, err := matchesSpecifier(left, interfaces.VersionSpecifier{Operator: c.Operator, Value: right}); err == nil {
+				return ok, nil
+			}
+		}
+	}
+
+	// the values that are not versions are compared as strings
+	switch c.Operator {
+	case "==", "===":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	case "<":
+		return left < right, nil
+	case "<=":
+		return left <= right, nil
+	case ">":
+		return left > right, nil
+	case ">=":
+		return left >= right, nil
+	}
+	return false, fmt.Errorf("operator %s cannot compare %q and %q", c.Operator, left, right)
+}
+
+func (v *markerValue) isVariable(name string) bool {
+	return v.Variable != nil && *v.Variable == name
+}
+
+func (v *markerValue) isVersion() bool {
+	return v.Variable != nil && versionMarkers[*v.Variable]
+}
+
+// resolve returns the value of the variable from the environment or the string literal
+func (v *markerValue) resolve(environment interfaces.Environment, extra string) (string, error) {
+	if v.String != nil {
+		return *v.String, nil
+	}
+	name := *v.Variable
+	if name == "extra" {
+		return extra, nil
+	}
+	value, ok := environment[name]
+	if !ok {
+		return "", fmt.Errorf("unknown marker variable %s", name)
+	}
+	return value, nil
+}
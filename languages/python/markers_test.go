@@ -0,0 +1,113 @@
+package python
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestEvaluateMarkers(t *testing.T) {
+	linux, err := NewEnvironment("3.8", "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		markers  string
+		extras   []string
+		expected bool
+	}{
+		{"", nil, true},
+		{`python_version >= "3.6"`, nil, true},
+		{`python_version < "3.10"`, nil, true},
+		{`python_full_version >= "3.8.1"`, nil, false},
+		{`sys_platform == "win32"`, nil, false},
+		{`platform_system != "AIX" and os_name == 'posix'`, nil, true},
+		{`sys_platform == "win32" or python_version >= "3.7"`, nil, true},
+		{`(sys_platform == "win32" or sys_platform == "darwin") and python_version >= "3.7"`, nil, false},
+		{`"linux" in sys_platform`, nil, true},
+		{`platform_machine not in "x86_64 aarch64"`, nil, false},
+		{`extra == "socks"`, nil, false},
+		{`extra == "socks"`, []string{"security", "socks"}, true},
+		{`extra == "Test_Utils"`, []string{"test-utils"}, true},
+		{`python_version >= "3" and extra == "socks"`, []string{"other"}, false},
+	}
+	for _, test := range tests {
+		ok, err := evaluateMarkers(test.markers, linux, test.extras)
+		if err != nil {
+			t.Fatalf("%s: %v", test.markers, err)
+		}
+		if ok != test.expected {
+			t.Errorf("Expected %s to be %t", test.markers, test.expected)
+		}
+	}
+
+	if _, err := evaluateMarkers(`unknown_variable == "1"`, linux, nil); err == nil {
+		t.Error("Expected unknown variable error")
+	}
+	if _, err := evaluateMarkers(`python_version >=`, linux, nil); err == nil {
+		t.Error("Expected syntax error")
+	}
+}
+
+func TestNewEnvironment(t *testing.T) {
+	windows, err := NewEnvironment("3.12", "windows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if windows["python_full_version"] != "3.12.0" || windows["sys_platform"] != "win32" {
+		t.Errorf("Unexpected environment %v", windows)
+	}
+
+	for _, invalid := range [][2]string{{"3", "linux"}, {"3.11", "solaris"}} {
+		if _, err := NewEnvironment(invalid[0], invalid[1]); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+}
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		requirement string
+		expected    interfaces.Dependency
+	}{
+		{"soupsieve>1.2", interfaces.Dependency{
+			Name:     "soupsieve",
+			Versions: []interfaces.VersionSpecifier{{Operator: ">", Value: "1.2"}},
+		}},
+		{"urllib3 (<3,>=1.21.1)", interfaces.Dependency{
+			Name:     "urllib3",
+			Versions: []interfaces.VersionSpecifier{{Operator: "<", Value: "3"}, {Operator: ">=", Value: "1.21.1"}},
+		}},
+		{"PySocks!=1.5.7,>=1.5.6; extra == 'socks'", interfaces.Dependency{
+			Name:     "PySocks",
+			Versions: []interfaces.VersionSpecifier{{Operator: "!=", Value: "1.5.7"}, {Operator: ">=", Value: "1.5.6"}},
+			Markers:  "extra == 'socks'",
+		}},
+		{"requests[security, socks] ; python_version < '3'", interfaces.Dependency{
+			Name:    "requests",
+			Extras:  []string{"security", "socks"},
+			Markers: "python_version < '3'",
+		}},
+		{"pip @ https://github.com/pypa/pip/archive/1.3.1.zip#sha1=da9234ee ; os_name == 'nt'", interfaces.Dependency{
+			Name:    "pip",
+			Markers: "os_name == 'nt'",
+		}},
+	}
+	for _, test := range tests {
+		dependency, err := parseRequirement(test.requirement)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.expected, dependency); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", test.requirement, diff)
+		}
+	}
+
+	for _, invalid := range []string{"", "name >> 1"} {
+		if _, err := parseRequirement(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Reads the python core metadata, the METADATA file of the wheels
+// and the PKG-INFO file of the source distributions
+// https://packaging.python.org/en/latest/specifications/core-metadata/
+package python
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strings"
+)
+
+// parseMetadata reads the core metadata to the same structure
+// the PyPI JSON API returns, so both are converted to the PackageMeta the same way
+func parseMetadata(r io.Reader) (pypiInfo, error) {
+	reader := textproto.NewReader(bufio.NewReader(r))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return pypiInfo{}, fmt.Errorf("invalid metadata: %w", err)
+	}
+	if header.Get("Name") == "" {
+		return pypiInfo{}, fmt.Errorf("invalid metadata: missing name")
+	}
+
+	info := pypiInfo{
+		Author:            header.Get("Author"),
+		Name:              header.Get("Name"),
+		Version:           header.Get("Version"),
+		Classifiers:       header.Values("Classifier"),
+		Description:       header.Get("Description"),
+		Homepage:          header.Get("Home-Page"),
+		License:           metadataLicense(header.Get("License")),
+		LicenseExpression: header.Get("License-Expression"),
+		LicenseFiles:      header.Values("License-File"),
+		RequiresDist:      header.Values("Requires-Dist"),
+	}
+	if info.Author == "" {
+		info.Author = header.Get("Author-Email")
+	}
+	for _, projectURL := range header.Values("Project-Url") {
+		label, url, ok := strings.Cut(projectURL, ",")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(label)) {
+		case "homepage":
+			info.ProjectURL.Homepage = strings.TrimSpace(url)
+		case "source", "source code", "repository":
+			info.ProjectURL.Source = strings.TrimSpace(url)
+		}
+	}
+	if info.ProjectURL.Homepage == "" {
+		info.ProjectURL.Homepage = info.Homepage
+	}
+
+	// since the metadata version 2.1 the description is the message body
+	if body, err := io.ReadAll(reader.R); err == nil && len(strings.TrimSpace(string(body))) > 0 {
+		info.Description = strings.TrimSpace(string(body))
+	}
+	return info, nil
+}
+
+// metadataLicense restores the lines of the multi line License field
+// the continuation lines start with the indentation and a pipe,
+// the header reader joins them to a single line separated by " |"
+func metadataLicense(license string) string {
+	return strings.TrimSpace(strings.ReplaceAll(license, " |", "\n"))
+}
@@ -0,0 +1,205 @@
+// Reads the dependencies locked by PDM https://pdm-project.org/latest/usage/lockfile/
+// The lock contains every package pinned to the exact version with its requirements,
+// the package required with extras is locked once more with the extras and the requirements
+// the extras add, the pyproject.toml next to the lock tells which packages are
+// the direct dependencies, the newer locks record the groups of the packages as well
+package python
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// pdmTool is the [tool.pdm] table of pyproject.toml
+type pdmTool struct {
+	// DevDependencies are keyed by the dependency group
+	DevDependencies map[string][]string `toml:"dev-dependencies"`
+	Source          []pdmSource         `toml:"source"`
+}
+
+// pdmSource is the package index configured for the project,
+// the source named pypi replaces PyPI
+type pdmSource struct {
+	Name string `toml:"name"`
+	URL  string `toml:"url"`
+	// Type is index or find_links, the find_links sources are not package indexes
+	Type string `toml:"type"`
+}
+
+type pdmLockFile struct {
+	Package []pdmPackage `toml:"package"`
+}
+
+type pdmPackage struct {
+	Name    string   `toml:"name"`
+	Version string   `toml:"version"`
+	Groups  []string `toml:"groups"`
+	// Extras are set for the package locked once more with the extras
+	Extras       []string `toml:"extras"`
+	Dependencies []string `toml:"dependencies"`
+	Git          string   `toml:"git"`
+	Revision     string   `toml:"revision"`
+	URL          string   `toml:"url"`
+	Path         string   `toml:"path"`
+}
+
+// PdmLock represents the pdm.lock file
+type PdmLock struct {
+	indexes []string
+}
+
+// NewPdmLock creates a new instance of the PdmLock struct
+func NewPdmLock() *PdmLock {
+	return &PdmLock{}
+}
+
+func (p *PdmLock) GetDepFileType() string {
+	return "python/pdm.lock"
+}
+
+// GetRepository returns the repository querying the sources of the project, PyPI by default
+func (p *PdmLock) GetRepository() interfaces.PackageRepository {
+	indexes := p.indexes
+	if len(indexes) == 0 {
+		indexes = []string{DefaultIndexURL}
+	}
+	return newDirectReferenceRepository(indexes, nil)
+}
+
+func (p *PdmLock) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return evaluateMarkers(dependency.Markers, environment, extras)
+}
+
+// GetDependencies returns the locked packages pinned to the locked version
+// The packages pulled in by the other packages have the path of the packages requiring them
+// when the pyproject.toml is next to the lock
+func (p *PdmLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var lock pdmLockFile
+	if _, err := toml.Decode(string(data), &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid pdm.lock: %w", fileName, err)
+	}
+
+	p.indexes = nil
+	var roots []interfaces.Dependency
+	pyproject := filepath.Join(filepath.Dir(fileName), "pyproject.toml")
+	if _, err := os.Stat(pyproject); err == nil {
+		var file pyprojectFile
+		roots, file, err = readPyprojectDependencies(pyproject)
+		if err != nil {
+			return nil, err
+		}
+		p.indexes = pdmIndexes(file.Tool.PDM.Source)
+	}
+
+	packages, err := pdmLockPackages(lock.Package)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	graph := newLockGraph(packages)
+	graph.walk(roots)
+
+	var dependencies []interfaces.Dependency
+	for _, pkg := range lock.Package {
+		// the package with the extras is the same release as the package without them
+		if len(pkg.Extras) > 0 {
+			continue
+		}
+		dependency := interfaces.Dependency{Name: pkg.Name, File: fileName}
+		if pkg.Version != "" {
+			dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: pkg.Version}}
+		}
+		if node, ok := graph.node(pkg.Name); ok {
+			dependency.Path = node.Path
+			dependency.Groups = node.Groups
+			dependency.Markers = node.Markers()
+		}
+		if len(pkg.Groups) > 0 {
+			dependency.Groups = pkg.Groups
+		}
+
+		switch {
+		case pkg.Git != "":
+			dependency.URL = "git+" + pkg.Git
+			if pkg.Revision != "" {
+				dependency.URL += "@" + pkg.Revision
+			}
+		case pkg.URL != "":
+			dependency.URL = pkg.URL
+		case pkg.Path != "":
+			dependency.URL = pkg.Path
+			if !filepath.IsAbs(pkg.Path) {
+				dependency.URL = filepath.Join(filepath.Dir(fileName), pkg.Path)
+			}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// pdmLockPackages converts the locked packages to the nodes of the dependency graph
+// the requirements of the package locked with the extras are required by the extras,
+// except the requirement of the package itself
+func pdmLockPackages(packages []pdmPackage) ([]lockPackage, error) {
+	var converted []lockPackage
+	for _, pkg := range packages {
+		node := lockPackage{name: pkg.Name, optional: map[string][]lockEdge{}}
+		for _, requirement := range pkg.Dependencies {
+			dependency, err := parseRequirement(requirement)
+			if err != nil {
+				return nil, fmt.Errorf("package %s: %w", pkg.Name, err)
+			}
+			if interfaces.NormalizeName(dependency.Name) == interfaces.NormalizeName(pkg.Name) {
+				continue
+			}
+			edge := lockEdge{name: dependency.Name, extras: dependency.Extras, markers: dependency.Markers}
+			if len(pkg.Extras) == 0 {
+				node.dependencies = append(node.dependencies, edge)
+				continue
+			}
+			for _, extra := range pkg.Extras {
+				node.optional[interfaces.NormalizeName(extra)] = append(node.optional[interfaces.NormalizeName(extra)], edge)
+			}
+		}
+		converted = append(converted, node)
+	}
+	return converted, nil
+}
+
+// pdmIndexes returns the urls of the package indexes of the project
+func pdmIndexes(sources []pdmSource) []string {
+	indexes := []string{DefaultIndexURL}
+	for _, source := range sources {
+		switch {
+		case source.Type == "find_links" || source.URL == "":
+		case source.Name == "pypi":
+			indexes[0] = os.ExpandEnv(source.URL)
+		default:
+			indexes = append(indexes, os.ExpandEnv(source.URL))
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,78 @@
+package python
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPdmLock(t *testing.T) {
+	pdmLock := NewPdmLock()
+	fileName := filepath.Join("testdata", "pdm", "pdm.lock")
+	dependencies, err := pdmLock.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type locked struct {
+		name, version, groups, path, url, markers string
+	}
+	var got []locked
+	for dependency := range dependencies {
+		got = append(got, locked{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			groups:  strings.Join(dependency.Groups, ","),
+			path:    strings.Join(dependency.Path, " > "),
+			url:     dependency.URL,
+			markers: dependency.Markers,
+		})
+	}
+
+	expected := []locked{
+		{name: "certifi", version: "2023.7.22", groups: "main", path: "requests"},
+		{name: "colorama", version: "0.4.6", groups: "test", path: "pytest", markers: `sys_platform == "win32"`},
+		{name: "pysocks", version: "1.7.1", groups: "main", path: "requests"},
+		{name: "pytest", version: "7.4.3", groups: "test"},
+		{name: "requests", version: "2.31.0", groups: "main"},
+		{name: "toolbelt", version: "0.3.0", groups: "main", url: "git+https://github.com/example/toolbelt.git@4f1c2d3e5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d"},
+		{name: "urllib3", version: "2.0.7", groups: "main", path: "requests"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{DefaultIndexURL, "https://pypi.example.com/simple"}, pdmLock.indexes); diff != "" {
+		t.Errorf("Index urls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPdmLockGroups(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "pdm.lock")
+	writeFiles(t, filepath.Dir(fileName), map[string]string{"pdm.lock": `[[package]]
+name = "requests"
+version = "2.31.0"
+groups = ["default", "test"]
+
+[[package]]
+name = "local"
+version = "0.1.0"
+path = "./libs/local"
+`})
+
+	dependencies, err := NewPdmLock().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dependency := range dependencies {
+		got = append(got, dependency.Name+" "+strings.Join(dependency.Groups, ",")+" "+dependency.URL)
+	}
+	expected := []string{"requests default,test ", "local  " + filepath.Join(filepath.Dir(fileName), "libs", "local")}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,69 @@
+// Parses the PEP 508 requirement strings found in the package metadata
+// e.g. the Requires-Dist entries "urllib3 (<3,>=1.21.1)" or "PySocks!=1.5.7,>=1.5.6; extra == 'socks'"
+// https://peps.python.org/pep-0508/
+package python
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+var (
+	requirementName      = regexp.MustCompile(`^\s*([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*`)
+	requirementExtras    = regexp.MustCompile(`^\[([^\]]*)\]\s*`)
+	requirementSpecifier = regexp.MustCompile(`^\s*(===|==|!=|~=|<=|>=|<|>)\s*([^\s,;]+)\s*$`)
+)
+
+// parseRequirement parses the PEP 508 requirement string to the dependency
+// The direct url references (name @ url) are accepted, the url is ignored
+func parseRequirement(requirement string) (interfaces.Dependency, error) {
+	var dependency interfaces.Dependency
+
+	match := requirementName.FindStringSubmatch(requirement)
+	if match == nil {
+		return dependency, fmt.Errorf("invalid requirement %q: missing package name", requirement)
+	}
+	dependency.Name = match[1]
+	rest := requirement[len(match[0]):]
+
+	if match := requirementExtras.FindStringSubmatch(rest); match != nil {
+		for _, extra := range strings.Split(match[1], ",") {
+			if extra = strings.TrimSpace(extra); extra != "" {
+				dependency.Extras = append(dependency.Extras, extra)
+			}
+		}
+		rest = rest[len(match[0]):]
+	}
+
+	if strings.HasPrefix(rest, "@") {
+		// the url can contain ';', the markers have to be separated by whitespace
+		if index := strings.Index(rest, " ;"); index >= 0 {
+			dependency.Markers = strings.TrimSpace(rest[index+2:])
+		}
+		return dependency, nil
+	}
+
+	if index := strings.Index(rest, ";"); index >= 0 {
+		dependency.Markers = strings.TrimSpace(rest[index+1:])
+		rest = rest[:index]
+	}
+
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") && strings.HasSuffix(rest, ")") {
+		rest = rest[1 : len(rest)-1]
+	}
+	if strings.TrimSpace(rest) == "" {
+		return dependency, nil
+	}
+	for _, specifier := range strings.Split(rest, ",") {
+		match := requirementSpecifier.FindStringSubmatch(specifier)
+		if match == nil {
+			return dependency, fmt.Errorf("invalid requirement %q: invalid version specifier %q", requirement, strings.TrimSpace(specifier))
+		}
+		dependency.Versions = append(dependency.Versions, interfaces.VersionSpecifier{Operator: match[1], Value: match[2]})
+	}
+	return dependency, nil
+}
@@ -0,0 +1,190 @@
+// Reads the dependencies locked by pipenv https://pipenv.pypa.io/en/latest/pipfile.html
+// The default section contains the packages the project needs to run
+// and the develop section the packages needed only for the development,
+// the packages are put into the default and develop groups, so the development
+// packages can be left out
+package python
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// Groups of the Pipfile.lock sections
+const (
+	PipfileDefaultGroup = "default"
+	PipfileDevelopGroup = "develop"
+)
+
+type pipfileLockFile struct {
+	Meta struct {
+		Sources []pipfileSource `json:"sources"`
+	} `json:"_meta"`
+	Default map[string]pipfilePackage `json:"default"`
+	Develop map[string]pipfilePackage `json:"develop"`
+}
+
+// pipfileSource is the package index, the url can contain the environment variables
+type pipfileSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// pipfilePackage is the locked package, the packages from the package index
+// have the version, the other ones have the git, file or path reference
+type pipfilePackage struct {
+	Version string   `json:"version"`
+	Extras  []string `json:"extras"`
+	Markers string   `json:"markers"`
+	Index   string   `json:"index"`
+	Git     string   `json:"git"`
+	Ref     string   `json:"ref"`
+	File    string   `json:"file"`
+	Path    string   `json:"path"`
+}
+
+// PipfileLock represents the Pipfile.lock file
+type PipfileLock struct {
+	sources []string
+}
+
+// NewPipfileLock creates a new instance of the PipfileLock struct
+func NewPipfileLock() *PipfileLock {
+	return &PipfileLock{}
+}
+
+func (p *PipfileLock) GetDepFileType() string {
+	return "python/Pipfile.lock"
+}
+
+// GetRepository returns the repository querying the first source of the lock,
+// the packages locked with another index are downloaded from it
+func (p *PipfileLock) GetRepository() interfaces.PackageRepository {
+	indexURL := DefaultIndexURL
+	if len(p.sources) > 0 {
+		indexURL = p.sources[0]
+	}
+	return newDirectReferenceRepository([]string{indexURL}, p.sources)
+}
+
+func (p *PipfileLock) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return evaluateMarkers(dependency.Markers, environment, extras)
+}
+
+// GetDependencies returns the packages of the default section followed by the packages
+// of the develop section, the package locked in both sections is returned once with both groups
+func (p *PipfileLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var lock pipfileLockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid Pipfile.lock: %w", fileName, err)
+	}
+
+	sources := map[string]string{}
+	p.sources = nil
+	for _, source := range lock.Meta.Sources {
+		url := os.ExpandEnv(source.URL)
+		sources[source.Name] = url
+		p.sources = append(p.sources, url)
+	}
+
+	var dependencies []interfaces.Dependency
+	positions := map[string]int{}
+	sections := []struct {
+		group    string
+		packages map[string]pipfilePackage
+	}{
+		{PipfileDefaultGroup, lock.Default},
+		{PipfileDevelopGroup, lock.Develop},
+	}
+	for _, section := range sections {
+		names := make([]string, 0, len(section.packages))
+		for name := range section.packages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if position, ok := positions[interfaces.NormalizeName(name)]; ok {
+				dependencies[position].Groups = append(dependencies[position].Groups, section.group)
+				continue
+			}
+			dependency, err := pipfileDependency(name, section.packages[name], fileName)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fileName, err)
+			}
+			if pkg := section.packages[name]; pkg.Index != "" {
+				url, ok := sources[pkg.Index]
+				if !ok {
+					return nil, fmt.Errorf("%s: unknown index %s of package %s", fileName, pkg.Index, name)
+				}
+				dependency.Index = url
+			}
+			dependency.Groups = []string{section.group}
+			dependency.File = fileName
+			positions[interfaces.NormalizeName(name)] = len(dependencies)
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// pipfileDependency converts the locked package to the dependency,
+// the local paths are relative to the lock
+func pipfileDependency(name string, pkg pipfilePackage, fileName string) (interfaces.Dependency, error) {
+	requirement := name
+	if len(pkg.Extras) > 0 {
+		requirement += "[" + strings.Join(pkg.Extras, ",") + "]"
+	}
+	requirement += pkg.Version
+	if pkg.Markers != "" {
+		requirement += "; " + pkg.Markers
+	}
+	dependency, err := parseRequirement(requirement)
+	if err != nil {
+		return dependency, err
+	}
+
+	switch {
+	case pkg.Git != "":
+		dependency.URL = pkg.Git
+		if !strings.HasPrefix(dependency.URL, "git+") {
+			dependency.URL = "git+" + dependency.URL
+		}
+		if pkg.Ref != "" {
+			dependency.URL += "@" + pkg.Ref
+		}
+	case pkg.File != "":
+		dependency.URL = pkg.File
+	case pkg.Path != "":
+		dependency.URL = pkg.Path
+		if !filepath.IsAbs(dependency.URL) {
+			dependency.URL = filepath.Join(filepath.Dir(fileName), dependency.URL)
+		}
+	}
+	return dependency, nil
+}
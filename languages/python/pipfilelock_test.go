@@ -0,0 +1,55 @@
+package python
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPipfileLock(t *testing.T) {
+	t.Setenv("PRIVATE_INDEX_USER", "deploy")
+
+	pipfileLock := NewPipfileLock()
+	fileName := filepath.Join("testdata", "Pipfile.lock")
+	dependencies, err := pipfileLock.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for dependency := range dependencies {
+		line := dependency.Name + "==" + dependency.PinnedVersion() + " [" + strings.Join(dependency.Groups, ",") + "]"
+		if dependency.Extras != nil {
+			line += " extras " + strings.Join(dependency.Extras, ",")
+		}
+		if dependency.Markers != "" {
+			line += " markers " + dependency.Markers
+		}
+		if dependency.Index != "" {
+			line += " index " + dependency.Index
+		}
+		if dependency.URL != "" {
+			line += " url " + dependency.URL
+		}
+		got = append(got, line)
+	}
+
+	expected := []string{
+		"certifi==2023.7.22 [default,develop] markers python_version >= '3.6'",
+		"internal-lib==1.4.2 [default] index https://deploy@pypi.example.com/simple",
+		"requests==2.31.0 [default] extras socks markers python_version >= '3.7' index https://pypi.org/simple",
+		"toolbelt== [default] url git+https://github.com/example/toolbelt.git@4f1c2d3e5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d",
+		"pytest==7.4.3 [develop] index https://pypi.org/simple",
+		"service== [develop] url testdata",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"https://pypi.org/simple", "https://deploy@pypi.example.com/simple"}, pipfileLock.sources); diff != "" {
+		t.Errorf("Sources mismatch (-want +got):\n%s", diff)
+	}
+}
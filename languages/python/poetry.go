@@ -0,0 +1,477 @@
+// Reads the dependencies locked by poetry https://python-poetry.org/docs/basic-usage/#installing-with-poetrylock
+// The lock contains every package of the project pinned to the exact version,
+// the pyproject.toml next to the lock tells which packages are the direct dependencies
+// and which groups they belong to, the older locks record the category of the package
+// and the newer ones record the groups as well
+package python
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+var (
+	poetryConstraintPattern = regexp.MustCompile(`(\^|~=|~|===|==|!=|<=|>=|<|>|=)?\s*([^\s,<>=!~^]+)`)
+	releasePattern          = regexp.MustCompile(`^\d+(\.\d+)*`)
+)
+
+// poetryTool is the [tool.poetry] table of pyproject.toml
+// the dependencies are either the version constraints or the tables
+// with the constraint and the source of the dependency
+type poetryTool struct {
+	Dependencies    map[string]interface{} `toml:"dependencies"`
+	DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+	Group           map[string]struct {
+		Dependencies map[string]interface{} `toml:"dependencies"`
+	} `toml:"group"`
+	Source []poetrySource `toml:"source"`
+}
+
+// poetrySource is the package index configured for the project
+type poetrySource struct {
+	Name     string `toml:"name"`
+	URL      string `toml:"url"`
+	Priority string `toml:"priority"`
+	// Default and Secondary are replaced by Priority in poetry 1.5
+	Default   bool `toml:"default"`
+	Secondary bool `toml:"secondary"`
+}
+
+type poetrySources []poetrySource
+
+// urls returns the indexes queried for the dependencies without the explicit source
+// the primary sources replace PyPI, the supplemental sources are queried after them
+func (p poetrySources) urls() []string {
+	var primary, supplemental []string
+	for _, source := range p {
+		switch {
+		case source.URL == "":
+			// the PyPI source has no url
+		case source.Priority == "explicit":
+		case source.Priority == "supplemental" || source.Priority == "secondary" || source.Secondary:
+			supplemental = append(supplemental, source.URL)
+		default:
+			primary = append(primary, source.URL)
+		}
+	}
+	if len(primary) == 0 {
+		primary = []string{DefaultIndexURL}
+	}
+	return append(primary, supplemental...)
+}
+
+// explicitURLs returns the urls of all sources, the dependencies can name any of them
+func (p poetrySources) explicitURLs() []string {
+	var urls []string
+	for _, source := range p {
+		if source.URL != "" {
+			urls = append(urls, source.URL)
+		}
+	}
+	return urls
+}
+
+func (p poetrySources) url(name string) string {
+	for _, source := range p {
+		if strings.EqualFold(source.Name, name) {
+			return source.URL
+		}
+	}
+	return ""
+}
+
+// poetryDependency converts the dependency of the [tool.poetry.dependencies] table
+// e.g. requests = "^2.31" or requests = {version = "^2.31", extras = ["socks"]}
+// The dependencies with several constraints (e.g. for different python versions)
+// are not pinned to any version
+func poetryDependency(name string, value interface{}, sources poetrySources) (interfaces.Dependency, error) {
+	dependency := interfaces.Dependency{Name: name}
+	switch value := value.(type) {
+	case string:
+		versions, err := poetryConstraint(value)
+		if err != nil {
+			return dependency, fmt.Errorf("dependency %s: %w", name, err)
+		}
+		dependency.Versions = versions
+	case map[string]interface{}:
+		if version, ok := value["version"].(string); ok {
+			versions, err := poetryConstraint(version)
+			if err != nil {
+				return dependency, fmt.Errorf("dependency %s: %w", name, err)
+			}
+			dependency.Versions = versions
+		}
+		if extras, ok := value["extras"].([]interface{}); ok {
+			for _, extra := range extras {
+				if extra, ok := extra.(string); ok {
+					dependency.Extras = append(dependency.Extras, extra)
+				}
+			}
+		}
+		markers, err := poetryMarkers(value)
+		if err != nil {
+			return dependency, fmt.Errorf("dependency %s: %w", name, err)
+		}
+		dependency.Markers = markers
+		if source, ok := value["source"].(string); ok {
+			dependency.Index = sources.url(source)
+		}
+		dependency.URL = poetryReference(value)
+	case []interface{}:
+		// the multiple constraints dependency
+	default:
+		return dependency, fmt.Errorf("dependency %s: invalid constraint", name)
+	}
+	return dependency, nil
+}
+
+// poetryMarkers joins the markers and the python constraint of the dependency
+func poetryMarkers(value map[string]interface{}) (string, error) {
+	var markers []string
+	if marker, ok := value["markers"].(string); ok && marker != "" {
+		markers = append(markers, marker)
+	}
+	if python, ok := value["python"].(string); ok {
+		versions, err := poetryConstraint(python)
+		if err != nil {
+			return "", err
+		}
+		for _, version := range versions {
+			markers = append(markers, fmt.Sprintf("python_version %s %q", version.Operator, version.Value))
+		}
+	}
+	if len(markers) == 1 {
+		return markers[0], nil
+	}
+	for i, marker := range markers {
+		markers[i] = "(" + marker + ")"
+	}
+	return strings.Join(markers, " and "), nil
+}
+
+// poetryReference returns the direct reference of the git, url and path dependencies
+// e.g. {git = "https://github.com/psf/requests.git", tag = "v2.31.0"}
+func poetryReference(value map[string]interface{}) string {
+	if git, ok := value["git"].(string); ok {
+		for _, key := range []string{"rev", "tag", "branch"} {
+			if ref, ok := value[key].(string); ok {
+				return "git+" + git + "@" + ref
+			}
+		}
+		return "git+" + git
+	}
+	for _, key := range []string{"url", "path"} {
+		if reference, ok := value[key].(string); ok {
+			return reference
+		}
+	}
+	return ""
+}
+
+// poetryConstraint converts the poetry version constraint to the PEP 440 specifiers
+// https://python-poetry.org/docs/dependency-specification/#version-constraints
+// The caret and tilde requirements are converted to the version ranges,
+// the unions of the constraints (||) cannot be expressed, so they are left out
+func poetryConstraint(constraint string) ([]interfaces.VersionSpecifier, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || constraint == "*" || strings.Contains(constraint, "||") {
+		return nil, nil
+	}
+
+	var versions []interfaces.VersionSpecifier
+	for _, match := range poetryConstraintPattern.FindAllStringSubmatch(constraint, -1) {
+		operator, version := match[1], match[2]
+		if version == "*" {
+			continue
+		}
+		switch operator {
+		case "^", "~":
+			release := releasePattern.FindString(version)
+			if release == "" {
+				return nil, fmt.Errorf("invalid version constraint %q", constraint)
+			}
+			upper := caretUpperBound(release)
+			if operator == "~" {
+				upper = tildeUpperBound(release)
+			}
+			versions = append(versions,
+				interfaces.VersionSpecifier{Operator: ">=", Value: version},
+				interfaces.VersionSpecifier{Operator: "<", Value: upper},
+			)
+		case "", "=":
+			versions = append(versions, interfaces.VersionSpecifier{Operator: "==", Value: version})
+		default:
+			versions = append(versions, interfaces.VersionSpecifier{Operator: operator, Value: version})
+		}
+	}
+	return versions, nil
+}
+
+// caretUpperBound increments the first non zero part of the release
+// e.g. 1.2.3 is <2, 0.2.3 is <0.3 and 0.0.3 is <0.0.4
+func caretUpperBound(release string) string {
+	parts := strings.Split(release, ".")
+	index := len(parts) - 1
+	for i, part := range parts {
+		if strings.Trim(part, "0") != "" {
+			index = i
+			break
+		}
+	}
+	return incrementRelease(parts[:index+1])
+}
+
+// tildeUpperBound increments the minor version, or the major one when only it is given
+// e.g. 1.2.3 is <1.3 and 1 is <2
+func tildeUpperBound(release string) string {
+	parts := strings.Split(release, ".")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return incrementRelease(parts)
+}
+
+func incrementRelease(parts []string) string {
+	last, _ := strconv.Atoi(parts[len(parts)-1])
+	bound := append(append([]string{}, parts[:len(parts)-1]...), strconv.Itoa(last+1))
+	return strings.Join(bound, ".")
+}
+
+// poetryLockFile is the poetry.lock file
+type poetryLockFile struct {
+	Package []poetryPackage `toml:"package"`
+	// Extras are the optional packages of the project keyed by the extra
+	Extras map[string][]string `toml:"extras"`
+}
+
+type poetryPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	// Category is main or dev in the locks before poetry 1.5
+	Category string `toml:"category"`
+	// Groups are recorded since poetry 2.0
+	Groups []string `toml:"groups"`
+	// Optional is set for the packages required only by the extras of the project
+	Optional bool `toml:"optional"`
+	// Dependencies are the constraints of the package dependencies,
+	// the optional dependencies are required only by the extras of the package
+	Dependencies map[string]interface{} `toml:"dependencies"`
+	Extras       map[string][]string    `toml:"extras"`
+	Source       poetryPackageSource    `toml:"source"`
+}
+
+// poetryPackageSource is set for the packages which do not come from PyPI
+type poetryPackageSource struct {
+	// Type is one of legacy (the package index), git, url, file and directory
+	Type              string `toml:"type"`
+	URL               string `toml:"url"`
+	Reference         string `toml:"reference"`
+	ResolvedReference string `toml:"resolved_reference"`
+}
+
+// PoetryLock represents the poetry.lock file
+type PoetryLock struct {
+	sources []string
+}
+
+// NewPoetryLock creates a new instance of the PoetryLock struct
+func NewPoetryLock() *PoetryLock {
+	return &PoetryLock{}
+}
+
+func (p *PoetryLock) GetDepFileType() string {
+	return "python/poetry.lock"
+}
+
+// GetRepository returns the repository querying PyPI and the package indexes
+// the locked packages come from
+func (p *PoetryLock) GetRepository() interfaces.PackageRepository {
+	return newDirectReferenceRepository([]string{DefaultIndexURL}, p.sources)
+}
+
+func (p *PoetryLock) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return evaluateMarkers(dependency.Markers, environment, extras)
+}
+
+// GetDependencies returns all locked packages pinned to the locked version
+// The packages pulled in by the other packages have the path of the packages requiring them
+// when the pyproject.toml is next to the lock
+func (p *PoetryLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var lock poetryLockFile
+	if _, err := toml.Decode(string(data), &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid poetry.lock: %w", fileName, err)
+	}
+
+	var roots []interfaces.Dependency
+	pyproject := filepath.Join(filepath.Dir(fileName), "pyproject.toml")
+	if _, err := os.Stat(pyproject); err == nil {
+		roots, _, err = readPyprojectDependencies(pyproject)
+		if err != nil {
+			return nil, err
+		}
+	}
+	graph := newLockGraph(poetryLockPackages(lock.Package))
+	graph.walk(roots)
+	// the optional packages belong only to the extras of the project pulling them in
+	extras := newLockGraph(poetryLockPackages(lock.Package))
+	extras.walk(poetryExtraRoots(lock.Extras))
+
+	p.sources = nil
+	var dependencies []interfaces.Dependency
+	for _, pkg := range lock.Package {
+		dependency := interfaces.Dependency{
+			Name:     pkg.Name,
+			Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: pkg.Version}},
+			File:     fileName,
+		}
+		if node, ok := graph.node(pkg.Name); ok {
+			dependency.Path = node.Path
+			dependency.Groups = node.Groups
+			dependency.Markers = node.Markers()
+		}
+		switch {
+		case pkg.Optional:
+			dependency.Path, dependency.Groups, dependency.Markers = nil, nil, ""
+			if node, ok := extras.node(pkg.Name); ok {
+				dependency.Path = node.Path
+				dependency.Groups = node.Groups
+				dependency.Markers = node.Markers()
+			}
+		case len(pkg.Groups) > 0:
+			dependency.Groups = pkg.Groups
+		case pkg.Category != "":
+			dependency.Groups = []string{pkg.Category}
+		}
+
+		switch pkg.Source.Type {
+		case "legacy":
+			dependency.Index = pkg.Source.URL
+			if !containsString(p.sources, pkg.Source.URL) {
+				p.sources = append(p.sources, pkg.Source.URL)
+			}
+		case "git":
+			reference := pkg.Source.ResolvedReference
+			if reference == "" {
+				reference = pkg.Source.Reference
+			}
+			dependency.URL = "git+" + pkg.Source.URL
+			if reference != "" {
+				dependency.URL += "@" + reference
+			}
+		case "url":
+			dependency.URL = pkg.Source.URL
+		case "file", "directory":
+			dependency.URL = pkg.Source.URL
+			if !filepath.IsAbs(dependency.URL) {
+				dependency.URL = filepath.Join(filepath.Dir(fileName), dependency.URL)
+			}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// poetryLockPackages converts the locked packages to the nodes of the dependency graph
+// the optional dependencies are required by the extras listing them,
+// e.g. socks = ["PySocks (>=1.5.6,!=1.5.7)"]
+func poetryLockPackages(packages []poetryPackage) []lockPackage {
+	var converted []lockPackage
+	for _, pkg := range packages {
+		node := lockPackage{name: pkg.Name, optional: map[string][]lockEdge{}}
+		for _, name := range sortedDependencyNames(pkg.Dependencies) {
+			optional, extras, markers := lockRequirement(pkg.Dependencies[name])
+			edge := lockEdge{name: name, extras: extras, markers: markers}
+			if !optional {
+				node.dependencies = append(node.dependencies, edge)
+				continue
+			}
+			for extra, requirements := range pkg.Extras {
+				for _, requirement := range requirements {
+					fields := strings.FieldsFunc(requirement, func(r rune) bool {
+						return strings.ContainsRune(" ([;<>=!~", r)
+					})
+					if len(fields) > 0 && interfaces.NormalizeName(fields[0]) == interfaces.NormalizeName(name) {
+						node.optional[interfaces.NormalizeName(extra)] = append(node.optional[interfaces.NormalizeName(extra)], edge)
+					}
+				}
+			}
+		}
+		converted = append(converted, node)
+	}
+	return converted
+}
+
+// poetryExtraRoots returns the packages of the extras of the project in the group of the extra,
+// they are required only when the extra is requested
+func poetryExtraRoots(extras map[string][]string) []interfaces.Dependency {
+	var roots []interfaces.Dependency
+	for _, extra := range sortedKeys(extras) {
+		for _, requirement := range extras[extra] {
+			root, err := parseRequirement(requirement)
+			if err != nil {
+				continue
+			}
+			root.Groups = []string{extra}
+			root.Markers = fmt.Sprintf("extra == %q", extra)
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// lockRequirement returns whether the dependency of the locked package is optional,
+// the extras it requires and the markers it applies to
+func lockRequirement(value interface{}) (bool, []string, string) {
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		return false, nil, ""
+	}
+	optional, _ := table["optional"].(bool)
+	markers, _ := table["markers"].(string)
+	var extras []string
+	if values, ok := table["extras"].([]interface{}); ok {
+		for _, extra := range values {
+			if extra, ok := extra.(string); ok {
+				extras = append(extras, extra)
+			}
+		}
+	}
+	return optional, extras, markers
+}
+
+func sortedDependencyNames(dependencies map[string]interface{}) []string {
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
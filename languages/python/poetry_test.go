@@ -0,0 +1,253 @@
+package python
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestPoetryConstraint(t *testing.T) {
+	constraints := map[string]string{
+		"^2.31":             ">=2.31,<3",
+		"^0.2.3":            ">=0.2.3,<0.3",
+		"^0.0.3":            ">=0.0.3,<0.0.4",
+		"^0":                ">=0,<1",
+		"~1.4":              ">=1.4,<1.5",
+		"~1.4.2":            ">=1.4.2,<1.5",
+		"~1":                ">=1,<2",
+		"~=1.4":             "~=1.4",
+		"1.2.3":             "==1.2.3",
+		"1.2.*":             "==1.2.*",
+		">=1.0,<2.0":        ">=1.0,<2.0",
+		">=1.0 <2.0":        ">=1.0,<2.0",
+		"!=1.5.7":           "!=1.5.7",
+		"*":                 "",
+		"":                  "",
+		"<1.5.7 || >1.5.7":  "",
+		"^1.0.0b1":          ">=1.0.0b1,<2",
+		">= 2017.4.17":      ">=2017.4.17",
+		"==2.0.0":           "==2.0.0",
+		"=2.0.0":            "==2.0.0",
+		">1.0, !=1.2, <2.0": ">1.0,!=1.2,<2.0",
+	}
+	for constraint, expected := range constraints {
+		versions, err := poetryConstraint(constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var specifiers []string
+		for _, version := range versions {
+			specifiers = append(specifiers, version.Operator+version.Value)
+		}
+		if got := strings.Join(specifiers, ","); got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, constraint, got)
+		}
+	}
+}
+
+func TestPyprojectToml(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "pyproject.toml")
+	data := `[project]
+name = "service"
+dependencies = ["requests>=2.31", "click; python_version >= '3.8'"]
+
+[project.optional-dependencies]
+socks = ["PySocks>=1.5.6"]
+dev = ["black"]
+
+[dependency-groups]
+test = ["pytest>=7", {include-group = "coverage"}]
+coverage = ["coverage[toml]"]
+
+[tool.poetry.dependencies]
+requests = {version = "^2.31", source = "private"}
+rich = {version = "^13.0", python = ">=3.8,<4.0", markers = "sys_platform != 'win32'"}
+
+[tool.poetry.dev-dependencies]
+mypy = "^1.7"
+
+[[tool.poetry.source]]
+name = "private"
+url = "https://pypi.example.com/simple"
+priority = "supplemental"
+`
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pyproject := NewPyprojectToml()
+	dependencies, err := pyproject.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []interfaces.Dependency
+	for dependency := range dependencies {
+		got = append(got, dependency)
+	}
+
+	expected := []interfaces.Dependency{
+		{Name: "requests", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "2.31"}}, File: fileName, Groups: []string{"main"}},
+		{Name: "click", Markers: "python_version >= '3.8'", File: fileName, Groups: []string{"main"}},
+		{Name: "black", File: fileName, Groups: []string{"dev"}},
+		{Name: "PySocks", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "1.5.6"}}, File: fileName, Groups: []string{"socks"}},
+		{Name: "coverage", Extras: []string{"toml"}, File: fileName, Groups: []string{"coverage", "test"}},
+		{Name: "pytest", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "7"}}, File: fileName, Groups: []string{"test"}},
+		{
+			Name:     "rich",
+			Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "13.0"}, {Operator: "<", Value: "14"}},
+			Markers:  `(sys_platform != 'win32') and (python_version >= "3.8") and (python_version < "4.0")`,
+			File:     fileName,
+			Groups:   []string{"main"},
+		},
+		{Name: "mypy", Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "1.7"}, {Operator: "<", Value: "2"}}, File: fileName, Groups: []string{"dev"}},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{DefaultIndexURL, "https://pypi.example.com/simple"}, pyproject.sources.urls()); diff != "" {
+		t.Errorf("Index urls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPoetryLock(t *testing.T) {
+	poetryLock := NewPoetryLock()
+	fileName := filepath.Join("testdata", "poetry", "poetry.lock")
+	dependencies, err := poetryLock.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type locked struct {
+		name, version, groups, path, index, url string
+	}
+	var got []locked
+	for dependency := range dependencies {
+		got = append(got, locked{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			groups:  strings.Join(dependency.Groups, ","),
+			path:    strings.Join(dependency.Path, " > "),
+			index:   dependency.Index,
+			url:     dependency.URL,
+		})
+	}
+
+	expected := []locked{
+		{name: "certifi", version: "2023.7.22", groups: "main,dev", path: "requests"},
+		{name: "colorama", version: "0.4.6", groups: "dev", path: "pytest"},
+		{name: "internal-lib", version: "1.4.2", groups: "main", index: "https://pypi.example.com/simple"},
+		{name: "pysocks", version: "1.7.1", groups: "main,dev", path: "requests"},
+		{name: "pytest", version: "7.4.3", groups: "dev"},
+		{name: "requests", version: "2.31.0", groups: "main,dev"},
+		{name: "toolbelt", version: "0.3.0", groups: "main", url: "git+https://github.com/example/toolbelt.git@4f1c2d3e5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d"},
+		{name: "unused", version: "0.1.0"},
+		{name: "urllib3", version: "2.0.7", groups: "main,dev", path: "requests"},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"https://pypi.example.com/simple"}, poetryLock.sources); diff != "" {
+		t.Errorf("Sources mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPoetryLockCategories(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "poetry.lock")
+	data := `[[package]]
+name = "requests"
+version = "2.28.0"
+category = "main"
+optional = false
+
+[[package]]
+name = "pytest"
+version = "7.1.0"
+category = "dev"
+optional = false
+
+[[package]]
+name = "black"
+version = "23.1.0"
+groups = ["dev", "lint"]
+optional = false
+`
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dependencies, err := NewPoetryLock().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dependency := range dependencies {
+		got = append(got, dependency.Name+": "+strings.Join(dependency.Groups, ","))
+	}
+	if diff := cmp.Diff([]string{"requests: main", "pytest: dev", "black: dev,lint"}, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPoetryLockOptional(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "poetry.lock")
+	data := `[[package]]
+name = "requests"
+version = "2.28.0"
+category = "main"
+optional = false
+
+[[package]]
+name = "mysqlclient"
+version = "2.1.1"
+category = "main"
+optional = true
+
+[[package]]
+name = "psycopg"
+version = "3.1.8"
+groups = ["main"]
+optional = true
+
+[package.dependencies]
+psycopg-binary = {version = "3.1.8", optional = true, markers = "implementation_name != \"pypy\" and extra == \"binary\""}
+
+[package.extras]
+binary = ["psycopg-binary (==3.1.8)"]
+
+[[package]]
+name = "psycopg-binary"
+version = "3.1.8"
+groups = ["main"]
+optional = true
+
+[extras]
+mysql = ["mysqlclient"]
+pgsql = ["psycopg[binary] (>=3.1,<4.0)"]
+`
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dependencies, err := NewPoetryLock().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for dependency := range dependencies {
+		got = append(got, dependency.Name+": "+strings.Join(dependency.Groups, ",")+" "+strings.Join(dependency.Path, ">")+" "+dependency.Markers)
+	}
+	expected := []string{
+		"requests: main  ",
+		`mysqlclient: mysql  extra == "mysql"`,
+		`psycopg: pgsql  extra == "pgsql"`,
+		`psycopg-binary: pgsql psycopg (extra == "pgsql") and (implementation_name != "pypy" and extra == "binary")`,
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
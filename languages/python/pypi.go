@@ -1,11 +1,14 @@
 // Used to get packages from PyPI (pypi.org)
 // Uses the PyPI JSON API https://warehouse.pypa.io/api-reference/json.html
 // using the url https://pypi.org/pypi/<package_name>/json
+// or https://pypi.org/pypi/<package_name>/<version>/json for pinned versions
 // The PyPI represent an implementation of interface  packagerepository
 package python
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -17,11 +20,22 @@ const PyPIURL = "https://pypi.org/pypi/<package_name>/json"
 type PyPI struct {
 	name string
 	url  string
+	// index is the index url recorded in the package meta
+	index string
+	// auth are the credentials sent to the index, nil for the public indexes
+	auth *indexAuth
 }
 
 type pypiResponse struct {
 	// PyPI general package information
 	Info pypiInfo `json:"info"`
+	// Releases maps the versions to their files, the release specific
+	// response does not contain it
+	Releases map[string][]pypiFile `json:"releases,omitempty"`
+}
+
+type pypiFile struct {
+	Yanked bool `json:"yanked"`
 }
 
 // pypiInfo contains the general package information
@@ -35,6 +49,13 @@ type pypiInfo struct {
 	Description string     `json:"description"`
 	Homepage    string     `json:"home_page,omitempty"`
 	ProjectURL  projectURL `json:"project_urls"`
+	// Free text license field, it might contain the whole license text
+	License string `json:"license,omitempty"`
+	// PEP 639 SPDX license expression and license files
+	LicenseExpression string   `json:"license_expression,omitempty"`
+	LicenseFiles      []string `json:"license_files,omitempty"`
+	// PEP 508 requirements of the package
+	RequiresDist []string `json:"requires_dist,omitempty"`
 }
 
 type projectURL struct {
@@ -46,8 +67,9 @@ type projectURL struct {
 // Nothing crazy, just struct init, no call to the API
 func NewPyPI(name string, pypiURL string) interfaces.PackageRepository {
 	return &PyPI{
-		name: "PyPI",
-		url:  pypiURL,
+		name:  "PyPI",
+		url:   pypiURL,
+		index: strings.TrimSuffix(pypiURL, "/<package_name>/json"),
 	}
 }
 
@@ -55,21 +77,85 @@ func (p *PyPI) GetRepositoryName() string {
 	return p.name
 }
 
-func (p *PyPI) GetPackageInfo(packageName string) (*interfaces.PackageMeta, error) {
-	url := strings.Replace(p.url, "<package_name>", packageName, 1)
-	resp, err := http.Get(url)
+func (p *PyPI) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	response, err := p.fetch(ctx, dependency, p.packageURL(dependency))
 	if err != nil {
 		return nil, err
 	}
+
+	// the latest release does not have to satisfy the version specifiers,
+	// in such a case the info of the highest matching release is downloaded
+	if dependency.PinnedVersion() == "" && !matchesSpecifiers(response.Info.Version, dependency.Versions) {
+		version := bestVersion(availableVersions(response.Releases), dependency.Versions)
+		if version == "" {
+			err := fmt.Errorf("no release matches the version specifiers")
+			return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+		}
+		response, err = p.fetch(ctx, dependency, p.releaseURL(dependency.Name, version))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	meta := convertPyPIResponseToPackageMeta(*response)
+	meta.Index = p.index
+	return meta, nil
+}
+
+// fetch downloads and decodes the JSON API response
+func (p *PyPI) fetch(ctx context.Context, dependency interfaces.Dependency, url string) (*pypiResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.auth != nil {
+		p.auth.apply(req)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("unexpected response status %s", resp.Status)
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
 	var response pypiResponse
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
-		return nil, err
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+	}
+	return &response, nil
+}
+
+// packageURL returns the JSON API url for the dependency
+// The release specific url is used when the dependency is pinned to a version,
+// so the license of that release is returned and not the one of the latest release
+func (p *PyPI) packageURL(dependency interfaces.Dependency) string {
+	if version := dependency.PinnedVersion(); version != "" {
+		return p.releaseURL(dependency.Name, version)
 	}
+	return strings.Replace(p.url, "<package_name>", dependency.Name, 1)
+}
 
-	return convertPyPIResponseToPackageMeta(response), nil
+func (p *PyPI) releaseURL(name string, version string) string {
+	return strings.Replace(p.url, "<package_name>", name+"/"+version, 1)
+}
+
+// availableVersions returns the versions that have at least one file which is not yanked
+func availableVersions(releases map[string][]pypiFile) []string {
+	var versions []string
+	for version, files := range releases {
+		for _, file := range files {
+			if !file.Yanked {
+				versions = append(versions, version)
+				break
+			}
+		}
+	}
+	return versions
 }
 
 // convertPyPIResponseToPackageMeta converts the PyPI response to the PackageMeta struct
@@ -80,25 +166,43 @@ func convertPyPIResponseToPackageMeta(response pypiResponse) *interfaces.Package
 	meta.Author = response.Info.Author
 	meta.Name = response.Info.Name
 	meta.Version = response.Info.Version
-	meta.License = extractLicenseFromClassifiers(response.Info.Classifiers)
+	resolveLicense(meta, response.Info.LicenseExpression, response.Info.Classifiers, response.Info.License)
+	meta.LicenseFiles = response.Info.LicenseFiles
 	meta.Description = response.Info.Description
 	meta.Homepage = response.Info.ProjectURL.Homepage
 	meta.Repository = response.Info.ProjectURL.Source
 	meta.Language = "python"
+	// the requirements that cannot be parsed are skipped, they cannot be resolved anyway
+	for _, requirement := range response.Info.RequiresDist {
+		if dependency, err := parseRequirement(requirement); err == nil {
+			meta.Requires = append(meta.Requires, dependency)
+		}
+	}
 
 	return meta
 }
 
-// extractLicenseFromClassifiers extracts the license from the classifier list
-// walks over classifiers and return the license, which is the last item in the item
-// starting with 'license ::'
-// if the license cannot be found return empty string
-func extractLicenseFromClassifiers(classifiers []string) string {
+// extractLicensesFromClassifiers extracts the licenses from the classifier list
+// walks over classifiers and collects the licenses, which are the last item in the items
+// starting with 'license ::', the dual licensed packages list several of them
+// the generic classifiers without the license name (e.g. 'License :: OSI Approved') are skipped
+// if no license can be found return empty slice
+func extractLicensesFromClassifiers(classifiers []string) []string {
+	licenses := []string{}
 	for _, classifier := range classifiers {
 		if strings.HasPrefix(strings.ToLower(classifier), "license ::") {
 			tempLicense := strings.Split(classifier, "::")
-			return strings.TrimSpace(tempLicense[len(tempLicense)-1])
+			if len(tempLicense) == 2 && genericLicenseClassifiers[strings.TrimSpace(tempLicense[1])] {
+				continue
+			}
+			licenses = append(licenses, strings.TrimSpace(tempLicense[len(tempLicense)-1]))
 		}
 	}
-	return ""
+	return licenses
+}
+
+// genericLicenseClassifiers do not name any license, they only group the licenses
+var genericLicenseClassifiers = map[string]bool{
+	"OSI Approved":  true,
+	"DFSG approved": true,
 }
@@ -1,54 +1,95 @@
 package python
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
 )
 
-// TestExtractLicenseFromClassifiers tests the extractLicenseFromClassifiers function
+// TestExtractLicensesFromClassifiers tests the extractLicensesFromClassifiers function
 // It does not cover every possible license, but it should be enough to cover the
 // most common ones, it also does not cover the negative scenarios and all possible
 // butchering of the license names
 // Test private functions
-func TestExtractLicenseFromClassifiers(t *testing.T) {
-	classifiers := map[string][]string{
-		"MIT License": {
-			"Development Status :: 5 - Production/Stable",
-			"Intended Audience :: Developers",
-			"License :: OSI Approved :: MIT License",
-			"Programming Language :: Python :: 3.6",
-			"Programming Language :: Python :: 3.7",
+func TestExtractLicensesFromClassifiers(t *testing.T) {
+	tests := []struct {
+		expected    []string
+		classifiers []string
+	}{
+		{
+			expected: []string{"MIT License"},
+			classifiers: []string{
+				"Development Status :: 5 - Production/Stable",
+				"Intended Audience :: Developers",
+				"License :: OSI Approved :: MIT License",
+				"Programming Language :: Python :: 3.6",
+				"Programming Language :: Python :: 3.7",
+			},
 		},
-		"BSD License": {
-			"Development Status :: 5 - Production/Stable",
-			"Intended Audience :: Developers",
-			"Intended Audience :: Information Technology",
-			"License :: OSI Approved :: BSD License",
-			"Operating System :: OS Independent",
-			"Programming Language :: C",
-			"Programming Language :: Cython",
-			"Programming Language :: Python :: 2",
-			"Programming Language :: Python :: 2.7",
-			"Programming Language :: Python :: 3",
-			"Programming Language :: Python :: 3.10",
-			"Programming Language :: Python :: 3.11",
-			"Programming Language :: Python :: 3.12",
-			"Programming Language :: Python :: 3.6",
-			"Programming Language :: Python :: 3.7",
-			"Programming Language :: Python :: 3.8",
-			"Programming Language :: Python :: 3.9",
-			"Topic :: Software Development :: Libraries :: Python Modules",
-			"Topic :: Text Processing :: Markup :: HTML",
-			"Topic :: Text Processing :: Markup :: XML",
+		{
+			expected: []string{"BSD License"},
+			classifiers: []string{
+				"Development Status :: 5 - Production/Stable",
+				"Intended Audience :: Developers",
+				"Intended Audience :: Information Technology",
+				"License :: OSI Approved :: BSD License",
+				"Operating System :: OS Independent",
+				"Programming Language :: C",
+				"Programming Language :: Cython",
+				"Programming Language :: Python :: 2",
+				"Programming Language :: Python :: 2.7",
+				"Programming Language :: Python :: 3",
+				"Programming Language :: Python :: 3.10",
+				"Programming Language :: Python :: 3.11",
+				"Programming Language :: Python :: 3.12",
+				"Programming Language :: Python :: 3.6",
+				"Programming Language :: Python :: 3.7",
+				"Programming Language :: Python :: 3.8",
+				"Programming Language :: Python :: 3.9",
+				"Topic :: Software Development :: Libraries :: Python Modules",
+				"Topic :: Text Processing :: Markup :: HTML",
+				"Topic :: Text Processing :: Markup :: XML",
+			},
+		},
+		{
+			// dual licensed package, e.g. cryptography
+			expected: []string{"Apache Software License", "BSD License"},
+			classifiers: []string{
+				"Development Status :: 5 - Production/Stable",
+				"License :: OSI Approved",
+				"License :: OSI Approved :: Apache Software License",
+				"License :: OSI Approved :: BSD License",
+				"Natural Language :: English",
+			},
+		},
+		{
+			expected: []string{"Python Software Foundation License", "Zope Public License", "Public Domain"},
+			classifiers: []string{
+				"License :: OSI Approved :: Python Software Foundation License",
+				"License :: OSI Approved :: Zope Public License",
+				"License :: Public Domain",
+			},
+		},
+		{
+			expected: []string{},
+			classifiers: []string{
+				"Development Status :: 5 - Production/Stable",
+				"Programming Language :: Python :: 3",
+			},
 		},
 	}
-	for expectedLicense, classifiers := range classifiers {
-		license := extractLicenseFromClassifiers(classifiers)
-		if license != expectedLicense {
-			t.Errorf("Expected %s, got %s", expectedLicense, license)
+	for _, test := range tests {
+		licenses := extractLicensesFromClassifiers(test.classifiers)
+		if diff := cmp.Diff(test.expected, licenses); diff != "" {
+			t.Errorf("Licenses mismatch (-want +got):\n%s", diff)
 		}
 	}
 }
@@ -69,7 +110,7 @@ func TestGetPackageInfo(t *testing.T) {
 	defer server.Close()
 
 	pypi := NewPyPI("pypi", server.URL)
-	meta, err := pypi.GetPackageInfo("beautifulsoup4")
+	meta, err := pypi.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "beautifulsoup4"})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -87,6 +128,12 @@ func TestGetPackageInfo(t *testing.T) {
 	if meta.License != "MIT License" {
 		t.Errorf("Expected MIT License, got %s", meta.License)
 	}
+	if meta.LicenseSPDX != "MIT" {
+		t.Errorf("Expected MIT, got %s", meta.LicenseSPDX)
+	}
+	if meta.LicenseSource != interfaces.LicenseSourceClassifier {
+		t.Errorf("Expected classifier license source, got %s", meta.LicenseSource)
+	}
 	if !strings.HasPrefix(meta.Description, "Beautiful Soup is a library that") {
 		t.Errorf("Expected 'Beautiful Soup is a library that', got %s", meta.Description)
 	}
@@ -99,5 +146,119 @@ func TestGetPackageInfo(t *testing.T) {
 	if meta.Language != "python" {
 		t.Errorf("Expected python, got %s", meta.Language)
 	}
+	requires := []interfaces.Dependency{
+		{Name: "soupsieve", Versions: []interfaces.VersionSpecifier{{Operator: ">", Value: "1.2"}}},
+		{Name: "html5lib", Markers: "extra == 'html5lib'"},
+		{Name: "lxml", Markers: "extra == 'lxml'"},
+	}
+	if diff := cmp.Diff(requires, meta.Requires); diff != "" {
+		t.Errorf("Requires mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestGetPackageInfoPinnedVersion tests that the release specific endpoint
+// is used when the dependency is pinned and the latest one otherwise
+func TestGetPackageInfoPinnedVersion(t *testing.T) {
+	data, err := os.ReadFile("testdata/pypi_response.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	pypi := NewPyPI("pypi", server.URL+"/pypi/<package_name>/json")
+
+	dependencies := map[string]interfaces.Dependency{
+		"/pypi/beautifulsoup4/4.12.2/json": {
+			Name:     "beautifulsoup4",
+			Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "4.12.2"}},
+		},
+		"/pypi/beautifulsoup4/json": {
+			Name:     "beautifulsoup4",
+			Versions: []interfaces.VersionSpecifier{{Operator: ">=", Value: "4.12.2"}},
+		},
+	}
+	for expectedPath, dependency := range dependencies {
+		if _, err := pypi.GetPackageInfo(context.Background(), dependency); err != nil {
+			t.Fatal(err)
+		}
+		if requestedPath != expectedPath {
+			t.Errorf("Expected %s, got %s", expectedPath, requestedPath)
+		}
+	}
+}
 
+// TestGetPackageInfoVersionRange tests that the highest release matching
+// the version specifiers is used when the latest release does not match them
+func TestGetPackageInfoVersionRange(t *testing.T) {
+	data, err := os.ReadFile("testdata/pypi_response.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requestedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	pypi := NewPyPI("pypi", server.URL+"/pypi/<package_name>/json")
+
+	_, err = pypi.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "beautifulsoup4",
+		Versions: []interfaces.VersionSpecifier{{Operator: "~=", Value: "4.11.0"}, {Operator: "!=", Value: "4.11.2"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/pypi/beautifulsoup4/json", "/pypi/beautifulsoup4/4.11.1/json"}
+	if diff := cmp.Diff(expected, requestedPaths); diff != "" {
+		t.Errorf("Requested paths mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = pypi.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "beautifulsoup4",
+		Versions: []interfaces.VersionSpecifier{{Operator: ">", Value: "5"}},
+	})
+	var pkgErr *interfaces.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch package error, got %v", err)
+	}
+}
+
+// TestGetPackageInfoMultipleClassifiers tests that all license classifiers
+// of the dual licensed package are reported as a disjunction
+func TestGetPackageInfoMultipleClassifiers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"info": {"name": "cryptography", "version": "41.0.3", "classifiers": [
+			"License :: OSI Approved :: Apache Software License",
+			"License :: OSI Approved :: BSD License",
+			"Natural Language :: English"
+		]}}`))
+	}))
+	defer server.Close()
+
+	pypi := NewPyPI("pypi", server.URL)
+	meta, err := pypi.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "cryptography"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"Apache Software License", "BSD License"}, meta.Licenses); diff != "" {
+		t.Errorf("Licenses mismatch (-want +got):\n%s", diff)
+	}
+	if meta.License != "Apache Software License OR BSD License" {
+		t.Errorf("Expected 'Apache Software License OR BSD License', got %s", meta.License)
+	}
+	// BSD License cannot be mapped to a single SPDX identifier
+	if meta.LicenseSPDX != "" {
+		t.Errorf("Expected no SPDX expression, got %s", meta.LicenseSPDX)
+	}
 }
@@ -0,0 +1,316 @@
+// Reads the package metadata from the [project] table of pyproject.toml
+// https://packaging.python.org/en/latest/specifications/pyproject-toml/
+// and the dependencies of the project, see PyprojectToml
+package python
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// mainGroup is the group of the dependencies required to run the project
+const mainGroup = "main"
+
+type pyprojectFile struct {
+	Project pyprojectProject `toml:"project"`
+	// DependencyGroups are the PEP 735 dependency groups, the items are
+	// either the requirements or the {include-group = "name"} tables
+	DependencyGroups map[string][]interface{} `toml:"dependency-groups"`
+	Tool             struct {
+		Poetry poetryTool `toml:"poetry"`
+		PDM    pdmTool    `toml:"pdm"`
+	} `toml:"tool"`
+}
+
+type pyprojectProject struct {
+	Name        string `toml:"name"`
+	Version     string `toml:"version"`
+	Description string `toml:"description"`
+	// License is either the PEP 639 license expression
+	// or the table with the license text or file
+	License interface{} `toml:"license"`
+	// LicenseFiles are the PEP 639 globs of the license files
+	LicenseFiles         []string            `toml:"license-files"`
+	Authors              []pyprojectAuthor   `toml:"authors"`
+	Classifiers          []string            `toml:"classifiers"`
+	Dependencies         []string            `toml:"dependencies"`
+	OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	URLs                 map[string]string   `toml:"urls"`
+}
+
+type pyprojectAuthor struct {
+	Name  string `toml:"name"`
+	Email string `toml:"email"`
+}
+
+// parsePyproject reads the [project] table to the same structure
+// the PyPI JSON API returns
+func parsePyproject(data []byte) (pypiInfo, error) {
+	var file pyprojectFile
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return pypiInfo{}, fmt.Errorf("invalid pyproject.toml: %w", err)
+	}
+	project := file.Project
+	if project.Name == "" {
+		return pypiInfo{}, fmt.Errorf("invalid pyproject.toml: missing project name")
+	}
+
+	info := pypiInfo{
+		Name:         project.Name,
+		Version:      project.Version,
+		Classifiers:  project.Classifiers,
+		Description:  project.Description,
+		LicenseFiles: project.LicenseFiles,
+		RequiresDist: project.Dependencies,
+	}
+
+	switch license := project.License.(type) {
+	case string:
+		info.LicenseExpression = license
+	case map[string]interface{}:
+		// the license file is found among the license files of the archive
+		if text, ok := license["text"].(string); ok {
+			info.License = text
+		}
+	}
+
+	if len(project.Authors) > 0 {
+		info.Author = project.Authors[0].Name
+		if info.Author == "" {
+			info.Author = project.Authors[0].Email
+		}
+	}
+
+	for label, url := range project.URLs {
+		switch strings.ToLower(label) {
+		case "homepage":
+			info.ProjectURL.Homepage = url
+		case "source", "source code", "repository":
+			info.ProjectURL.Source = url
+		}
+	}
+
+	// the optional dependencies are required only with their extra
+	for _, extra := range sortedKeys(project.OptionalDependencies) {
+		for _, requirement := range project.OptionalDependencies[extra] {
+			info.RequiresDist = append(info.RequiresDist, withExtraMarker(requirement, extra))
+		}
+	}
+	return info, nil
+}
+
+// withExtraMarker adds the extra marker to the requirement string
+func withExtraMarker(requirement string, extra string) string {
+	marker := fmt.Sprintf("extra == %q", extra)
+	name, markers, found := strings.Cut(requirement, ";")
+	if !found || strings.TrimSpace(markers) == "" {
+		return strings.TrimSpace(name) + "; " + marker
+	}
+	return strings.TrimSpace(name) + "; (" + strings.TrimSpace(markers) + ") and " + marker
+}
+
+// PyprojectToml represents the pyproject.toml of the project
+// The dependencies are read from the PEP 621 [project] table, the PEP 735 [dependency-groups],
+// the [tool.pdm.dev-dependencies] and the [tool.poetry] tables, the dependencies are grouped by the table they come from
+// (main, the name of the extra or the dependency group, dev for the poetry dev-dependencies)
+type PyprojectToml struct {
+	sources poetrySources
+}
+
+// NewPyprojectToml creates a new instance of the PyprojectToml struct
+func NewPyprojectToml() *PyprojectToml {
+	return &PyprojectToml{}
+}
+
+func (p *PyprojectToml) GetDepFileType() string {
+	return "python/pyproject.toml"
+}
+
+// GetRepository returns the repository querying the poetry sources, PyPI by default
+func (p *PyprojectToml) GetRepository() interfaces.PackageRepository {
+	return newDirectReferenceRepository(p.sources.urls(), p.sources.explicitURLs())
+}
+
+func (p *PyprojectToml) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	dependencies, file, err := readPyprojectDependencies(fileName)
+	if err != nil {
+		return nil, err
+	}
+	p.sources = file.Tool.Poetry.Source
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+func (p *PyprojectToml) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return evaluateMarkers(dependency.Markers, environment, extras)
+}
+
+// readPyprojectDependencies reads the dependencies of the pyproject.toml in the file order,
+// the dependency listed in several groups is returned once with all its groups
+// The decoded file is returned as well, so the tool specific settings can be read
+func readPyprojectDependencies(fileName string) ([]interfaces.Dependency, pyprojectFile, error) {
+	var file pyprojectFile
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, file, err
+	}
+	metadata, err := toml.Decode(string(data), &file)
+	if err != nil {
+		return nil, file, fmt.Errorf("%s: invalid pyproject.toml: %w", fileName, err)
+	}
+	sources := poetrySources(file.Tool.Poetry.Source)
+
+	var dependencies []interfaces.Dependency
+	positions := map[string]int{}
+	add := func(dependency interfaces.Dependency, group string) {
+		dependency.File = fileName
+		key := interfaces.NormalizeName(dependency.Name)
+		if position, ok := positions[key]; ok {
+			if !containsString(dependencies[position].Groups, group) {
+				dependencies[position].Groups = append(dependencies[position].Groups, group)
+			}
+			return
+		}
+		dependency.Groups = []string{group}
+		positions[key] = len(dependencies)
+		dependencies = append(dependencies, dependency)
+	}
+	addRequirements := func(requirements []string, group string) error {
+		for _, requirement := range requirements {
+			dependency, err := parseRequirement(requirement)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fileName, err)
+			}
+			add(dependency, group)
+		}
+		return nil
+	}
+
+	if err := addRequirements(file.Project.Dependencies, mainGroup); err != nil {
+		return nil, file, err
+	}
+	for _, extra := range sortedKeys(file.Project.OptionalDependencies) {
+		if err := addRequirements(file.Project.OptionalDependencies[extra], extra); err != nil {
+			return nil, file, err
+		}
+	}
+	groups := make([]string, 0, len(file.DependencyGroups))
+	for group := range file.DependencyGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		requirements, err := dependencyGroupRequirements(file.DependencyGroups, group, nil)
+		if err != nil {
+			return nil, file, fmt.Errorf("%s: %w", fileName, err)
+		}
+		if err := addRequirements(requirements, group); err != nil {
+			return nil, file, err
+		}
+	}
+
+	for _, group := range sortedKeys(file.Tool.PDM.DevDependencies) {
+		if err := addRequirements(file.Tool.PDM.DevDependencies[group], group); err != nil {
+			return nil, file, err
+		}
+	}
+
+	// the poetry tables are read in the file order
+	for _, key := range metadata.Keys() {
+		var group string
+		switch {
+		case len(key) == 4 && key[0] == "tool" && key[1] == "poetry" && key[2] == "dependencies":
+			group = mainGroup
+		case len(key) == 4 && key[0] == "tool" && key[1] == "poetry" && key[2] == "dev-dependencies":
+			group = "dev"
+		case len(key) == 6 && key[0] == "tool" && key[1] == "poetry" && key[2] == "group" && key[4] == "dependencies":
+			group = key[3]
+		default:
+			continue
+		}
+		name := key[len(key)-1]
+		// python is the constraint of the interpreter
+		if strings.ToLower(name) == "python" {
+			continue
+		}
+		var value interface{}
+		switch group {
+		case mainGroup:
+			value = file.Tool.Poetry.Dependencies[name]
+		case "dev":
+			value = file.Tool.Poetry.DevDependencies[name]
+		}
+		if key[2] == "group" {
+			value = file.Tool.Poetry.Group[group].Dependencies[name]
+		}
+		dependency, err := poetryDependency(name, value, sources)
+		if err != nil {
+			return nil, file, fmt.Errorf("%s: %w", fileName, err)
+		}
+		add(dependency, group)
+	}
+	return dependencies, file, nil
+}
+
+// dependencyGroupRequirements returns the requirements of the PEP 735 dependency group
+// including the requirements of the included groups
+func dependencyGroupRequirements(groups map[string][]interface{}, group string, including []string) ([]string, error) {
+	for _, name := range including {
+		if name == group {
+			return nil, fmt.Errorf("dependency group include cycle: %s -> %s", strings.Join(including, " -> "), group)
+		}
+	}
+	items, ok := groups[group]
+	if !ok {
+		return nil, fmt.Errorf("unknown dependency group %s", group)
+	}
+	var requirements []string
+	for _, item := range items {
+		switch item := item.(type) {
+		case string:
+			requirements = append(requirements, item)
+		case map[string]interface{}:
+			included, ok := item["include-group"].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid item of dependency group %s", group)
+			}
+			nested, err := dependencyGroupRequirements(groups, included, append(including, group))
+			if err != nil {
+				return nil, err
+			}
+			requirements = append(requirements, nested...)
+		default:
+			return nil, fmt.Errorf("invalid item of dependency group %s", group)
+		}
+	}
+	return requirements, nil
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
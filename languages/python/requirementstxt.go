@@ -2,7 +2,11 @@ package python
 
 import (
 	"context"
-	"io/ioutil"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
@@ -11,19 +15,24 @@ import (
 )
 
 type Requirement struct {
-	Line []Line `@@*`
+	Line []Line `( @@ | EOL )*`
 }
 
 type Line struct {
+	// Pos is the position of the line in the file, it is set by the parser
+	Pos lexer.Position
+
 	Package  *Package `  @@`
 	Command  *Command `| @@`
 	Download *string  `| @Download`
+	// Path is the local path of the distribution archive
+	Path *string `| @Path`
 }
 
 type Command struct {
-	LongName  *string `   "--"  @Ident`
-	ShortName *string `| "-" @Ident`
-	Option    *string `@Ident`
+	LongName  *string `(   "--"  @Ident`
+	ShortName *string `  | "-" @Ident )`
+	Option    *string `"="? @( Download | Path | Ident )?`
 }
 
 type Package struct {
@@ -47,19 +56,24 @@ type Environ struct {
 
 type RequirementsTxt struct {
 	parser *participle.Parser[Requirement]
+	// indexes are read from the last requirements file
+	indexes packageIndexes
 }
 
 func newRequirementsTxtParser() *participle.Parser[Requirement] {
 	requirementLexer := lexer.MustSimple([]lexer.SimpleRule{
 		{`Comment`, `#.*`},
 		{`Command`, `--|-`},
-		{`Download`, `((([A-Za-z]{3,9}:(?:\/\/)?)(?:[-;:&=\+\$,\w]+@)?[A-Za-z0-9.-]+|(?:www.|[-;:&=\+\$,\w]+@)[A-Za-z0-9.-]+)((?:\/[\+~%\/.\w-_]*)?\??(?:[-\+=&%@.\w_]*)#?(?:[.\!\/\\w]*))?)`},
+		{`Download`, `((([A-Za-z][A-Za-z+]{2,11}:(?:\/\/)?)(?:[-;:&=\+\$,\w]+@)?[A-Za-z0-9.-]*|(?:www.|[-;:&=\+\$,\w]+@)[A-Za-z0-9.-]+)(?::\d+)?((?:\/[\+~%\/.\w-_]*)?\??(?:[-\+=&%@.\w_]*)(?:#[-=&!.\/\w]*)?)?)`},
+		{`Path`, `\.{1,2}(?:[/\\][^\s#]*)?|[\w.~:-]*[/\\][^\s#]*`},
 		{`Ident`, `[a-zA-Z_][a-zA-Z_0-9\-.]*`},
 		{`Operator`, `==|!=|~=|>=|>|<=|<`},
 		{`VersionValue`, `[0-9\.\*]+`},
 		{`EnvironVersion`, `'(\\'|[^'])*'|"(\\"|[^"])*"`},
 		{"Punct", `\[|]|[-!()+*=,;@]`},
-		{`Whitespace`, `\s+`},
+		{`EOL`, `\n`},
+		// the backslash at the end of the line continues the line
+		{`Whitespace`, `[ \t\r]+|\\\r?\n`},
 	})
 
 	requirementParser := participle.MustBuild[Requirement](
@@ -72,8 +86,32 @@ func newRequirementsTxtParser() *participle.Parser[Requirement] {
 	return requirementParser
 }
 
+// toDependency converts the parsed package line to the dependency passed
+// to the package repository
+func (p *Package) toDependency() interfaces.Dependency {
+	dependency := interfaces.Dependency{
+		Name:   p.Name,
+		Extras: p.Extras,
+	}
+	if p.Download != nil {
+		dependency.URL = *p.Download
+	}
+	for _, version := range p.Versions {
+		dependency.Versions = append(dependency.Versions, interfaces.VersionSpecifier{
+			Operator: version.Operator,
+			Value:    version.Value,
+		})
+	}
+	var markers []string
+	for _, environ := range p.Environs {
+		markers = append(markers, fmt.Sprintf("%s %s %q", environ.Name, environ.Operator, environ.Value))
+	}
+	dependency.Markers = strings.Join(markers, " and ")
+	return dependency
+}
+
 // NewRequirementsTxt creates a new instance of the RequirementsTxt struct
-func NewRequirementsTxt() interfaces.DepFile {
+func NewRequirementsTxt() *RequirementsTxt {
 	return &RequirementsTxt{
 		parser: newRequirementsTxtParser(),
 	}
@@ -83,36 +121,210 @@ func (r *RequirementsTxt) GetDepFileType() string {
 	return "python/requirements.txt"
 }
 
+// GetRepository returns the repository querying the indexes configured
+// in the requirements file or in the environment, PyPI by default
+// The dependencies with the direct url are read from their archives
 func (r *RequirementsTxt) GetRepository() interfaces.PackageRepository {
-	return NewPyPI("PyPI", PyPIURL)
+	return newDirectReferenceRepository(r.indexes.urls(), nil)
 }
 
-func (r *RequirementsTxt) GetDependencies(ctx context.Context, fileName string) (<-chan string, error) {
-	data, err := ioutil.ReadFile(fileName)
-	if err != nil {
+// GetDependencies reads the dependencies of the requirements file and the files it includes
+// The -r/--requirement files are resolved relative to the including file,
+// the -c/--constraint files do not add any dependencies, their versions
+// constrain the versions of the dependencies with the same name
+func (r *RequirementsTxt) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	files := &requirementsFiles{parser: r.parser, read: map[string]bool{}}
+	if err := files.readFile(fileName, false, nil); err != nil {
 		return nil, err
 	}
+	r.indexes = files.indexes
 
-	req, err := r.parser.ParseString("", string(data))
-	if err != nil {
-		return nil, err
-	}
+	dependencies := files.constrainedDependencies()
 
-	depChan := make(chan string)
+	depChan := make(chan interfaces.Dependency)
 
 	go func() {
 		defer close(depChan)
-		for _, line := range req.Line {
+		for _, dependency := range dependencies {
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				if line.Package != nil {
-					depChan <- line.Package.Name
-				}
+			case depChan <- dependency:
 			}
 		}
 	}()
 
 	return depChan, nil
 }
+
+// ReadRequirements reads the requirements embedded in another file (e.g. the pip section
+// of the conda environment), the data is read as the contents of the file,
+// so the included files are resolved relative to it
+// The indexes configured by the requirements are used by the repository returned by GetRepository
+func (r *RequirementsTxt) ReadRequirements(fileName string, data string) ([]interfaces.Dependency, error) {
+	path, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+	files := &requirementsFiles{parser: r.parser, read: map[string]bool{readKey(path, false): true}}
+	if err := files.parse(fileName, data, false, []string{path}); err != nil {
+		return nil, err
+	}
+	r.indexes = files.indexes
+	return files.constrainedDependencies(), nil
+}
+
+// requirementsFiles collects the dependencies and constraints
+// of the requirements file and all the files it includes
+type requirementsFiles struct {
+	parser       *participle.Parser[Requirement]
+	dependencies []interfaces.Dependency
+	constraints  []interfaces.Dependency
+	// read contains the files already read keyed by readKey, a file included several times
+	// is read only once as the requirements and once as the constraints
+	read    map[string]bool
+	indexes packageIndexes
+}
+
+// readFile reads the file and follows its includes, the includes stack contains
+// the files including the file and it is used to detect the include cycles
+// The dependencies of the constraint files are collected as constraints
+func (f *requirementsFiles) readFile(fileName string, constraint bool, includes []string) error {
+	path, err := filepath.Abs(fileName)
+	if err != nil {
+		return err
+	}
+	for i, include := range includes {
+		if include == path {
+			return fmt.Errorf("include cycle: %s", strings.Join(append(includes[i:], path), " -> "))
+		}
+	}
+	if f.read[readKey(path, constraint)] {
+		return nil
+	}
+	f.read[readKey(path, constraint)] = true
+	includes = append(includes, path)
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	return f.parse(fileName, string(data), constraint, includes)
+}
+
+// readKey returns the key of the file read as the requirements or as the constraints
+func readKey(path string, constraint bool) string {
+	return path + "\x00" + strconv.FormatBool(constraint)
+}
+
+// parse reads the requirements of the file, the includes stack ends with the file itself
+func (f *requirementsFiles) parse(fileName string, data string, constraint bool, includes []string) error {
+	req, err := f.parser.ParseString(fileName, data)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range req.Line {
+		if line.Package != nil || line.Download != nil || line.Path != nil {
+			var dependency interfaces.Dependency
+			switch {
+			case line.Package != nil:
+				dependency = line.Package.toDependency()
+			case line.Download != nil:
+				dependency = interfaces.Dependency{Name: directReferenceName(*line.Download), URL: *line.Download}
+			default:
+				dependency = interfaces.Dependency{Name: directReferenceName(*line.Path), URL: *line.Path}
+			}
+			dependency.File = fileName
+			dependency.Line = line.Pos.Line
+			if constraint {
+				f.constraints = append(f.constraints, dependency)
+			} else {
+				f.dependencies = append(f.dependencies, dependency)
+			}
+			continue
+		}
+		if line.Command == nil {
+			continue
+		}
+
+		name := line.Command.name()
+		if indexOptions[name] {
+			if line.Command.Option == nil {
+				return fmt.Errorf("%s:%d: missing url of the %s option", fileName, line.Pos.Line, name)
+			}
+			if name == "--extra-index-url" {
+				f.indexes.extraIndexURLs = append(f.indexes.extraIndexURLs, *line.Command.Option)
+			} else {
+				f.indexes.indexURL = *line.Command.Option
+			}
+			continue
+		}
+
+		includeConstraint, ok := line.Command.include()
+		if !ok {
+			continue
+		}
+		if line.Command.Option == nil {
+			return fmt.Errorf("%s:%d: missing file name of the included file", fileName, line.Pos.Line)
+		}
+		included := *line.Command.Option
+		if !filepath.IsAbs(included) {
+			included = filepath.Join(filepath.Dir(fileName), included)
+		}
+		if err := f.readFile(included, constraint || includeConstraint, includes); err != nil {
+			return fmt.Errorf("%s:%d: %w", fileName, line.Pos.Line, err)
+		}
+	}
+	return nil
+}
+
+// indexOptions configure the package indexes
+var indexOptions = map[string]bool{"-i": true, "--index-url": true, "--extra-index-url": true}
+
+// name returns the command name with the dashes, e.g. -r or --requirement
+func (c *Command) name() string {
+	if c.LongName != nil {
+		return "--" + *c.LongName
+	}
+	if c.ShortName != nil {
+		return "-" + *c.ShortName
+	}
+	return ""
+}
+
+// include reports whether the command includes another file and whether
+// the file contains constraints
+func (c *Command) include() (constraint bool, ok bool) {
+	switch c.name() {
+	case "-r", "--requirement":
+		return false, true
+	case "-c", "--constraint":
+		return true, true
+	}
+	return false, false
+}
+
+// constrainedDependencies returns the dependencies with the version specifiers
+// of the constraints added, the constraints are matched by the normalized package name
+func (f *requirementsFiles) constrainedDependencies() []interfaces.Dependency {
+	constraints := map[string][]interfaces.VersionSpecifier{}
+	for _, constraint := range f.constraints {
+		name := interfaces.NormalizeName(constraint.Name)
+		constraints[name] = append(constraints[name], constraint.Versions...)
+	}
+
+	dependencies := make([]interfaces.Dependency, 0, len(f.dependencies))
+	for _, dependency := range f.dependencies {
+		if versions, ok := constraints[interfaces.NormalizeName(dependency.Name)]; ok {
+			dependency.Versions = append(append([]interfaces.VersionSpecifier{}, dependency.Versions...), versions...)
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies
+}
+
+// EvaluateMarkers evaluates the PEP 508 environment markers of the dependency
+func (r *RequirementsTxt) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return evaluateMarkers(dependency.Markers, environment, extras)
+}
@@ -2,11 +2,15 @@
 package python
 
 import (
+	"context"
 	"io/ioutil"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/radiculaCZ/license-check/interfaces"
 )
 
 func stringPtr(s string) *string {
@@ -314,7 +318,143 @@ func TestRequirementsTxtLineVariants(t *testing.T) {
 		},
 	}
 
-	if diff := cmp.Diff(expected, req); diff != "" {
+	if diff := cmp.Diff(expected, req, cmpopts.IgnoreFields(Line{}, "Pos")); diff != "" {
 		t.Fatalf("RequirementsTxt parser mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestRequirementsTxtGetDependencies(t *testing.T) {
+	fileName := t.TempDir() + "/requirements.txt"
+	data := `contourpy [bold] ==1.0.6; python_version >= "3.6", platform == "linux"
+numpy >= 1.23.5, < 1.24
+--pre
+`
+	if err := ioutil.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dependencies, err := NewRequirementsTxt().GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []interfaces.Dependency
+	for dependency := range dependencies {
+		got = append(got, dependency)
+	}
+
+	expected := []interfaces.Dependency{
+		{
+			Name:     "contourpy",
+			Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: "1.0.6"}},
+			Extras:   []string{"bold"},
+			Markers:  `python_version >= "3.6" and platform == "linux"`,
+			File:     fileName,
+			Line:     1,
+		},
+		{
+			Name: "numpy",
+			Versions: []interfaces.VersionSpecifier{
+				{Operator: ">=", Value: "1.23.5"},
+				{Operator: "<", Value: "1.24"},
+			},
+			File: fileName,
+			Line: 2,
+		},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("GetDependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	if version := got[0].PinnedVersion(); version != "1.0.6" {
+		t.Fatalf("Expected pinned version 1.0.6, got %s", version)
+	}
+
+	if version := got[1].PinnedVersion(); version != "" {
+		t.Fatalf("Expected no pinned version, got %s", version)
+	}
+}
+
+func TestRequirementsTxtIncludes(t *testing.T) {
+	dependencies, err := NewRequirementsTxt().GetDependencies(context.Background(), "testdata/includes/prod.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []interfaces.Dependency
+	for dependency := range dependencies {
+		got = append(got, dependency)
+	}
+
+	expected := []interfaces.Dependency{
+		{
+			Name: "requests",
+			Versions: []interfaces.VersionSpecifier{
+				{Operator: ">=", Value: "2"},
+				{Operator: "==", Value: "2.31.0"},
+			},
+			File: "testdata/includes/base/common.txt",
+			Line: 1,
+		},
+		{
+			Name: "six",
+			File: "testdata/includes/shared.txt",
+			Line: 1,
+		},
+		{
+			Name:     "gunicorn",
+			Versions: []interfaces.VersionSpecifier{{Operator: "<", Value: "22"}},
+			File:     "testdata/includes/prod.txt",
+			Line:     4,
+		},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("GetDependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	// the version pinned by the constraint is used
+	if version := got[0].PinnedVersion(); version != "2.31.0" {
+		t.Fatalf("Expected pinned version 2.31.0, got %s", version)
+	}
+}
+
+func TestRequirementsTxtIncludeCycle(t *testing.T) {
+	_, err := NewRequirementsTxt().GetDependencies(context.Background(), "testdata/includes/cycle_a.txt")
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("Expected include cycle error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "cycle_a.txt -> ") || !strings.Contains(err.Error(), "cycle_b.txt:2") {
+		t.Errorf("Expected the cycle and its location in the error, got %v", err)
+	}
+}
+
+// TestRequirementsTxtConstraintAndInclude tests that the file read as the constraints
+// still adds its requirements when it is included by -r as well
+func TestRequirementsTxtConstraintAndInclude(t *testing.T) {
+	dependencies, err := NewRequirementsTxt().GetDependencies(context.Background(), "testdata/includes/mixed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []interfaces.Dependency
+	for dependency := range dependencies {
+		got = append(got, dependency)
+	}
+
+	expected := []interfaces.Dependency{
+		{
+			Name: "attrs",
+			Versions: []interfaces.VersionSpecifier{
+				{Operator: "==", Value: "23.1.0"},
+				{Operator: "==", Value: "23.1.0"},
+			},
+			File: "testdata/includes/pins.txt",
+			Line: 1,
+		},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("GetDependencies mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,316 @@
+// Used to get packages from the package indexes implementing the Simple API
+// (devpi, Artifactory, Nexus, pypiserver, ...) which do not have the PyPI JSON API
+// Uses the PEP 691 JSON Simple API https://peps.python.org/pep-0691/
+// with the fallback to the PEP 503 HTML pages https://peps.python.org/pep-0503/
+// The license is read from the core metadata of the distribution file of the release,
+// the PEP 658 metadata file is used when the index provides it, otherwise
+// only the needed parts of the wheel are downloaded using the range requests
+package python
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+const (
+	simpleJSONContentType = "application/vnd.pypi.simple.v1+json"
+	simpleAcceptHeader    = simpleJSONContentType + ", text/html;q=0.1"
+)
+
+// SimpleAPI represents the package index implementing the Simple API
+type SimpleAPI struct {
+	name string
+	// url is the index url without the credentials, e.g. https://pypi.org/simple
+	url  string
+	auth *indexAuth
+}
+
+// simpleProject is the project page of the JSON Simple API
+type simpleProject struct {
+	Files []simpleFile `json:"files"`
+}
+
+type simpleFile struct {
+	Filename string `json:"filename"`
+	URL      string `json:"url"`
+	// Yanked is either false or the reason of the yank
+	Yanked simpleFlag `json:"yanked"`
+	// CoreMetadata tells whether the PEP 658 metadata file is available,
+	// the older indexes use the data-dist-info-metadata name
+	CoreMetadata     simpleFlag `json:"core-metadata"`
+	DistInfoMetadata simpleFlag `json:"data-dist-info-metadata"`
+}
+
+// simpleFlag is the Simple API value which is either false or the details
+// (e.g. the yank reason or the metadata hashes), anything but false is set
+type simpleFlag bool
+
+func (f *simpleFlag) UnmarshalJSON(data []byte) error {
+	value := strings.TrimSpace(string(data))
+	*f = simpleFlag(value != "false" && value != "null")
+	return nil
+}
+
+// NewSimpleAPI creates a new instance of the SimpleAPI struct
+// the index url can contain the credentials, see auth.go for the other sources
+func NewSimpleAPI(indexURL string) interfaces.PackageRepository {
+	index, auth := splitIndexURL(indexURL)
+	return &SimpleAPI{
+		name: "Simple API",
+		url:  strings.TrimSuffix(index, "/"),
+		auth: auth,
+	}
+}
+
+func (s *SimpleAPI) GetRepositoryName() string {
+	return s.name
+}
+
+func (s *SimpleAPI) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	files, err := s.projectFiles(ctx, dependency.Name)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	file, err := selectDistribution(dependency, files)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: err}
+	}
+
+	info, err := s.distributionMetadata(ctx, file)
+	if err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: err}
+	}
+
+	meta := convertPyPIResponseToPackageMeta(pypiResponse{Info: info})
+	meta.Index = s.url
+	return meta, nil
+}
+
+func (s *SimpleAPI) get(ctx context.Context, url string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if s.auth != nil {
+		s.auth.apply(req)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// projectFiles returns the distribution files of the project
+// the urls of the files are resolved against the project page url
+func (s *SimpleAPI) projectFiles(ctx context.Context, name string) ([]simpleFile, error) {
+	pageURL := s.url + "/" + interfaces.NormalizeName(name) + "/"
+	resp, err := s.get(ctx, pageURL, simpleAcceptHeader)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var files []simpleFile
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, simpleJSONContentType) || strings.HasPrefix(contentType, "application/json") {
+		var project simpleProject
+		if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+			return nil, err
+		}
+		files = project.Files
+	} else {
+		files, err = parseSimpleHTML(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	base, err := url.Parse(resp.Request.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		fileURL, err := base.Parse(files[i].URL)
+		if err != nil {
+			return nil, err
+		}
+		// the fragment contains the hash of the file
+		fileURL.Fragment = ""
+		files[i].URL = fileURL.String()
+	}
+	return files, nil
+}
+
+// parseSimpleHTML reads the files from the anchors of the PEP 503 project page
+func parseSimpleHTML(r io.Reader) ([]simpleFile, error) {
+	var files []simpleFile
+	var current *simpleFile
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return files, nil
+			}
+			return nil, tokenizer.Err()
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			if token.Data != "a" {
+				continue
+			}
+			current = &simpleFile{}
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "href":
+					current.URL = attr.Val
+				case "data-yanked":
+					current.Yanked = true
+				case "data-core-metadata", "data-dist-info-metadata":
+					current.CoreMetadata = simpleFlag(attr.Val != "false")
+				}
+			}
+		case html.TextToken:
+			if current != nil {
+				current.Filename += strings.TrimSpace(string(tokenizer.Text()))
+			}
+		case html.EndTagToken:
+			if current != nil && tokenizer.Token().Data == "a" {
+				if current.Filename == "" {
+					current.Filename = current.URL[strings.LastIndex(current.URL, "/")+1:]
+				}
+				files = append(files, *current)
+				current = nil
+			}
+		}
+	}
+}
+
+// distributionMetadata reads the core metadata of the distribution file
+func (s *SimpleAPI) distributionMetadata(ctx context.Context, file simpleFile) (pypiInfo, error) {
+	if file.CoreMetadata || file.DistInfoMetadata {
+		resp, err := s.get(ctx, file.URL+".metadata", "")
+		if err != nil {
+			return pypiInfo{}, err
+		}
+		defer resp.Body.Close()
+		return parseMetadata(resp.Body)
+	}
+
+	if strings.HasSuffix(file.Filename, ".whl") || strings.HasSuffix(file.Filename, ".zip") {
+		reader, size, err := openRemoteFile(ctx, file.URL, s.auth)
+		if err != nil {
+			return pypiInfo{}, err
+		}
+		return readZipMetadata(reader, size)
+	}
+
+	// the tar archives have no index, so they are downloaded as a whole
+	resp, err := s.get(ctx, file.URL, "")
+	if err != nil {
+		return pypiInfo{}, err
+	}
+	defer resp.Body.Close()
+	return readTarMetadata(resp.Body, file.Filename)
+}
+
+// selectDistribution returns the file of the release matching the dependency
+// The pinned version is used even when it is yanked, otherwise the highest
+// version matching the version specifiers is used
+// The files with the PEP 658 metadata are preferred, then the wheels, which can be
+// read partially, and finally the source distributions
+func selectDistribution(dependency interfaces.Dependency, files []simpleFile) (simpleFile, error) {
+	version := dependency.PinnedVersion()
+	if version == "" {
+		var versions []string
+		for _, file := range files {
+			if fileVersion, ok := distributionVersion(file.Filename, dependency.Name); ok && !bool(file.Yanked) {
+				versions = append(versions, fileVersion)
+			}
+		}
+		version = bestVersion(versions, dependency.Versions)
+		if version == "" {
+			return simpleFile{}, fmt.Errorf("no release matches the version specifiers")
+		}
+	}
+	wanted, err := parseVersion(version)
+	if err != nil {
+		return simpleFile{}, err
+	}
+
+	var selected *simpleFile
+	selectedRank := 0
+	for i, file := range files {
+		fileVersion, ok := distributionVersion(file.Filename, dependency.Name)
+		if !ok {
+			continue
+		}
+		if parsed, err := parseVersion(fileVersion); err != nil || parsed.compare(wanted) != 0 {
+			continue
+		}
+		rank := 1
+		if strings.HasSuffix(file.Filename, ".whl") {
+			rank = 2
+		}
+		if file.CoreMetadata || file.DistInfoMetadata {
+			rank = 3
+		}
+		if rank > selectedRank {
+			selected, selectedRank = &files[i], rank
+		}
+	}
+	if selected == nil {
+		return simpleFile{}, fmt.Errorf("no distribution file of version %s", version)
+	}
+	return *selected, nil
+}
+
+// sdistExtensions are the extensions of the source distributions
+var sdistExtensions = []string{".tar.gz", ".tar.bz2", ".tgz", ".zip"}
+
+// distributionVersion returns the version from the file name of the wheel
+// or the source distribution of the package, e.g. requests-2.31.0-py3-none-any.whl
+func distributionVersion(filename string, name string) (string, bool) {
+	if strings.HasSuffix(filename, ".whl") {
+		parts := strings.Split(strings.TrimSuffix(filename, ".whl"), "-")
+		if len(parts) < 5 || interfaces.NormalizeName(parts[0]) != interfaces.NormalizeName(name) {
+			return "", false
+		}
+		return parts[1], true
+	}
+	for _, extension := range sdistExtensions {
+		if !strings.HasSuffix(filename, extension) {
+			continue
+		}
+		stem := strings.TrimSuffix(filename, extension)
+		// the legacy source distributions do not normalize the dashes in the name
+		for i := strings.Index(stem, "-"); i >= 0; {
+			if interfaces.NormalizeName(stem[:i]) == interfaces.NormalizeName(name) {
+				return stem[i+1:], true
+			}
+			next := strings.Index(stem[i+1:], "-")
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+	}
+	return "", false
+}
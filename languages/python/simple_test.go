@@ -0,0 +1,260 @@
+package python
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+const wheelMetadata = `Metadata-Version: 2.3
+Name: pkg-json
+Version: 1.1
+Author-email: Jane Doe <jane@example.com>
+License-Expression: Apache-2.0
+License-File: LICENSE
+Requires-Dist: six
+Requires-Dist: pytest; extra == "test"
+Project-URL: Homepage, https://example.com
+Project-URL: Source, https://example.com/src
+
+Long description.
+`
+
+const sdistMetadata = `Metadata-Version: 1.1
+Name: pkg-html
+Version: 0.5
+License: MIT License
+        |
+        |Copyright (c) Jane Doe
+Classifier: License :: OSI Approved :: MIT License
+`
+
+func buildWheel(t *testing.T, metadata string) []byte {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	// the stored padding makes the wheel larger than a single range request
+	padding, err := archive.CreateHeader(&zip.FileHeader{Name: "pkg_json/data.bin", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	padding.Write(bytes.Repeat([]byte{'x'}, 4*minRangeSize))
+	file, err := archive.Create("pkg_json-1.1.dist-info/METADATA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte(metadata))
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func buildSdist(t *testing.T, metadata string) []byte {
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	archive := tar.NewWriter(gz)
+	files := map[string]string{
+		"pkg-html-0.5/pkg_html.egg-info/PKG-INFO": "Name: wrong\n",
+		"pkg-html-0.5/PKG-INFO":                   metadata,
+	}
+	for _, name := range []string{"pkg-html-0.5/pkg_html.egg-info/PKG-INFO", "pkg-html-0.5/PKG-INFO"} {
+		archive.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})
+		archive.Write([]byte(files[name]))
+	}
+	archive.Close()
+	gz.Close()
+	return buffer.Bytes()
+}
+
+func TestSimpleAPI(t *testing.T) {
+	wheel := buildWheel(t, wheelMetadata)
+	sdist := buildSdist(t, sdistMetadata)
+
+	var mutex sync.Mutex
+	var wheelRequests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/simple/pkg-json/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), simpleJSONContentType) {
+			t.Errorf("Expected JSON Simple API to be accepted, got %s", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", simpleJSONContentType)
+		fmt.Fprint(w, `{"meta": {"api-version": "1.0"}, "name": "pkg-json", "files": [
+			{"filename": "pkg_json-1.0-py3-none-any.whl", "url": "/files/pkg_json-1.0-py3-none-any.whl#sha256=00", "hashes": {}, "core-metadata": {"sha256": "00"}},
+			{"filename": "pkg-json-1.0.tar.gz", "url": "/files/pkg-json-1.0.tar.gz", "hashes": {}},
+			{"filename": "pkg_json-1.1-py3-none-any.whl", "url": "/files/pkg_json-1.1-py3-none-any.whl", "hashes": {}, "core-metadata": false},
+			{"filename": "pkg_json-2.0-py3-none-any.whl", "url": "/files/pkg_json-2.0-py3-none-any.whl", "hashes": {}, "yanked": "broken"}
+		]}`)
+	})
+	mux.HandleFunc("/files/pkg_json-1.0-py3-none-any.whl.metadata", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Replace(wheelMetadata, "Version: 1.1", "Version: 1.0", 1))
+	})
+	mux.HandleFunc("/files/pkg_json-1.1-py3-none-any.whl", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		wheelRequests = append(wheelRequests, r.Header.Get("Range"))
+		mutex.Unlock()
+		http.ServeContent(w, r, "wheel.whl", time.Time{}, bytes.NewReader(wheel))
+	})
+	mux.HandleFunc("/simple/pkg-html/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<!DOCTYPE html><html><body>
+			<a href="../../files/pkg-html-0.5.tar.gz#sha256=00">pkg-html-0.5.tar.gz</a>
+			<a href="../../files/pkg_html-0.6-py3-none-any.whl" data-yanked="">pkg_html-0.6-py3-none-any.whl</a>
+		</body></html>`)
+	})
+	mux.HandleFunc("/files/pkg-html-0.5.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(sdist)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	simple := NewSimpleAPI(server.URL + "/simple/")
+
+	// the PEP 658 metadata file is used
+	meta, err := simple.GetPackageInfo(context.Background(), interfaces.Dependency{
+		Name:     "pkg_json",
+		Versions: []interfaces.VersionSpecifier{{Operator: "<", Value: "1.1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Version != "1.0" || meta.Index != server.URL+"/simple" {
+		t.Errorf("Expected 1.0 from %s/simple, got %s from %s", server.URL, meta.Version, meta.Index)
+	}
+
+	// the yanked release is skipped and the wheel is read partially
+	meta, err = simple.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "pkg-json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &interfaces.PackageMeta{
+		Author:        "Jane Doe <jane@example.com>",
+		Name:          "pkg-json",
+		Version:       "1.1",
+		License:       "Apache-2.0",
+		LicenseSPDX:   "Apache-2.0",
+		LicenseSource: interfaces.LicenseSourceExpression,
+		LicenseFiles:  []string{"LICENSE"},
+		Description:   "Long description.",
+		Homepage:      "https://example.com",
+		Repository:    "https://example.com/src",
+		Language:      "python",
+		Requires: []interfaces.Dependency{
+			{Name: "six"},
+			{Name: "pytest", Markers: `extra == "test"`},
+		},
+		Index: server.URL + "/simple",
+	}
+	if diff := cmp.Diff(expected, meta); diff != "" {
+		t.Errorf("PackageMeta mismatch (-want +got):\n%s", diff)
+	}
+	if len(wheelRequests) == 0 || len(wheelRequests) > 3 {
+		t.Errorf("Expected the wheel to be read by a few range requests, got %v", wheelRequests)
+	}
+	for _, byteRange := range wheelRequests {
+		if byteRange == "" {
+			t.Errorf("Expected only range requests, got the full download")
+		}
+	}
+
+	// the HTML page and the source distribution
+	meta, err = simple.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "pkg-html"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Name != "pkg-html" || meta.Version != "0.5" || meta.LicenseSPDX != "MIT" {
+		t.Errorf("Expected pkg-html 0.5 under MIT, got %s %s under %s", meta.Name, meta.Version, meta.LicenseSPDX)
+	}
+
+	_, err = simple.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "missing"})
+	if err == nil {
+		t.Error("Expected missing package to fail")
+	}
+}
+
+func TestDistributionVersion(t *testing.T) {
+	tests := []struct {
+		filename string
+		name     string
+		version  string
+	}{
+		{"requests-2.31.0-py3-none-any.whl", "requests", "2.31.0"},
+		{"typing_extensions-4.8.0-py3-none-any.whl", "typing-extensions", "4.8.0"},
+		{"python-dateutil-2.8.2.tar.gz", "python_dateutil", "2.8.2"},
+		{"pkg-1.0-1-py3-none-any.whl", "pkg", "1.0"},
+		{"other-1.0.tar.gz", "pkg", ""},
+		{"pkg-1.0.exe", "pkg", ""},
+	}
+	for _, test := range tests {
+		if version, _ := distributionVersion(test.filename, test.name); version != test.version {
+			t.Errorf("Expected %q for %s, got %q", test.version, test.filename, version)
+		}
+	}
+}
+
+// TestSimpleAPIFileHost tests that the index credentials are not sent
+// to the files hosted outside of the index
+func TestSimpleAPIFileHost(t *testing.T) {
+	wheel := buildWheel(t, wheelMetadata)
+
+	var mutex sync.Mutex
+	var authorized []string
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			mutex.Lock()
+			authorized = append(authorized, r.URL.Path)
+			mutex.Unlock()
+		}
+		switch r.URL.Path {
+		case "/pkg_json-1.0-py3-none-any.whl.metadata":
+			fmt.Fprint(w, strings.Replace(wheelMetadata, "Version: 1.1", "Version: 1.0", 1))
+		case "/pkg_json-1.1-py3-none-any.whl":
+			http.ServeContent(w, r, "wheel.whl", time.Time{}, bytes.NewReader(wheel))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer files.Close()
+
+	index := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", simpleJSONContentType)
+		fmt.Fprintf(w, `{"meta": {"api-version": "1.0"}, "name": "pkg-json", "files": [
+			{"filename": "pkg_json-1.0-py3-none-any.whl", "url": "%[1]s/pkg_json-1.0-py3-none-any.whl", "hashes": {}, "core-metadata": true},
+			{"filename": "pkg_json-1.1-py3-none-any.whl", "url": "%[1]s/pkg_json-1.1-py3-none-any.whl", "hashes": {}}
+		]}`, files.URL)
+	}))
+	defer index.Close()
+
+	simple := NewSimpleAPI(strings.Replace(index.URL, "http://", "http://user:secret@", 1) + "/simple/")
+	for _, version := range []string{"1.0", "1.1"} {
+		meta, err := simple.GetPackageInfo(context.Background(), interfaces.Dependency{
+			Name:     "pkg-json",
+			Versions: []interfaces.VersionSpecifier{{Operator: "==", Value: version}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.Version != version {
+			t.Errorf("Expected %s, got %s", version, meta.Version)
+		}
+	}
+	if len(authorized) > 0 {
+		t.Errorf("Expected no credentials sent to the files host, got them for %v", authorized)
+	}
+}
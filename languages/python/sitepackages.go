@@ -0,0 +1,261 @@
+// Reads the packages installed in the python environment
+// The path is either a virtual environment or the site-packages directory,
+// the packages are found by their *.dist-info and *.egg-info directories
+// https://packaging.python.org/en/latest/specifications/recording-installed-packages/
+// The package meta is read from the installed metadata and license files,
+// so no package index is queried
+package python
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// SitePackages represents the installed python environment
+type SitePackages struct {
+	// packages are the installed packages keyed by the normalized name
+	packages map[string]installedPackage
+}
+
+// installedPackage is the package meta read from the metadata directory
+// or the error when the metadata cannot be read
+type installedPackage struct {
+	meta *interfaces.PackageMeta
+	err  error
+}
+
+// NewSitePackages creates a new instance of the SitePackages struct
+func NewSitePackages() *SitePackages {
+	return &SitePackages{}
+}
+
+func (s *SitePackages) GetDepFileType() string {
+	return "python/site-packages"
+}
+
+// GetRepository returns the repository serving the installed packages,
+// the packages which are not installed cannot be found
+func (s *SitePackages) GetRepository() interfaces.PackageRepository {
+	return &installedRepository{packages: s.packages}
+}
+
+// GetDependencies returns all packages installed in the environment pinned to the installed version
+func (s *SitePackages) GetDependencies(ctx context.Context, path string) (<-chan interfaces.Dependency, error) {
+	directories, err := sitePackagesDirectories(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.packages = map[string]installedPackage{}
+	var dependencies []interfaces.Dependency
+	for _, directory := range directories {
+		entries, err := os.ReadDir(directory)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name, version, ok := installedDistribution(entry.Name())
+			if !ok {
+				continue
+			}
+			// the first site-packages directory shadows the others the same way it does in python
+			key := interfaces.NormalizeName(name)
+			if _, ok := s.packages[key]; ok {
+				continue
+			}
+
+			metaPath := filepath.Join(directory, entry.Name())
+			meta, err := readInstalledPackage(metaPath)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", metaPath, err)
+			} else {
+				name, version = meta.Name, meta.Version
+			}
+			s.packages[key] = installedPackage{meta: meta, err: err}
+
+			dependency := interfaces.Dependency{Name: name, File: metaPath}
+			if version != "" {
+				dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: version}}
+			}
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// EvaluateMarkers evaluates the markers of the requirements of the installed packages
+func (s *SitePackages) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return evaluateMarkers(dependency.Markers, environment, extras)
+}
+
+// sitePackagesDirectories returns the site-packages directories of the virtual environment
+// (lib/pythonX.Y/site-packages on unix, Lib/site-packages on windows)
+// or the path itself when it is not a virtual environment
+func sitePackagesDirectories(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", path)
+	}
+
+	var directories []string
+	for _, pattern := range []string{"lib/python*/site-packages", "lib64/python*/site-packages", "Lib/site-packages"} {
+		matches, err := filepath.Glob(filepath.Join(path, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			// lib64 is usually a symlink to lib
+			if resolved, err := filepath.EvalSymlinks(match); err == nil && !containsString(directories, resolved) {
+				directories = append(directories, resolved)
+			}
+		}
+	}
+	if len(directories) == 0 {
+		directories = append(directories, path)
+	}
+	return directories, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// installedDistribution returns the name and the version from the name
+// of the metadata directory, e.g. requests-2.31.0.dist-info or six-1.16.0-py3.11.egg-info
+// the version is empty for the egg-info directories of the development installs
+func installedDistribution(entry string) (string, string, bool) {
+	stem, ok := strings.CutSuffix(entry, ".dist-info")
+	if !ok {
+		stem, ok = strings.CutSuffix(entry, ".egg-info")
+	}
+	if !ok || stem == "" {
+		return "", "", false
+	}
+	parts := strings.Split(stem, "-")
+	if len(parts) == 1 {
+		return parts[0], "", true
+	}
+	return parts[0], parts[1], true
+}
+
+// readInstalledPackage reads the package meta from the metadata directory
+// the egg-info can be a single PKG-INFO file as well
+func readInstalledPackage(metaPath string) (*interfaces.PackageMeta, error) {
+	info, err := os.Stat(metaPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(metaPath)
+		if err != nil {
+			return nil, err
+		}
+		return installedPackageMeta(data, nil)
+	}
+
+	metadataFile := "METADATA"
+	if strings.HasSuffix(metaPath, ".egg-info") {
+		metadataFile = "PKG-INFO"
+	}
+	data, err := os.ReadFile(filepath.Join(metaPath, metadataFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var licenses []archiveFile
+	err = filepath.WalkDir(metaPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(metaPath, path)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+		// PEP 639 licenses directory or the license files next to the metadata
+		if !strings.HasPrefix(relative, "licenses/") && (strings.Contains(relative, "/") || !licenseFilePattern.MatchString(relative)) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if len(content) > maxArchiveFileSize {
+			content = content[:maxArchiveFileSize]
+		}
+		licenses = append(licenses, archiveFile{name: relative, data: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return installedPackageMeta(data, licenses)
+}
+
+// installedPackageMeta converts the installed metadata to the package meta,
+// the license is detected from the license files when the metadata do not contain it
+func installedPackageMeta(metadata []byte, licenses []archiveFile) (*interfaces.PackageMeta, error) {
+	info, err := parseMetadata(bytes.NewReader(metadata))
+	if err != nil {
+		return nil, err
+	}
+	if len(info.LicenseFiles) == 0 {
+		for _, license := range licenses {
+			info.LicenseFiles = append(info.LicenseFiles, license.name)
+		}
+	}
+	meta := convertPyPIResponseToPackageMeta(pypiResponse{Info: info})
+	detectLicense(meta, licenses)
+	return meta, nil
+}
+
+// installedRepository returns the package meta of the installed packages
+type installedRepository struct {
+	packages map[string]installedPackage
+}
+
+func (i *installedRepository) GetRepositoryName() string {
+	return "site-packages"
+}
+
+func (i *installedRepository) GetPackageInfo(ctx context.Context, dependency interfaces.Dependency) (*interfaces.PackageMeta, error) {
+	installed, ok := i.packages[interfaces.NormalizeName(dependency.Name)]
+	if !ok {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageFetch, Err: fmt.Errorf("package is not installed")}
+	}
+	if installed.err != nil {
+		return nil, &interfaces.PackageError{Package: dependency.Name, Stage: interfaces.StageDecode, Err: installed.err}
+	}
+	// the meta is copied, so the core can change it for every dependency path
+	meta := *installed.meta
+	return &meta, nil
+}
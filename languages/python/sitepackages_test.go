@@ -0,0 +1,89 @@
+package python
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSitePackages(t *testing.T) {
+	license, err := os.ReadFile("testdata/LICENSE-MIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	venv := t.TempDir()
+	writeFiles(t, venv, map[string]string{
+		"pyvenv.cfg": "home = /usr/bin\n",
+		"lib/python3.11/site-packages/requests/__init__.py": "",
+		"lib/python3.11/site-packages/requests-2.31.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\n" +
+			"License: Apache 2.0\nClassifier: License :: OSI Approved :: Apache Software License\nRequires-Dist: idna (<4,>=2.5)\n",
+		"lib/python3.11/site-packages/requests-2.31.0.dist-info/LICENSE":        "Apache License",
+		"lib/python3.11/site-packages/Plain_Lib-1.0.dist-info/METADATA":         "Metadata-Version: 2.4\nName: plain-lib\nVersion: 1.0\n",
+		"lib/python3.11/site-packages/Plain_Lib-1.0.dist-info/licenses/COPYING": string(license),
+		"lib/python3.11/site-packages/legacy-0.1-py3.11.egg-info":               "Metadata-Version: 1.0\nName: legacy\nVersion: 0.1\nLicense: BSD-3-Clause\n",
+		"lib/python3.11/site-packages/broken-1.0.dist-info/RECORD":              "",
+	})
+
+	sitePackages := NewSitePackages()
+	dependencies, err := sitePackages.GetDependencies(context.Background(), venv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for dependency := range dependencies {
+		names = append(names, dependency.Name+"=="+dependency.PinnedVersion())
+	}
+	if diff := cmp.Diff([]string{"plain-lib==1.0", "broken==1.0", "legacy==0.1", "requests==2.31.0"}, names); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	repository := sitePackages.GetRepository()
+	tests := map[string]struct {
+		license      string
+		spdx         string
+		source       string
+		licenseFiles []string
+	}{
+		"requests":  {"Apache Software License", "", interfaces.LicenseSourceClassifier, []string{"LICENSE"}},
+		"plain_lib": {"MIT", "MIT", interfaces.LicenseSourceFile, []string{"licenses/COPYING"}},
+		"legacy":    {"BSD-3-Clause", "BSD-3-Clause", interfaces.LicenseSourceField, nil},
+	}
+	for name, test := range tests {
+		meta, err := repository.GetPackageInfo(context.Background(), interfaces.Dependency{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.License != test.license || meta.LicenseSPDX != test.spdx || meta.LicenseSource != test.source {
+			t.Errorf("%s: expected %s (%s) from %s, got %s (%s) from %s", name, test.license, test.spdx, test.source, meta.License, meta.LicenseSPDX, meta.LicenseSource)
+		}
+		if diff := cmp.Diff(test.licenseFiles, meta.LicenseFiles); diff != "" {
+			t.Errorf("%s: LicenseFiles mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	var pkgErr *interfaces.PackageError
+	if _, err := repository.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "broken"}); !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageDecode {
+		t.Errorf("Expected decode error of the missing METADATA, got %v", err)
+	}
+	if _, err := repository.GetPackageInfo(context.Background(), interfaces.Dependency{Name: "idna"}); !errors.As(err, &pkgErr) || pkgErr.Stage != interfaces.StageFetch {
+		t.Errorf("Expected fetch error of the package which is not installed, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+// Reads the dependencies locked by uv https://docs.astral.sh/uv/concepts/projects/layout/#the-lockfile
+// The lock contains the workspace members (the project itself) and every package
+// they require pinned to the exact version with the source it comes from
+// The graph of the lock tells which packages are the direct dependencies
+// of the project and which groups they belong to
+package python
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+type uvLockFile struct {
+	Manifest struct {
+		Members []string `toml:"members"`
+	} `toml:"manifest"`
+	Package []uvPackage `toml:"package"`
+}
+
+type uvPackage struct {
+	Name         string         `toml:"name"`
+	Version      string         `toml:"version"`
+	Source       uvSource       `toml:"source"`
+	Dependencies []uvDependency `toml:"dependencies"`
+	// OptionalDependencies are keyed by the extra
+	OptionalDependencies map[string][]uvDependency `toml:"optional-dependencies"`
+	// DevDependencies are keyed by the dependency group, they are set only for the workspace members
+	DevDependencies map[string][]uvDependency `toml:"dev-dependencies"`
+}
+
+// uvSource is the source of the package, only one of the fields is set
+type uvSource struct {
+	Registry  string `toml:"registry"`
+	Git       string `toml:"git"`
+	URL       string `toml:"url"`
+	Path      string `toml:"path"`
+	Directory string `toml:"directory"`
+	Editable  string `toml:"editable"`
+	Virtual   string `toml:"virtual"`
+}
+
+type uvDependency struct {
+	Name   string   `toml:"name"`
+	Extra  []string `toml:"extra"`
+	Marker string   `toml:"marker"`
+}
+
+// UvLock represents the uv.lock file
+type UvLock struct {
+	sources []string
+}
+
+// NewUvLock creates a new instance of the UvLock struct
+func NewUvLock() *UvLock {
+	return &UvLock{}
+}
+
+func (u *UvLock) GetDepFileType() string {
+	return "python/uv.lock"
+}
+
+// GetRepository returns the repository querying the registries the locked packages come from
+func (u *UvLock) GetRepository() interfaces.PackageRepository {
+	return newDirectReferenceRepository([]string{DefaultIndexURL}, u.sources)
+}
+
+func (u *UvLock) EvaluateMarkers(dependency interfaces.Dependency, environment interfaces.Environment, extras []string) (bool, error) {
+	return evaluateMarkers(dependency.Markers, environment, extras)
+}
+
+// GetDependencies returns the locked packages except the workspace members
+// The dependencies of the members are in the main group, their optional dependencies
+// in the group of the extra and their development dependencies in the dependency group
+func (u *UvLock) GetDependencies(ctx context.Context, fileName string) (<-chan interfaces.Dependency, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var lock uvLockFile
+	if _, err := toml.Decode(string(data), &lock); err != nil {
+		return nil, fmt.Errorf("%s: invalid uv.lock: %w", fileName, err)
+	}
+
+	var packages []lockPackage
+	var roots []interfaces.Dependency
+	for _, pkg := range lock.Package {
+		if isUvMember(pkg, lock.Manifest.Members) {
+			roots = append(roots, uvRoots(pkg)...)
+			continue
+		}
+		node := lockPackage{name: pkg.Name, dependencies: uvEdges(pkg.Dependencies), optional: map[string][]lockEdge{}}
+		for extra, dependencies := range pkg.OptionalDependencies {
+			node.optional[interfaces.NormalizeName(extra)] = uvEdges(dependencies)
+		}
+		packages = append(packages, node)
+	}
+	graph := newLockGraph(packages)
+	graph.walk(roots)
+
+	u.sources = nil
+	var dependencies []interfaces.Dependency
+	for _, pkg := range lock.Package {
+		if isUvMember(pkg, lock.Manifest.Members) {
+			continue
+		}
+		dependency := interfaces.Dependency{Name: pkg.Name, File: fileName}
+		if pkg.Version != "" {
+			dependency.Versions = []interfaces.VersionSpecifier{{Operator: "==", Value: pkg.Version}}
+		}
+		if node, ok := graph.node(pkg.Name); ok {
+			dependency.Path = node.Path
+			dependency.Groups = node.Groups
+			dependency.Markers = node.Markers()
+		}
+
+		source := pkg.Source
+		switch {
+		case source.Registry != "":
+			dependency.Index = source.Registry
+			if !containsString(u.sources, source.Registry) {
+				u.sources = append(u.sources, source.Registry)
+			}
+		case source.Git != "":
+			dependency.URL = uvGitReference(source.Git)
+		case source.URL != "":
+			dependency.URL = source.URL
+		default:
+			for _, path := range []string{source.Path, source.Directory, source.Editable, source.Virtual} {
+				if path != "" {
+					dependency.URL = path
+					if !filepath.IsAbs(path) {
+						dependency.URL = filepath.Join(filepath.Dir(fileName), path)
+					}
+					break
+				}
+			}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	depChan := make(chan interfaces.Dependency)
+
+	go func() {
+		defer close(depChan)
+		for _, dependency := range dependencies {
+			select {
+			case <-ctx.Done():
+				return
+			case depChan <- dependency:
+			}
+		}
+	}()
+
+	return depChan, nil
+}
+
+// isUvMember reports whether the package is the workspace member,
+// the single project workspace has no manifest, its root is the project itself
+func isUvMember(pkg uvPackage, members []string) bool {
+	if len(members) > 0 {
+		for _, member := range members {
+			if interfaces.NormalizeName(member) == interfaces.NormalizeName(pkg.Name) {
+				return true
+			}
+		}
+		return false
+	}
+	return pkg.Source.Editable == "." || pkg.Source.Virtual == "."
+}
+
+// uvRoots returns the direct dependencies of the workspace member with their groups
+func uvRoots(pkg uvPackage) []interfaces.Dependency {
+	var roots []interfaces.Dependency
+	add := func(dependencies []uvDependency, group string) {
+		for _, dependency := range dependencies {
+			roots = append(roots, interfaces.Dependency{
+				Name:    dependency.Name,
+				Extras:  dependency.Extra,
+				Markers: dependency.Marker,
+				Groups:  []string{group},
+			})
+		}
+	}
+	add(pkg.Dependencies, mainGroup)
+	for _, extra := range sortedUvGroups(pkg.OptionalDependencies) {
+		add(pkg.OptionalDependencies[extra], extra)
+	}
+	for _, group := range sortedUvGroups(pkg.DevDependencies) {
+		add(pkg.DevDependencies[group], group)
+	}
+	return roots
+}
+
+func uvEdges(dependencies []uvDependency) []lockEdge {
+	var edges []lockEdge
+	for _, dependency := range dependencies {
+		edges = append(edges, lockEdge{name: dependency.Name, extras: dependency.Extra, markers: dependency.Marker})
+	}
+	return edges
+}
+
+func sortedUvGroups(groups map[string][]uvDependency) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uvGitReference converts the git source of the lock to the VCS url
+// e.g. https://github.com/psf/requests?tag=v2.31.0#<commit> is git+https://github.com/psf/requests@<commit>
+func uvGitReference(source string) string {
+	parsed, err := url.Parse(source)
+	if err != nil {
+		return "git+" + source
+	}
+	reference := parsed.Fragment
+	if reference == "" {
+		query := parsed.Query()
+		for _, key := range []string{"rev", "tag", "branch"} {
+			if value := query.Get(key); value != "" {
+				reference = value
+				break
+			}
+		}
+	}
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	if reference == "" {
+		return "git+" + parsed.String()
+	}
+	return "git+" + parsed.String() + "@" + reference
+}
@@ -0,0 +1,85 @@
+package python
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUvLock(t *testing.T) {
+	uvLock := NewUvLock()
+	fileName := filepath.Join("testdata", "uv", "uv.lock")
+	dependencies, err := uvLock.GetDependencies(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type locked struct {
+		name, version, groups, path, index, url string
+	}
+	var got []locked
+	linux, err := NewEnvironment("3.11", "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var linuxPackages []string
+	for dependency := range dependencies {
+		applies, err := uvLock.EvaluateMarkers(dependency, linux, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if applies {
+			linuxPackages = append(linuxPackages, dependency.Name)
+		}
+		got = append(got, locked{
+			name:    dependency.Name,
+			version: dependency.PinnedVersion(),
+			groups:  strings.Join(dependency.Groups, ","),
+			path:    strings.Join(dependency.Path, " > "),
+			index:   dependency.Index,
+			url:     dependency.URL,
+		})
+	}
+
+	expected := []locked{
+		{name: "certifi", version: "2023.7.22", groups: "main", path: "requests", index: "https://pypi.org/simple"},
+		{name: "colorama", version: "0.4.6", groups: "dev", path: "pytest", index: "https://pypi.org/simple"},
+		{name: "internal-lib", version: "1.4.2", groups: "main", index: "https://pypi.example.com/simple"},
+		{name: "pysocks", version: "1.7.1", groups: "main", path: "requests", index: "https://pypi.org/simple"},
+		{name: "pytest", version: "7.4.3", groups: "dev", index: "https://pypi.org/simple"},
+		{name: "requests", version: "2.31.0", groups: "main", index: "https://pypi.org/simple"},
+		{name: "toolbelt", version: "0.3.0", groups: "main", url: "git+https://github.com/example/toolbelt.git@4f1c2d3e5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d"},
+		{name: "urllib3", version: "2.0.7", groups: "main", path: "requests", index: "https://pypi.org/simple"},
+		{name: "vendored", version: "0.2.0", url: filepath.Join("testdata", "uv", "vendor", "vendored-0.2.0-py3-none-any.whl")},
+	}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(locked{})); diff != "" {
+		t.Errorf("Dependencies mismatch (-want +got):\n%s", diff)
+	}
+
+	// colorama is required by pytest only on windows
+	expectedLinux := []string{"certifi", "internal-lib", "pysocks", "pytest", "requests", "toolbelt", "urllib3", "vendored"}
+	if diff := cmp.Diff(expectedLinux, linuxPackages); diff != "" {
+		t.Errorf("Linux packages mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"https://pypi.org/simple", "https://pypi.example.com/simple"}, uvLock.sources); diff != "" {
+		t.Errorf("Sources mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUvGitReference(t *testing.T) {
+	references := map[string]string{
+		"https://github.com/psf/requests?tag=v2.31.0#0123abcd": "git+https://github.com/psf/requests@0123abcd",
+		"https://github.com/psf/requests?branch=main":          "git+https://github.com/psf/requests@main",
+		"https://github.com/psf/requests?rev=0123abcd":         "git+https://github.com/psf/requests@0123abcd",
+		"https://github.com/psf/requests":                      "git+https://github.com/psf/requests",
+	}
+	for source, expected := range references {
+		if got := uvGitReference(source); got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, source, got)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Implements the PEP 440 version parsing, ordering and specifier matching
+// https://peps.python.org/pep-0440/
+package python
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+var versionPattern = regexp.MustCompile(`(?i)^\s*v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d*))?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+	`(?:[-_.]?(dev)[-_.]?(\d*))?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?\s*$`)
+
+// preReleaseRank orders the pre-release phases, the spelling variants are normalized
+var preReleaseRank = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+// The missing parts of the version are ordered using the sentinels,
+// e.g. a release without the pre-release part sorts after its pre-releases
+const (
+	sentinelLow  = -1
+	sentinelHigh = 1 << 30
+)
+
+// pythonVersion is the parsed PEP 440 version
+type pythonVersion struct {
+	epoch   int
+	release []int
+	// pre is the phase rank and number of the pre-release
+	pre   [2]int
+	post  int
+	dev   int
+	local string
+	// isPre is set for the pre-releases and development releases
+	isPre bool
+}
+
+func parseVersion(version string) (*pythonVersion, error) {
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+
+	v := &pythonVersion{
+		pre:   [2]int{sentinelHigh, 0},
+		post:  sentinelLow,
+		dev:   sentinelHigh,
+		local: strings.ToLower(match[10]),
+	}
+	v.epoch, _ = strconv.Atoi(match[1])
+	for _, part := range strings.Split(match[2], ".") {
+		number, _ := strconv.Atoi(part)
+		v.release = append(v.release, number)
+	}
+	if match[3] != "" {
+		number, _ := strconv.Atoi(match[4])
+		v.pre = [2]int{preReleaseRank[strings.ToLower(match[3])], number}
+		v.isPre = true
+	}
+	if match[5] != "" {
+		v.post, _ = strconv.Atoi(match[5])
+	} else if match[6] != "" {
+		v.post, _ = strconv.Atoi(match[7])
+	}
+	if match[8] != "" {
+		v.dev, _ = strconv.Atoi(match[9])
+		v.isPre = true
+		// the development release of the final release sorts before its pre-releases
+		if match[3] == "" && v.post == sentinelLow {
+			v.pre = [2]int{sentinelLow, 0}
+		}
+	}
+	return v, nil
+}
+
+// compare returns -1, 0 or 1 when the version is lower, equal or higher
+// than the other one, the local version label is ignored
+func (v *pythonVersion) compare(other *pythonVersion) int {
+	if c := compareInts([]int{v.epoch}, []int{other.epoch}); c != 0 {
+		return c
+	}
+	if c := compareInts(v.release, other.release); c != 0 {
+		return c
+	}
+	if c := compareInts(v.pre[:], other.pre[:]); c != 0 {
+		return c
+	}
+	if c := compareInts([]int{v.post}, []int{other.post}); c != 0 {
+		return c
+	}
+	return compareInts([]int{v.dev}, []int{other.dev})
+}
+
+// compareInts compares the number sequences, the shorter one is padded with zeros
+func compareInts(a []int, b []int) int {
+	length := len(a)
+	if len(b) > length {
+		length = len(b)
+	}
+	for i := 0; i < length; i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// matchesSpecifier checks whether the version satisfies the version specifier
+func matchesSpecifier(version string, specifier interfaces.VersionSpecifier) (bool, error) {
+	if specifier.Operator == "===" {
+		return strings.EqualFold(strings.TrimSpace(version), strings.TrimSpace(specifier.Value)), nil
+	}
+
+	v, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	value := strings.TrimSpace(specifier.Value)
+	if strings.HasSuffix(value, ".*") {
+		prefix, err := parseVersion(strings.TrimSuffix(value, ".*"))
+		if err != nil {
+			return false, err
+		}
+		matches := v.epoch == prefix.epoch && compareInts(padRelease(v.release, len(prefix.release)), prefix.release) == 0
+		switch specifier.Operator {
+		case "==":
+			return matches, nil
+		case "!=":
+			return !matches, nil
+		}
+		return false, fmt.Errorf("invalid specifier %s%s", specifier.Operator, specifier.Value)
+	}
+
+	s, err := parseVersion(value)
+	if err != nil {
+		return false, err
+	}
+	c := v.compare(s)
+	// the local version label is compared only when the specifier has one
+	if c == 0 && s.local != "" && v.local != s.local {
+		c = 1
+	}
+
+	switch specifier.Operator {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "~=":
+		// ~=1.4.5 is the same as >=1.4.5, ==1.4.*
+		if len(s.release) < 2 {
+			return false, fmt.Errorf("invalid specifier ~=%s", specifier.Value)
+		}
+		prefix := s.release[:len(s.release)-1]
+		return c >= 0 && v.epoch == s.epoch && compareInts(padRelease(v.release, len(prefix)), prefix) == 0, nil
+	}
+	return false, fmt.Errorf("invalid specifier operator %s", specifier.Operator)
+}
+
+// padRelease returns the first length parts of the release padded with zeros
+func padRelease(release []int, length int) []int {
+	padded := make([]int, length)
+	copy(padded, release)
+	return padded
+}
+
+// matchesSpecifiers checks whether the version satisfies all specifiers
+func matchesSpecifiers(version string, specifiers []interfaces.VersionSpecifier) bool {
+	for _, specifier := range specifiers {
+		if ok, err := matchesSpecifier(version, specifier); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// bestVersion returns the highest version satisfying all specifiers
+// the pre-releases are used only when no final release matches
+// empty string is returned when no version matches
+func bestVersion(versions []string, specifiers []interfaces.VersionSpecifier) string {
+	var best, bestPre string
+	var bestVersion, bestPreVersion *pythonVersion
+	for _, version := range versions {
+		v, err := parseVersion(version)
+		if err != nil || !matchesSpecifiers(version, specifiers) {
+			continue
+		}
+		if v.isPre {
+			if bestPreVersion == nil || v.compare(bestPreVersion) > 0 {
+				bestPre, bestPreVersion = version, v
+			}
+			continue
+		}
+		if bestVersion == nil || v.compare(bestVersion) > 0 {
+			best, bestVersion = version, v
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return bestPre
+}
@@ -0,0 +1,92 @@
+package python
+
+import (
+	"testing"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestParseVersionOrder(t *testing.T) {
+	// the versions are in the ascending order
+	versions := []string{
+		"1.0.dev1", "1.0a1", "1.0a2.dev1", "1.0a2", "1.0b1", "1.0rc1",
+		"1.0", "1.0.post1.dev1", "1.0.post1", "1.1", "1.10", "1!0.1",
+	}
+	for i := 1; i < len(versions); i++ {
+		lower, err := parseVersion(versions[i-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		higher, err := parseVersion(versions[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lower.compare(higher) >= 0 {
+			t.Errorf("Expected %s to be lower than %s", versions[i-1], versions[i])
+		}
+	}
+
+	same := [][2]string{{"1.0", "1.0.0"}, {"1.0RC1", "1.0c1"}, {"v1.0-1", "1.0.post1"}, {"1.0+local", "1.0"}}
+	for _, pair := range same {
+		a, _ := parseVersion(pair[0])
+		b, _ := parseVersion(pair[1])
+		if a.compare(b) != 0 {
+			t.Errorf("Expected %s to equal %s", pair[0], pair[1])
+		}
+	}
+
+	if _, err := parseVersion("not a version"); err == nil {
+		t.Error("Expected invalid version error")
+	}
+}
+
+func TestMatchesSpecifier(t *testing.T) {
+	tests := []struct {
+		version   string
+		specifier interfaces.VersionSpecifier
+		expected  bool
+	}{
+		{"1.4.5", interfaces.VersionSpecifier{Operator: "==", Value: "1.4.5"}, true},
+		{"1.4.5", interfaces.VersionSpecifier{Operator: "==", Value: "1.4.*"}, true},
+		{"1.5.0", interfaces.VersionSpecifier{Operator: "==", Value: "1.4.*"}, false},
+		{"1.5.0", interfaces.VersionSpecifier{Operator: "!=", Value: "1.4.*"}, true},
+		{"1.4.5", interfaces.VersionSpecifier{Operator: "~=", Value: "1.4.2"}, true},
+		{"1.5.0", interfaces.VersionSpecifier{Operator: "~=", Value: "1.4.2"}, false},
+		{"1.9", interfaces.VersionSpecifier{Operator: "~=", Value: "1.4"}, true},
+		{"2.0", interfaces.VersionSpecifier{Operator: "~=", Value: "1.4"}, false},
+		{"1.10", interfaces.VersionSpecifier{Operator: ">", Value: "1.9"}, true},
+		{"1.0+local", interfaces.VersionSpecifier{Operator: "==", Value: "1.0"}, true},
+		{"1.0", interfaces.VersionSpecifier{Operator: "==", Value: "1.0+local"}, false},
+		{"1.0", interfaces.VersionSpecifier{Operator: "<=", Value: "1.0"}, true},
+		{"1.0", interfaces.VersionSpecifier{Operator: "===", Value: "1.0"}, true},
+		{"1.0.0", interfaces.VersionSpecifier{Operator: "===", Value: "1.0"}, false},
+	}
+	for _, test := range tests {
+		matches, err := matchesSpecifier(test.version, test.specifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matches != test.expected {
+			t.Errorf("Expected %s %s%s to be %t", test.version, test.specifier.Operator, test.specifier.Value, test.expected)
+		}
+	}
+}
+
+func TestBestVersion(t *testing.T) {
+	versions := []string{"1.0", "1.2", "1.10", "2.0rc1", "invalid"}
+	tests := []struct {
+		specifiers []interfaces.VersionSpecifier
+		expected   string
+	}{
+		{nil, "1.10"},
+		{[]interfaces.VersionSpecifier{{Operator: "<", Value: "1.10"}}, "1.2"},
+		// the pre-release is used only when no final release matches
+		{[]interfaces.VersionSpecifier{{Operator: ">=", Value: "2.0a1"}}, "2.0rc1"},
+		{[]interfaces.VersionSpecifier{{Operator: ">", Value: "3"}}, ""},
+	}
+	for _, test := range tests {
+		if best := bestVersion(versions, test.specifiers); best != test.expected {
+			t.Errorf("Expected %q for %v, got %q", test.expected, test.specifiers, best)
+		}
+	}
+}
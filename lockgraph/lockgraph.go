@@ -0,0 +1,204 @@
+// Walks the dependency graph recorded by the lockfiles
+// The lockfiles pin every package and list the packages it requires, so the transitive
+// packages, the shortest path to them, their groups and the markers they apply to
+// are known without resolving the dependencies again
+// The packages are identified by the ids chosen by the lockfile (e.g. name@version)
+package lockgraph
+
+import (
+	"sort"
+	"strings"
+)
+
+// Package is the locked package with the edges to the packages it requires
+type Package struct {
+	Name         string
+	Dependencies []Edge
+	// Optional are the edges required only by the extras of the package keyed by the extra
+	Optional map[string][]Edge
+}
+
+// Edge is the requirement of the locked package
+type Edge struct {
+	ID     string
+	Extras []string
+	// Markers are the PEP 508 environment markers the requirement applies to
+	Markers string
+}
+
+// Root is the package required by the project itself
+type Root struct {
+	ID      string
+	Groups  []string
+	Extras  []string
+	Markers string
+}
+
+// Node is the package reached from the roots
+type Node struct {
+	// Path is the shortest path from the root, empty for the roots
+	Path []string
+	// Groups are the groups of all roots pulling the package in
+	Groups []string
+	Extras []string
+	// conditions are the sets of the markers along the paths the package is reached by,
+	// the empty set means the package is always required
+	conditions [][]string
+}
+
+// Graph is the dependency graph of the locked packages keyed by the id
+type Graph struct {
+	packages map[string]Package
+	nodes    map[string]*Node
+}
+
+// New creates the graph of the packages keyed by the id
+func New(packages map[string]Package) *Graph {
+	return &Graph{packages: packages, nodes: map[string]*Node{}}
+}
+
+// Node returns the package, ok is false when the package is not reachable from the roots
+func (g *Graph) Node(id string) (*Node, bool) {
+	node, ok := g.nodes[id]
+	return node, ok
+}
+
+// Walk visits the packages reachable from the roots breadth first
+// The package is visited again when it is reached with a new group, extra or markers,
+// so its requirements get them as well
+func (g *Graph) Walk(roots []Root) {
+	type visit struct {
+		id         string
+		path       []string
+		groups     []string
+		extras     []string
+		conditions []string
+	}
+	var queue []visit
+	for _, root := range roots {
+		queue = append(queue, visit{
+			id:         root.ID,
+			groups:     root.Groups,
+			extras:     root.Extras,
+			conditions: addMarkers(nil, root.Markers),
+		})
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		pkg, ok := g.packages[current.id]
+		if !ok {
+			continue
+		}
+
+		node, ok := g.nodes[current.id]
+		if !ok {
+			node = &Node{Path: current.path}
+			g.nodes[current.id] = node
+		}
+		changed := node.addCondition(current.conditions)
+		for _, group := range current.groups {
+			if !contains(node.Groups, group) {
+				node.Groups = append(node.Groups, group)
+				changed = true
+			}
+		}
+		for _, extra := range current.extras {
+			if !contains(node.Extras, extra) {
+				node.Extras = append(node.Extras, extra)
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		path := append(append([]string{}, current.path...), pkg.Name)
+		groups := append([]string{}, node.Groups...)
+		edges := pkg.Dependencies
+		for _, extra := range node.Extras {
+			edges = append(append([]Edge{}, edges...), pkg.Optional[extra]...)
+		}
+		for _, edge := range edges {
+			queue = append(queue, visit{
+				id:         edge.ID,
+				path:       path,
+				groups:     groups,
+				extras:     edge.Extras,
+				conditions: addMarkers(current.conditions, edge.Markers),
+			})
+		}
+	}
+}
+
+// Markers returns the markers the package is required with, the paths are joined by or
+// and the markers along a single path by and, empty when the package is always required
+func (n *Node) Markers() string {
+	var alternatives []string
+	for _, condition := range n.conditions {
+		if len(condition) == 0 {
+			return ""
+		}
+		alternatives = append(alternatives, joinMarkers(condition, "and"))
+	}
+	return joinMarkers(alternatives, "or")
+}
+
+// addCondition adds the markers of the path, ok is false when the path
+// with the subset of the markers is already known, so the path adds nothing
+func (n *Node) addCondition(condition []string) bool {
+	var conditions [][]string
+	for _, known := range n.conditions {
+		if isSubset(known, condition) {
+			return false
+		}
+		if !isSubset(condition, known) {
+			conditions = append(conditions, known)
+		}
+	}
+	n.conditions = append(conditions, condition)
+	return true
+}
+
+// addMarkers returns the sorted set of the markers extended by the markers of the requirement
+func addMarkers(conditions []string, markers string) []string {
+	markers = strings.TrimSpace(markers)
+	if markers == "" || contains(conditions, markers) {
+		return conditions
+	}
+	extended := append(append([]string{}, conditions...), markers)
+	sort.Strings(extended)
+	return extended
+}
+
+// joinMarkers joins the markers by the operator, the markers are parenthesized,
+// so the operators inside them keep their precedence
+func joinMarkers(markers []string, operator string) string {
+	if len(markers) == 1 {
+		return markers[0]
+	}
+	parenthesized := make([]string, 0, len(markers))
+	for _, marker := range markers {
+		parenthesized = append(parenthesized, "("+marker+")")
+	}
+	return strings.Join(parenthesized, " "+operator+" ")
+}
+
+func isSubset(subset []string, set []string) bool {
+	for _, value := range subset {
+		if !contains(set, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
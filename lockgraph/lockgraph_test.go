@@ -0,0 +1,71 @@
+package lockgraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	graph := New(map[string]Package{
+		"app":     {Name: "app", Dependencies: []Edge{{ID: "lib"}}},
+		"lib":     {Name: "lib", Optional: map[string][]Edge{"socks": {{ID: "pysocks"}}}},
+		"pysocks": {Name: "pysocks"},
+		"tool":    {Name: "tool", Dependencies: []Edge{{ID: "lib", Extras: []string{"socks"}}}},
+	})
+	graph.Walk([]Root{
+		{ID: "app", Groups: []string{"main"}},
+		{ID: "tool", Groups: []string{"dev"}},
+		{ID: "missing", Groups: []string{"main"}},
+	})
+
+	expected := map[string]Node{
+		"app":     {Groups: []string{"main"}},
+		"lib":     {Path: []string{"app"}, Groups: []string{"main", "dev"}, Extras: []string{"socks"}},
+		"pysocks": {Path: []string{"tool", "lib"}, Groups: []string{"main", "dev"}},
+	}
+	for id, node := range expected {
+		actual, ok := graph.Node(id)
+		if !ok {
+			t.Fatalf("Expected %s to be reachable", id)
+		}
+		if !reflect.DeepEqual(actual.Path, node.Path) || !reflect.DeepEqual(actual.Groups, node.Groups) ||
+			!reflect.DeepEqual(actual.Extras, node.Extras) {
+			t.Errorf("%s: expected %+v, got %+v", id, node, *actual)
+		}
+	}
+	if _, ok := graph.Node("missing"); ok {
+		t.Error("Expected the package missing in the lock to be unreachable")
+	}
+}
+
+func TestWalkMarkers(t *testing.T) {
+	graph := New(map[string]Package{
+		"app": {Name: "app", Dependencies: []Edge{
+			{ID: "colorama", Markers: `sys_platform == "win32"`},
+			{ID: "click", Markers: `python_version < "3.12"`},
+		}},
+		"click":    {Name: "click", Dependencies: []Edge{{ID: "colorama", Markers: `platform_system == "Windows"`}}},
+		"colorama": {Name: "colorama"},
+		"tool":     {Name: "tool", Dependencies: []Edge{{ID: "click"}}},
+	})
+	graph.Walk([]Root{
+		{ID: "app", Groups: []string{"main"}},
+		{ID: "tool", Groups: []string{"dev"}},
+	})
+
+	expected := map[string]string{
+		"app": "",
+		// the path through tool has no markers
+		"click":    "",
+		"colorama": `(sys_platform == "win32") or (platform_system == "Windows")`,
+	}
+	for id, markers := range expected {
+		node, ok := graph.Node(id)
+		if !ok {
+			t.Fatalf("Expected %s to be reachable", id)
+		}
+		if node.Markers() != markers {
+			t.Errorf("%s: expected markers %q, got %q", id, markers, node.Markers())
+		}
+	}
+}
@@ -3,20 +3,65 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/radiculaCZ/license-check/core"
 	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/languages/conda"
+	"github.com/radiculaCZ/license-check/languages/golang"
+	"github.com/radiculaCZ/license-check/languages/javascript"
 	"github.com/radiculaCZ/license-check/languages/python"
+	"github.com/radiculaCZ/license-check/policy"
 	"github.com/radiculaCZ/license-check/results"
 	"github.com/urfave/cli/v2"
 )
 
+// Exit codes of the check command, when more verdicts apply
+// the most severe one is used
+const (
+	exitDenied  = 2
+	exitUnknown = 3
+	exitReview  = 4
+)
+
+// developmentGroups are the dependency groups left out by the --exclude-dev flag
+var developmentGroups = []string{"dev", python.PipfileDevelopGroup}
+
 func main() {
 	// Register all depfiles
 	requrementsTxt := python.NewRequirementsTxt()
+	sitePackages := python.NewSitePackages()
+	pyprojectToml := python.NewPyprojectToml()
+	poetryLock := python.NewPoetryLock()
+	pipfileLock := python.NewPipfileLock()
+	uvLock := python.NewUvLock()
+	pdmLock := python.NewPdmLock()
+	environmentYml := conda.NewEnvironmentYml()
+	condaLock := conda.NewCondaLock()
+	packageLock := javascript.NewPackageLock()
+	npmShrinkwrap := javascript.NewNpmShrinkwrap()
+	yarnLock := javascript.NewYarnLock()
+	pnpmLock := javascript.NewPnpmLock()
+	goMod := golang.NewGoMod()
+	vendorModules := golang.NewVendorModules()
 
 	depFiles := map[string]interfaces.DepFile{
 		requrementsTxt.GetDepFileType(): requrementsTxt,
+		sitePackages.GetDepFileType():   sitePackages,
+		pyprojectToml.GetDepFileType():  pyprojectToml,
+		poetryLock.GetDepFileType():     poetryLock,
+		pipfileLock.GetDepFileType():    pipfileLock,
+		uvLock.GetDepFileType():         uvLock,
+		pdmLock.GetDepFileType():        pdmLock,
+		environmentYml.GetDepFileType(): environmentYml,
+		condaLock.GetDepFileType():      condaLock,
+		packageLock.GetDepFileType():    packageLock,
+		npmShrinkwrap.GetDepFileType():  npmShrinkwrap,
+		yarnLock.GetDepFileType():       yarnLock,
+		pnpmLock.GetDepFileType():       pnpmLock,
+		goMod.GetDepFileType():          goMod,
+		vendorModules.GetDepFileType():  vendorModules,
 	}
 	// End of registering depfiles
 
@@ -78,7 +123,7 @@ func main() {
 			&cli.PathFlag{
 				Name:      "file",
 				Aliases:   []string{"f"},
-				Usage:     "Path to the dependency file",
+				Usage:     "Path to the dependency file, or the directory for the installed environments",
 				Required:  true,
 				TakesFile: true,
 				Action: func(c *cli.Context, p cli.Path) error {
@@ -90,21 +135,184 @@ func main() {
 			},
 			typeFlag,
 			resultFlag,
+			&cli.IntFlag{
+				Name:    "concurrency",
+				Aliases: []string{"c"},
+				Usage:   "Number of packages downloaded in parallel",
+				Value:   core.DefaultConcurrency,
+				Action: func(c *cli.Context, v int) error {
+					if v < 1 {
+						return fmt.Errorf("Invalid concurrency %d", v)
+					}
+					return nil
+				},
+			},
+			&cli.BoolFlag{
+				Name:  "fail-on-error",
+				Usage: "Exit with non-zero code when some packages could not be resolved",
+			},
+			&cli.BoolFlag{
+				Name:  "resolve",
+				Usage: "Check the transitive dependencies of the packages as well",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude-group",
+				Usage: "Dependency group left out of the check (e.g. dev), can be used multiple times",
+			},
+			&cli.StringSliceFlag{
+				Name:  "project",
+				Usage: "Workspace project the dependencies are checked for (e.g. packages/app), can be used multiple times",
+			},
+			&cli.BoolFlag{
+				Name:  "exclude-dev",
+				Usage: "Leave out the development dependencies (the " + strings.Join(developmentGroups, " and ") + " groups)",
+			},
+			&cli.StringSliceFlag{
+				Name: "python-version",
+				Usage: "Python version the environment markers are evaluated for, can be used multiple times " +
+					"to check the union of the targets",
+				Value: cli.NewStringSlice(python.DefaultPythonVersion),
+			},
+			&cli.StringSliceFlag{
+				Name: "platform",
+				Usage: "Platform the environment markers are evaluated for, one of " + strings.Join(python.Platforms(), ", ") +
+					", can be used multiple times to check the union of the targets",
+				Value: cli.NewStringSlice(python.DefaultPlatform),
+			},
+			&cli.StringSliceFlag{
+				Name:  "extra",
+				Usage: "Extra of the project the environment markers are evaluated with, can be used multiple times",
+			},
 		},
 		Action: func(c *cli.Context) error {
-			packages, err := core.DownloadDependencyInfo(depFiles[c.String("type")], c.Path("file"))
+			packages, pkgErrors, err := downloadDependencyInfo(c, depFiles[c.String("type")])
 			if err != nil {
 				return err
 			}
 
-			result := core.ProcessDependencyInfo(results[c.String("result")], packages)
+			result := core.ProcessDependencyInfo(results[c.String("result")], packages, pkgErrors, nil)
 			fmt.Println(result)
 
-			return nil
+			return failOnError(c, pkgErrors)
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "check",
+				Usage:     "Check the licenses of dependencies against the license policy",
+				UsageText: "license-check --file <file> --type <type> --result <result> check --policy <policy>",
+				Description: fmt.Sprintf("Exits with code %d when some license is denied, %d when some license is unknown "+
+					"and %d when some license needs review", exitDenied, exitUnknown, exitReview),
+				Flags: []cli.Flag{
+					&cli.PathFlag{
+						Name:      "policy",
+						Aliases:   []string{"p"},
+						Usage:     "Path to the license policy file",
+						Required:  true,
+						TakesFile: true,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					licensePolicy, err := policy.LoadPolicy(c.Path("policy"))
+					if err != nil {
+						return err
+					}
+
+					packages, pkgErrors, err := downloadDependencyInfo(c, depFiles[c.String("type")])
+					if err != nil {
+						return err
+					}
+
+					// the packages that could not be resolved have the unknown license
+					now := time.Now()
+					verdicts := append(licensePolicy.Evaluate(packages, now), licensePolicy.EvaluateErrors(pkgErrors, now)...)
+
+					result := core.ProcessDependencyInfo(results[c.String("result")], packages, pkgErrors, verdicts)
+					fmt.Println(result)
+
+					if code := verdictExitCode(verdicts); code != 0 {
+						return cli.Exit("license policy check failed", code)
+					}
+
+					return failOnError(c, pkgErrors)
+				},
+			},
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// downloadDependencyInfo downloads the packages of the depfile passed in the flags
+// it is shared by the default action and the commands
+func downloadDependencyInfo(c *cli.Context, depFile interfaces.DepFile) ([]interfaces.PackageMeta, []interfaces.PackageError, error) {
+	options := core.Options{
+		Concurrency:   c.Int("concurrency"),
+		Resolve:       c.Bool("resolve"),
+		ExcludeGroups: c.StringSlice("exclude-group"),
+		Projects:      c.StringSlice("project"),
+	}
+	if c.Bool("exclude-dev") {
+		options.ExcludeGroups = append(options.ExcludeGroups, developmentGroups...)
+	}
+	// the markers are evaluated for the resolved dependencies or when some target is requested
+	if options.Resolve || c.IsSet("python-version") || c.IsSet("platform") || c.IsSet("extra") {
+		targets, err := newTargets(c.StringSlice("python-version"), c.StringSlice("platform"))
+		if err != nil {
+			return nil, nil, err
+		}
+		options.Targets = targets
+		options.Extras = c.StringSlice("extra")
+	}
+	return core.DownloadDependencyInfo(c.Context, depFile, c.Path("file"), options)
+}
+
+// newTargets returns the targets of all combinations of the python versions and the platforms
+func newTargets(pythonVersions []string, platforms []string) ([]core.Target, error) {
+	var targets []core.Target
+	for _, pythonVersion := range pythonVersions {
+		for _, platform := range platforms {
+			environment, err := python.NewEnvironment(pythonVersion, platform)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, core.Target{Name: pythonVersion + "/" + platform, Environment: environment})
+		}
+	}
+	return targets, nil
+}
+
+// failOnError returns the exit error when some packages could not be resolved
+// and the --fail-on-error flag is set
+func failOnError(c *cli.Context, pkgErrors []interfaces.PackageError) error {
+	if len(pkgErrors) > 0 && c.Bool("fail-on-error") {
+		return cli.Exit(fmt.Sprintf("%d packages could not be resolved", len(pkgErrors)), 1)
+	}
+	return nil
+}
+
+// verdictExitCode returns the exit code of the most severe verdict
+// or 0 when all packages are allowed
+func verdictExitCode(verdicts []interfaces.Verdict) int {
+	severity := map[string]int{
+		interfaces.VerdictDenied:  exitDenied,
+		interfaces.VerdictUnknown: exitUnknown,
+		interfaces.VerdictReview:  exitReview,
+	}
+	order := []int{exitDenied, exitUnknown, exitReview}
+
+	found := map[int]bool{}
+	for _, verdict := range verdicts {
+		if code, ok := severity[verdict.Status]; ok {
+			found[code] = true
+		}
+	}
+	for _, code := range order {
+		if found[code] {
+			return code
+		}
 	}
+	return 0
 }
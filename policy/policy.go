@@ -0,0 +1,245 @@
+// The policy package evaluates the resolved packages against the license policy
+// The policy is a JSON file in the following format
+//
+//	{
+//		"allowed": ["MIT", "Apache-2.0", "BSD License"],
+//		"denied": ["GPL-3.0-only"],
+//		"review": ["MPL-2.0"],
+//		"exceptions": [
+//			{
+//				"package": "chardet",
+//				"justification": "Used only in the test suite",
+//				"expires": "2024-12-31"
+//			}
+//		]
+//	}
+//
+// The licenses are matched case insensitively, either by the license name
+// or by the SPDX identifier, so "MIT" in the policy matches "MIT License" as well
+// SPDX license expressions are evaluated per license, the OR expression
+// gets the best verdict of its licenses, the AND expression the worst one
+// Packages declaring several licenses are evaluated as the OR expression
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+	"github.com/radiculaCZ/license-check/spdx"
+)
+
+// ExpiryLayout is the date format of the exception expiry
+const ExpiryLayout = "2006-01-02"
+
+type Policy struct {
+	Allowed    []string    `json:"allowed"`
+	Denied     []string    `json:"denied"`
+	Review     []string    `json:"review"`
+	Exceptions []Exception `json:"exceptions"`
+}
+
+// Exception allows a package regardless of its license until the expiry date
+type Exception struct {
+	Package       string `json:"package"`
+	Justification string `json:"justification"`
+	// Expires is the last day the exception is valid, in the ExpiryLayout format
+	Expires string `json:"expires"`
+	expires time.Time
+}
+
+// LoadPolicy reads and validates the policy file
+func LoadPolicy(fileName string) (*Policy, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", fileName, err)
+	}
+
+	for i, exception := range policy.Exceptions {
+		if exception.Package == "" {
+			return nil, fmt.Errorf("invalid policy %s: exception %d has no package", fileName, i)
+		}
+		if exception.Justification == "" {
+			return nil, fmt.Errorf("invalid policy %s: exception for %s has no justification", fileName, exception.Package)
+		}
+		expires, err := time.Parse(ExpiryLayout, exception.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy %s: exception for %s has invalid expiry: %w", fileName, exception.Package, err)
+		}
+		policy.Exceptions[i].expires = expires
+	}
+
+	return &policy, nil
+}
+
+// Evaluate returns the verdict for every package
+// The valid exception takes precedence, otherwise the denied licenses
+// are checked first, then the review and allowed ones
+// Packages with a license not listed in the policy are unknown
+// The now is used to check the expiry of the exceptions
+func (p *Policy) Evaluate(packages []interfaces.PackageMeta, now time.Time) []interfaces.Verdict {
+	verdicts := make([]interfaces.Verdict, 0, len(packages))
+	for _, pkg := range packages {
+		verdicts = append(verdicts, p.evaluatePackage(pkg, now))
+	}
+	return verdicts
+}
+
+// EvaluateErrors returns the verdict for every package that could not be resolved,
+// its license is unknown unless the package has a valid exception
+func (p *Policy) EvaluateErrors(pkgErrors []interfaces.PackageError, now time.Time) []interfaces.Verdict {
+	verdicts := make([]interfaces.Verdict, 0, len(pkgErrors))
+	for _, pkgError := range pkgErrors {
+		verdict := interfaces.Verdict{
+			Package: pkgError.Package,
+			Status:  interfaces.VerdictUnknown,
+			Reason:  "license not resolved: " + pkgError.Stage,
+		}
+		exception, expired := p.exception(pkgError.Package, now)
+		if exception != nil {
+			verdict.Status = interfaces.VerdictAllowed
+			verdict.Reason = "exception: " + exception.Justification
+		} else if expired {
+			verdict.Reason += ", exception expired"
+		}
+		verdicts = append(verdicts, verdict)
+	}
+	return verdicts
+}
+
+// exception returns the valid exception of the package, expired is set
+// when the package has only the expired exceptions
+// The package names are compared after the normalization, e.g. Foo_Bar is foo-bar
+func (p *Policy) exception(name string, now time.Time) (*Exception, bool) {
+	expired := false
+	for i, exception := range p.Exceptions {
+		if interfaces.NormalizeName(exception.Package) != interfaces.NormalizeName(name) {
+			continue
+		}
+		// the exception is valid for the whole expiry day
+		if now.Before(exception.expires.AddDate(0, 0, 1)) {
+			return &p.Exceptions[i], false
+		}
+		expired = true
+	}
+	return nil, expired
+}
+
+func (p *Policy) evaluatePackage(pkg interfaces.PackageMeta, now time.Time) interfaces.Verdict {
+	verdict := interfaces.Verdict{
+		Package: pkg.Name,
+		Version: pkg.Version,
+		License: pkg.License,
+	}
+	if pkg.LicenseSPDX != "" {
+		verdict.License = pkg.LicenseSPDX
+	}
+
+	exception, expiredException := p.exception(pkg.Name, now)
+	if exception != nil {
+		verdict.Status = interfaces.VerdictAllowed
+		verdict.Reason = "exception: " + exception.Justification
+		return verdict
+	}
+
+	if pkg.License == "" && pkg.LicenseSPDX == "" {
+		verdict.Status = interfaces.VerdictUnknown
+		verdict.Reason = "license not found"
+	} else if expression, err := spdx.Parse(pkg.LicenseSPDX); pkg.LicenseSPDX != "" && err == nil {
+		verdict.Status = p.evaluateExpression(expression)
+	} else if len(pkg.Licenses) > 1 {
+		// the package can be used under any of its licenses
+		verdict.Status = interfaces.VerdictDenied
+		for _, license := range pkg.Licenses {
+			verdict.Status = better(verdict.Status, p.evaluateLicense(license))
+		}
+	} else {
+		verdict.Status = p.evaluateLicense(pkg.License)
+	}
+	if verdict.Status == interfaces.VerdictUnknown && verdict.Reason == "" {
+		verdict.Reason = "license not in policy"
+	}
+
+	if expiredException && verdict.Status != interfaces.VerdictAllowed {
+		verdict.Reason = strings.TrimPrefix(verdict.Reason+", exception expired", ", ")
+	}
+
+	return verdict
+}
+
+// severity orders the verdicts from the best to the worst one
+var severity = map[string]int{
+	interfaces.VerdictAllowed: 0,
+	interfaces.VerdictReview:  1,
+	interfaces.VerdictUnknown: 2,
+	interfaces.VerdictDenied:  3,
+}
+
+// better returns the verdict with the lower severity
+func better(status string, other string) string {
+	if severity[other] < severity[status] {
+		return other
+	}
+	return status
+}
+
+// evaluateExpression returns the verdict of the SPDX license expression
+// the OR expression passes if any of its branches passes,
+// the AND expression passes only if all its branches pass
+func (p *Policy) evaluateExpression(expression spdx.Expression) string {
+	switch e := expression.(type) {
+	case *spdx.Or:
+		status := interfaces.VerdictDenied
+		for _, branch := range e.Expressions {
+			status = better(status, p.evaluateExpression(branch))
+		}
+		return status
+	case *spdx.And:
+		status := interfaces.VerdictAllowed
+		for _, branch := range e.Expressions {
+			if branchStatus := p.evaluateExpression(branch); severity[branchStatus] > severity[status] {
+				status = branchStatus
+			}
+		}
+		return status
+	default:
+		return p.evaluateLicense(expression.String())
+	}
+}
+
+// evaluateLicense returns the verdict of a single license
+// the denied licenses are checked first, then the review and allowed ones
+func (p *Policy) evaluateLicense(license string) string {
+	switch {
+	case containsLicense(p.Denied, license):
+		return interfaces.VerdictDenied
+	case containsLicense(p.Review, license):
+		return interfaces.VerdictReview
+	case containsLicense(p.Allowed, license):
+		return interfaces.VerdictAllowed
+	}
+	return interfaces.VerdictUnknown
+}
+
+// containsLicense checks whether the license is in the list
+// the list items are compared with the license name and the SPDX identifier
+func containsLicense(licenses []string, license string) bool {
+	id := spdx.Normalize(license)
+	for _, l := range licenses {
+		if strings.EqualFold(l, license) {
+			return true
+		}
+		if id != "" && strings.EqualFold(spdx.Normalize(l), id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+func TestEvaluate(t *testing.T) {
+	policy, err := LoadPolicy("testdata/policy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packages := []interfaces.PackageMeta{
+		{Name: "requests", License: "mit license"},
+		{Name: "gpl-package", License: "GNU General Public License v3 (GPLv3)"},
+		{Name: "certifi", License: "Mozilla Public License 2.0 (MPL 2.0)"},
+		{Name: "no-license", License: ""},
+		{Name: "other", License: "Proprietary"},
+		{Name: "chardet", License: "GNU Lesser General Public License v2 or later (LGPLv2+)"},
+		{Name: "PyQt5", License: "GNU General Public License v3 (GPLv3)"},
+	}
+
+	expected := []string{
+		interfaces.VerdictAllowed,
+		interfaces.VerdictDenied,
+		interfaces.VerdictReview,
+		interfaces.VerdictUnknown,
+		interfaces.VerdictUnknown,
+		interfaces.VerdictAllowed,
+		interfaces.VerdictDenied,
+	}
+
+	now := time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)
+	verdicts := policy.Evaluate(packages, now)
+	if len(verdicts) != len(expected) {
+		t.Fatalf("Expected %d verdicts, got %d", len(expected), len(verdicts))
+	}
+	for i, verdict := range verdicts {
+		if verdict.Package != packages[i].Name {
+			t.Errorf("Expected package %s, got %s", packages[i].Name, verdict.Package)
+		}
+		if verdict.Status != expected[i] {
+			t.Errorf("Expected %s for %s, got %s", expected[i], verdict.Package, verdict.Status)
+		}
+	}
+
+	if verdicts[5].Reason != "exception: Used only in the test suite" {
+		t.Errorf("Expected exception justification, got %s", verdicts[5].Reason)
+	}
+	if verdicts[6].Reason != "exception expired" {
+		t.Errorf("Expected expired exception, got %s", verdicts[6].Reason)
+	}
+
+	// the day after the expiry the exception does not apply anymore
+	verdicts = policy.Evaluate(packages[5:6], now.AddDate(0, 0, 1))
+	if verdicts[0].Status != interfaces.VerdictUnknown {
+		t.Errorf("Expected expired exception to be unknown, got %s", verdicts[0].Status)
+	}
+}
+
+func TestLoadPolicyInvalidException(t *testing.T) {
+	policies := map[string]string{
+		"no package":       `{"exceptions": [{"justification": "x", "expires": "2023-01-01"}]}`,
+		"no justification": `{"exceptions": [{"package": "x", "expires": "2023-01-01"}]}`,
+		"invalid expiry":   `{"exceptions": [{"package": "x", "justification": "x", "expires": "01/01/2023"}]}`,
+	}
+	for name, data := range policies {
+		fileName := filepath.Join(t.TempDir(), "policy.json")
+		if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(fileName); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestEvaluateSPDX(t *testing.T) {
+	policy := &Policy{
+		Allowed: []string{"MIT", "Apache 2.0"},
+		Denied:  []string{"GPL-3.0-or-later"},
+	}
+
+	packages := []interfaces.PackageMeta{
+		{Name: "requests", License: "Apache Software License", LicenseSPDX: "Apache-2.0"},
+		{Name: "six", License: "MIT License", LicenseSPDX: "MIT"},
+		{Name: "gpl-package", License: "GNU General Public License v3 or later (GPLv3+)", LicenseSPDX: "GPL-3.0-or-later"},
+	}
+	expected := []string{interfaces.VerdictAllowed, interfaces.VerdictAllowed, interfaces.VerdictDenied}
+
+	for i, verdict := range policy.Evaluate(packages, time.Now()) {
+		if verdict.Status != expected[i] {
+			t.Errorf("Expected %s for %s, got %s", expected[i], verdict.Package, verdict.Status)
+		}
+		if verdict.License != packages[i].LicenseSPDX {
+			t.Errorf("Expected license %s, got %s", packages[i].LicenseSPDX, verdict.License)
+		}
+	}
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	policy := &Policy{
+		Allowed: []string{"MIT", "Apache-2.0", "GPL-2.0-only WITH Classpath-exception-2.0"},
+		Denied:  []string{"GPL-3.0-only"},
+		Review:  []string{"MPL-2.0"},
+	}
+
+	expressions := map[string]string{
+		"MIT OR GPL-3.0-only":                       interfaces.VerdictAllowed,
+		"MIT AND GPL-3.0-only":                      interfaces.VerdictDenied,
+		"MIT AND MPL-2.0":                           interfaces.VerdictReview,
+		"GPL-3.0-only OR MPL-2.0":                   interfaces.VerdictReview,
+		"MIT AND (Apache-2.0 OR GPL-3.0-only)":      interfaces.VerdictAllowed,
+		"MIT AND LGPL-2.1-only":                     interfaces.VerdictUnknown,
+		"GPL-2.0-only WITH Classpath-exception-2.0": interfaces.VerdictAllowed,
+		"GPL-2.0-only":                              interfaces.VerdictUnknown,
+	}
+
+	for expression, expected := range expressions {
+		pkg := interfaces.PackageMeta{Name: "package", License: expression, LicenseSPDX: expression}
+		verdicts := policy.Evaluate([]interfaces.PackageMeta{pkg}, time.Now())
+		if verdicts[0].Status != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, expression, verdicts[0].Status)
+		}
+	}
+}
+
+func TestEvaluateMultipleLicenses(t *testing.T) {
+	policy := &Policy{
+		Allowed: []string{"BSD License"},
+		Denied:  []string{"GPL-3.0-only"},
+	}
+
+	packages := []interfaces.PackageMeta{
+		{
+			Name:     "cryptography",
+			License:  "Apache Software License OR BSD License",
+			Licenses: []string{"Apache Software License", "BSD License"},
+		},
+		{
+			Name:     "gpl-package",
+			License:  "GNU General Public License v3 (GPLv3) OR Proprietary",
+			Licenses: []string{"GNU General Public License v3 (GPLv3)", "Proprietary"},
+		},
+	}
+	expected := []string{interfaces.VerdictAllowed, interfaces.VerdictUnknown}
+
+	for i, verdict := range policy.Evaluate(packages, time.Now()) {
+		if verdict.Status != expected[i] {
+			t.Errorf("Expected %s for %s, got %s", expected[i], verdict.Package, verdict.Status)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	policy := &Policy{
+		Allowed: []string{"MIT"},
+		Exceptions: []Exception{
+			{Package: "Foo_Bar", Justification: "Vendored fork", expires: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+			{Package: "old.package", Justification: "Replaced", expires: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	pkgErrors := []interfaces.PackageError{
+		{Package: "requests", Stage: interfaces.StageFetch},
+		{Package: "foo-bar", Stage: interfaces.StageDecode},
+		{Package: "Old_Package", Stage: interfaces.StageFetch},
+	}
+	expected := []interfaces.Verdict{
+		{Package: "requests", Status: interfaces.VerdictUnknown, Reason: "license not resolved: fetch"},
+		{Package: "foo-bar", Status: interfaces.VerdictAllowed, Reason: "exception: Vendored fork"},
+		{Package: "Old_Package", Status: interfaces.VerdictUnknown, Reason: "license not resolved: fetch, exception expired"},
+	}
+	verdicts := policy.EvaluateErrors(pkgErrors, now)
+	if !reflect.DeepEqual(expected, verdicts) {
+		t.Errorf("Expected %v, got %v", expected, verdicts)
+	}
+
+	// the exceptions match the normalized package names
+	verdicts = policy.Evaluate([]interfaces.PackageMeta{{Name: "foo.bar", License: "Proprietary"}}, now)
+	if verdicts[0].Status != interfaces.VerdictAllowed {
+		t.Errorf("Expected the exception of Foo_Bar to allow foo.bar, got %s", verdicts[0].Status)
+	}
+}
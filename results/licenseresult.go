@@ -1,20 +1,38 @@
 // Implements the Result interface for a license result
 // The output for this implementation is
 // <package name>: <license name>
-// <package name>: <license name>
+// <package name>: <license name> (<SPDX identifier>) [<verdict>]
+// <package name>: <license name> {conflicts with <source>: <license name>} [<verdict>]
+// <package name>: <license name> (detected with <confidence>% confidence) for the licenses detected from the license files
+// <package name>: <license name> (via <direct dependency> > <dependency>) for transitive packages
+// <package name>: <license name> (on <target>, <target>) when the packages are checked for more targets
+// <package name>: <license name> (in <project>, <project>) for the packages of the monorepo projects
+// followed by the packages that could not be resolved
+// Unresolved:
+// <package name>: <stage>: <error>
 package results
 
-import "github.com/radiculaCZ/license-check/interfaces"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
 
 type LicenseResult struct {
 	name     string
 	packages []interfaces.PackageMeta
+	errors   []interfaces.PackageError
+	// verdicts are keyed by <package name>@<version>
+	verdicts map[string]interfaces.Verdict
 }
 
 func NewLicenseResult() *LicenseResult {
 	return &LicenseResult{
 		name:     "license",
 		packages: []interfaces.PackageMeta{},
+		errors:   []interfaces.PackageError{},
+		verdicts: map[string]interfaces.Verdict{},
 	}
 }
 
@@ -22,6 +40,16 @@ func (l *LicenseResult) AddPackageMeta(packages []interfaces.PackageMeta) {
 	l.packages = append(l.packages, packages...)
 }
 
+func (l *LicenseResult) AddPackageErrors(errors []interfaces.PackageError) {
+	l.errors = append(l.errors, errors...)
+}
+
+func (l *LicenseResult) AddVerdicts(verdicts []interfaces.Verdict) {
+	for _, verdict := range verdicts {
+		l.verdicts[verdict.Package+"@"+verdict.Version] = verdict
+	}
+}
+
 func (l *LicenseResult) GetResultName() string {
 	return l.name
 }
@@ -29,7 +57,39 @@ func (l *LicenseResult) GetResultName() string {
 func (l *LicenseResult) GetResult() []byte {
 	var result []byte
 	for _, pkg := range l.packages {
-		result = append(result, []byte(pkg.Name+": "+pkg.License+"\n")...)
+		line := pkg.Name + ": " + pkg.License
+		if pkg.LicenseSPDX != "" && pkg.LicenseSPDX != pkg.License {
+			line += " (" + pkg.LicenseSPDX + ")"
+		}
+		if pkg.LicenseSource == interfaces.LicenseSourceFile {
+			line += fmt.Sprintf(" (detected with %.0f%% confidence)", pkg.LicenseConfidence*100)
+		}
+		if len(pkg.LicenseConflicts) > 0 {
+			line += " {conflicts with " + strings.Join(pkg.LicenseConflicts, ", ") + "}"
+		}
+		if pkg.Transitive {
+			line += " (via " + strings.Join(pkg.Path, " > ") + ")"
+		}
+		if len(pkg.Targets) > 0 {
+			line += " (on " + strings.Join(pkg.Targets, ", ") + ")"
+		}
+		if len(pkg.Projects) > 0 {
+			line += " (in " + strings.Join(pkg.Projects, ", ") + ")"
+		}
+		if verdict, ok := l.verdicts[pkg.Name+"@"+pkg.Version]; ok {
+			line += " [" + verdict.Status
+			if verdict.Reason != "" {
+				line += ": " + verdict.Reason
+			}
+			line += "]"
+		}
+		result = append(result, []byte(line+"\n")...)
+	}
+	if len(l.errors) > 0 {
+		result = append(result, []byte("Unresolved:\n")...)
+	}
+	for _, pkgErr := range l.errors {
+		result = append(result, []byte(pkgErr.Error()+"\n")...)
 	}
 	return result
 }
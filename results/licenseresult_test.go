@@ -0,0 +1,27 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// TestLicenseResultVersionVerdicts tests that every version of the package gets its own verdict
+func TestLicenseResultVersionVerdicts(t *testing.T) {
+	result := NewLicenseResult()
+	result.AddPackageMeta([]interfaces.PackageMeta{
+		{Name: "foo", Version: "1.0.0", License: "GPL-3.0-only"},
+		{Name: "foo", Version: "2.0.0", License: "MIT"},
+	})
+	result.AddVerdicts([]interfaces.Verdict{
+		{Package: "foo", Version: "1.0.0", License: "GPL-3.0-only", Status: interfaces.VerdictDenied},
+		{Package: "foo", Version: "2.0.0", License: "MIT", Status: interfaces.VerdictAllowed},
+	})
+
+	expected := "foo: GPL-3.0-only [denied]\nfoo: MIT [allowed]\n"
+	if diff := cmp.Diff(expected, string(result.GetResult())); diff != "" {
+		t.Errorf("Result mismatch (-want +got):\n%s", diff)
+	}
+}
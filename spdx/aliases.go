@@ -0,0 +1,117 @@
+package spdx
+
+// aliases maps the license names that are not part of the SPDX license list
+// to the SPDX identifiers, they are matched the same way as the SPDX license names
+// Ambiguous names (e.g. "BSD License", "GNU General Public License (GPL)", "Apache Software License")
+// are left out on purpose, as guessing the version would give a wrong answer
+var aliases = map[string]string{
+	// trove classifiers, see https://pypi.org/classifiers/
+	"Boost Software License 1.0 (BSL-1.0)":                            "BSL-1.0",
+	"CC0 1.0 Universal (CC0 1.0) Public Domain Dedication":            "CC0-1.0",
+	"CEA CNRS Inria Logiciel Libre License, version 2.1 (CeCILL-2.1)": "CECILL-2.1",
+	"CeCILL-B Free Software License Agreement (CECILL-B)":             "CECILL-B",
+	"CeCILL-C Free Software License Agreement (CECILL-C)":             "CECILL-C",
+	"Common Development and Distribution License 1.0 (CDDL-1.0)":      "CDDL-1.0",
+	"Common Public License":                                           "CPL-1.0",
+	"Eclipse Public License 1.0 (EPL-1.0)":                            "EPL-1.0",
+	"Eclipse Public License 2.0 (EPL-2.0)":                            "EPL-2.0",
+	"Educational Community License, Version 2.0 (ECL-2.0)":            "ECL-2.0",
+	"European Union Public Licence 1.0 (EUPL 1.0)":                    "EUPL-1.0",
+	"European Union Public Licence 1.1 (EUPL 1.1)":                    "EUPL-1.1",
+	"European Union Public Licence 1.2 (EUPL 1.2)":                    "EUPL-1.2",
+	"GNU Affero General Public License v3":                            "AGPL-3.0-only",
+	"GNU Affero General Public License v3 or later (AGPLv3+)":         "AGPL-3.0-or-later",
+	"GNU General Public License v2 (GPLv2)":                           "GPL-2.0-only",
+	"GNU General Public License v2 or later (GPLv2+)":                 "GPL-2.0-or-later",
+	"GNU General Public License v3 (GPLv3)":                           "GPL-3.0-only",
+	"GNU General Public License v3 or later (GPLv3+)":                 "GPL-3.0-or-later",
+	"GNU Lesser General Public License v2 (LGPLv2)":                   "LGPL-2.0-only",
+	"GNU Lesser General Public License v2 or later (LGPLv2+)":         "LGPL-2.0-or-later",
+	"GNU Lesser General Public License v3 (LGPLv3)":                   "LGPL-3.0-only",
+	"GNU Lesser General Public License v3 or later (LGPLv3+)":         "LGPL-3.0-or-later",
+	"Historical Permission Notice and Disclaimer (HPND)":              "HPND",
+	"IBM Public License":                                              "IPL-1.0",
+	"ISC License (ISCL)":                                              "ISC",
+	"Intel Open Source License":                                       "Intel",
+	"MIT License":                                                     "MIT",
+	"MIT No Attribution License (MIT-0)":                              "MIT-0",
+	"MirOS License (MirOS)":                                           "MirOS",
+	"Motosoto License":                                                "Motosoto",
+	"Mozilla Public License 1.0 (MPL)":                                "MPL-1.0",
+	"Mozilla Public License 1.1 (MPL 1.1)":                            "MPL-1.1",
+	"Mozilla Public License 2.0 (MPL 2.0)":                            "MPL-2.0",
+	"Mulan Permissive Software License v2 (MulanPSL-2.0)":             "MulanPSL-2.0",
+	"Nethack General Public License":                                  "NGPL",
+	"Nokia Open Source License":                                       "Nokia",
+	"Open Group Test Suite License":                                   "OGTSL",
+	"Open Software License 3.0 (OSL-3.0)":                             "OSL-3.0",
+	"PostgreSQL License":                                              "PostgreSQL",
+	"Python License (CNRI Python License)":                            "CNRI-Python",
+	"Qt Public License (QPL)":                                         "QPL-1.0",
+	"Ricoh Source Code Public License":                                "RSCPL",
+	"SIL Open Font License 1.1 (OFL-1.1)":                             "OFL-1.1",
+	"Sleepycat License":                                               "Sleepycat",
+	"Sun Industry Standards Source License (SISSL)":                   "SISSL",
+	"Sun Public License":                                              "SPL-1.0",
+	"The Unlicense (Unlicense)":                                       "Unlicense",
+	"Universal Permissive License (UPL)":                              "UPL-1.0",
+	"University of Illinois/NCSA Open Source License":                 "NCSA",
+	"Vovida Software License 1.0":                                     "VSL-1.0",
+	"W3C License":                                                     "W3C",
+	"X.Net License":                                                   "Xnet",
+	"Zero-Clause BSD (0BSD)":                                          "0BSD",
+	"zlib/libpng License":                                             "Zlib",
+
+	// common free text variants
+	"Apache 2":                    "Apache-2.0",
+	"Apache License 2":            "Apache-2.0",
+	"Apache License, Version 2.0": "Apache-2.0",
+	"Apache Software License 2":   "Apache-2.0",
+	"ASL 2":                       "Apache-2.0",
+	"AGPLv3":                      "AGPL-3.0-only",
+	"AGPLv3+":                     "AGPL-3.0-or-later",
+	"BSD 2-Clause":                "BSD-2-Clause",
+	"2-Clause BSD":                "BSD-2-Clause",
+	"BSD-2":                       "BSD-2-Clause",
+	"Simplified BSD":              "BSD-2-Clause",
+	"BSD 3-Clause":                "BSD-3-Clause",
+	"3-Clause BSD":                "BSD-3-Clause",
+	"BSD-3":                       "BSD-3-Clause",
+	"New BSD":                     "BSD-3-Clause",
+	"Modified BSD":                "BSD-3-Clause",
+	"Revised BSD":                 "BSD-3-Clause",
+	"CC0":                         "CC0-1.0",
+	"Expat":                       "MIT",
+	"GPLv2":                       "GPL-2.0-only",
+	"GPLv2+":                      "GPL-2.0-or-later",
+	"GPLv3":                       "GPL-3.0-only",
+	"GPLv3+":                      "GPL-3.0-or-later",
+	"GNU GPLv2":                   "GPL-2.0-only",
+	"GNU GPLv3":                   "GPL-3.0-only",
+	"LGPLv2":                      "LGPL-2.0-only",
+	"LGPLv2+":                     "LGPL-2.0-or-later",
+	"LGPLv2.1":                    "LGPL-2.1-only",
+	"LGPLv2.1+":                   "LGPL-2.1-or-later",
+	"LGPLv3":                      "LGPL-3.0-only",
+	"LGPLv3+":                     "LGPL-3.0-or-later",
+	"MPL 2":                       "MPL-2.0",
+	"MPLv2":                       "MPL-2.0",
+	"ISCL":                        "ISC",
+	"zlib":                        "Zlib",
+	"EPL 2":                       "EPL-2.0",
+}
+
+// urlAliases maps the license urls that are not in the SPDX license list
+// to the SPDX identifiers
+var urlAliases = map[string]string{
+	"https://opensource.org/license/mit":          "MIT",
+	"https://opensource.org/license/bsd-2-clause": "BSD-2-Clause",
+	"https://opensource.org/license/bsd-3-clause": "BSD-3-Clause",
+	"https://opensource.org/license/apache-2-0":   "Apache-2.0",
+	"https://www.gnu.org/licenses/gpl-2.0":        "GPL-2.0-only",
+	"https://www.gnu.org/licenses/gpl-3.0":        "GPL-3.0-only",
+	"https://www.gnu.org/licenses/lgpl-2.1":       "LGPL-2.1-only",
+	"https://www.gnu.org/licenses/lgpl-3.0":       "LGPL-3.0-only",
+	"https://www.gnu.org/licenses/agpl-3.0":       "AGPL-3.0-only",
+	"https://www.mozilla.org/MPL/2.0":             "MPL-2.0",
+}
@@ -0,0 +1,224 @@
+package spdx
+
+import (
+	"embed"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/radiculaCZ/license-check/interfaces"
+)
+
+// MinConfidence is the lowest similarity of the license text and the license template
+// for which the license is considered detected
+const MinConfidence = 0.8
+
+// licenseTexts are the templates of the commonly used licenses from the SPDX license list
+// https://github.com/spdx/license-list-data/tree/main/text named by the SPDX identifier,
+// the LGPL-3.0-only template contains only the additional permissions without the GPL-3.0 text,
+// as the packages ship the two texts in separate files
+//
+//go:embed texts/*.txt
+var licenseTexts embed.FS
+
+var (
+	templates     map[string]textShingles
+	templatesOnce sync.Once
+
+	placeholderPattern = regexp.MustCompile(`<[^<>\n]*>`)
+	copyrightPattern   = regexp.MustCompile(`^(copyright\b|\(c\)|©|all rights reserved)`)
+	wordPattern        = regexp.MustCompile(`[a-z0-9]+`)
+	// the license files of the dual licensed packages, e.g. LICENSE-MIT and LICENSE-APACHE
+	dualLicenseFilePattern = regexp.MustCompile(`(?i)^licen[cs]e[-._](mit|apache(-2\.0)?)(\.(txt|md))?$`)
+	// spelling variants that do not change the meaning of the text
+	wordVariants = map[string]string{"licence": "license", "licences": "licenses"}
+)
+
+// textShingles is the multiset of the word pairs of the normalized text
+type textShingles struct {
+	counts map[string]int
+	total  int
+}
+
+// Classify returns the SPDX identifier of the license the text is the most similar to
+// and the similarity in the range from 0 to 1
+// The texts are compared after the copyright lines, the template placeholders,
+// the punctuation and the whitespace are removed, the similarity is the Dice coefficient
+// of the word pairs of the texts
+// The identifier is empty when the similarity is lower than MinConfidence
+func Classify(text string) (string, float64) {
+	templatesOnce.Do(loadTemplates)
+
+	shingles := newTextShingles(text)
+	if shingles.total == 0 {
+		return "", 0
+	}
+	best, bestConfidence := "", 0.0
+	for id, template := range templates {
+		confidence := shingles.similarity(template)
+		// the identifiers break the ties, so the result does not depend on the map order
+		if confidence > bestConfidence || (confidence == bestConfidence && id < best) {
+			best, bestConfidence = id, confidence
+		}
+	}
+	if bestConfidence < MinConfidence {
+		return "", bestConfidence
+	}
+	return best, bestConfidence
+}
+
+// Detection is the license detected from the license files of the package
+type Detection struct {
+	// License is the SPDX identifier, or the conjunction of the identifiers when the files
+	// contain different licenses, the dual licensed package (LICENSE-MIT and LICENSE-APACHE)
+	// has the disjunction of the identifiers
+	License string
+	// Licenses lists the licenses of the dual licensed package, the package can use any of them
+	Licenses []string
+	// Confidence is the lowest similarity of the detected licenses
+	Confidence float64
+}
+
+// LicenseFile is the license file of the package
+type LicenseFile struct {
+	// Name is the path of the file, only its base name is used
+	Name string
+	Text string
+}
+
+// ClassifyFiles classifies every license file of the package and combines the detected licenses
+// The LGPL-3.0 is shipped as the GPL-3.0 text (COPYING) with the additional permissions
+// (COPYING.LESSER), so the two texts together are the LGPL-3.0-only
+// The different licenses apply all at once (e.g. the bundled third party notices), so they are
+// joined by AND, only the files of the dual licensed package named by the license
+// (LICENSE-MIT and LICENSE-APACHE) are joined by OR
+func ClassifyFiles(files []LicenseFile) Detection {
+	confidences := map[string]float64{}
+	dual := map[string]bool{}
+	for _, file := range files {
+		id, confidence := Classify(file.Text)
+		if id == "" {
+			continue
+		}
+		if confidence > confidences[id] {
+			confidences[id] = confidence
+		}
+		if dualLicenseFilePattern.MatchString(path.Base(file.Name)) {
+			dual[id] = true
+		}
+	}
+	if _, ok := confidences["LGPL-3.0-only"]; ok {
+		delete(confidences, "GPL-3.0-only")
+	}
+
+	var detection Detection
+	var alternatives, required []string
+	for id, confidence := range confidences {
+		if detection.Confidence == 0 || confidence < detection.Confidence {
+			detection.Confidence = confidence
+		}
+		if dual[id] {
+			alternatives = append(alternatives, id)
+		} else {
+			required = append(required, id)
+		}
+	}
+	sort.Strings(alternatives)
+	if len(alternatives) < 2 {
+		required = append(required, alternatives...)
+		alternatives = nil
+	}
+	sort.Strings(required)
+
+	switch {
+	case len(alternatives) > 0 && len(required) == 0:
+		detection.License = strings.Join(alternatives, " OR ")
+		detection.Licenses = alternatives
+	case len(alternatives) > 0:
+		detection.License = "(" + strings.Join(alternatives, " OR ") + ") AND " + strings.Join(required, " AND ")
+	default:
+		detection.License = strings.Join(required, " AND ")
+	}
+	return detection
+}
+
+// DetectLicense sets the license of the package detected from its license files,
+// the package is left unchanged when no license is detected
+func DetectLicense(meta *interfaces.PackageMeta, files []LicenseFile) {
+	detection := ClassifyFiles(files)
+	if detection.License == "" {
+		return
+	}
+	meta.License = detection.License
+	meta.LicenseSPDX = detection.License
+	meta.LicenseSource = interfaces.LicenseSourceFile
+	meta.LicenseConfidence = detection.Confidence
+	meta.Licenses = detection.Licenses
+}
+
+func loadTemplates() {
+	entries, err := licenseTexts.ReadDir("texts")
+	if err != nil {
+		panic("invalid embedded license texts: " + err.Error())
+	}
+	templates = make(map[string]textShingles, len(entries))
+	for _, entry := range entries {
+		data, err := licenseTexts.ReadFile(path.Join("texts", entry.Name()))
+		if err != nil {
+			panic("invalid embedded license texts: " + err.Error())
+		}
+		templates[strings.TrimSuffix(entry.Name(), ".txt")] = newTextShingles(string(data))
+	}
+}
+
+// normalizeText returns the words of the license text without the copyright lines
+// e.g. "Copyright (c) 2023 Jane Doe" is different in every copy of the license
+func normalizeText(text string) []string {
+	var words []string
+	for _, line := range strings.Split(strings.ToLower(text), "\n") {
+		line = strings.TrimSpace(line)
+		if copyrightPattern.MatchString(line) {
+			continue
+		}
+		line = placeholderPattern.ReplaceAllString(line, " ")
+		for _, word := range wordPattern.FindAllString(line, -1) {
+			if variant, ok := wordVariants[word]; ok {
+				word = variant
+			}
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func newTextShingles(text string) textShingles {
+	words := normalizeText(text)
+	shingles := textShingles{counts: map[string]int{}}
+	if len(words) == 1 {
+		shingles.counts[words[0]]++
+		shingles.total++
+	}
+	for i := 0; i+1 < len(words); i++ {
+		shingles.counts[words[i]+" "+words[i+1]]++
+		shingles.total++
+	}
+	return shingles
+}
+
+// similarity returns the Dice coefficient of the two multisets
+func (s textShingles) similarity(other textShingles) float64 {
+	if s.total == 0 || other.total == 0 {
+		return 0
+	}
+	common := 0
+	for shingle, count := range s.counts {
+		otherCount := other.counts[shingle]
+		if otherCount < count {
+			count = otherCount
+		}
+		common += count
+	}
+	return 2 * float64(common) / float64(s.total+other.total)
+}
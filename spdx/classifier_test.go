@@ -0,0 +1,164 @@
+package spdx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mitLicense = `The MIT License (MIT)
+
+Copyright (c) 2015-2023 Jane Doe <jane@example.com>
+Copyright (c) 2014 Example Corp.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+`
+
+const bsd3License = `BSD 3-Clause License
+
+Copyright (c) 2008-2011, AQR Capital Management, LLC, Lambda Foundry, Inc.
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+  list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+  this list of conditions and the following disclaimer in the documentation
+  and/or other materials provided with the distribution.
+
+* Neither the name of the copyright holder nor the names of its
+  contributors may be used to endorse or promote products derived from
+  this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+func TestClassify(t *testing.T) {
+	apache, err := licenseTexts.ReadFile("texts/Apache-2.0.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// most of the projects leave out the appendix of the license
+	apacheWithoutAppendix, _, _ := strings.Cut(string(apache), "APPENDIX")
+
+	texts := map[string]struct {
+		text string
+		id   string
+	}{
+		"MIT":                  {mitLicense, "MIT"},
+		"BSD-3-Clause":         {bsd3License, "BSD-3-Clause"},
+		"Apache-2.0":           {string(apache), "Apache-2.0"},
+		"Apache-2.0 appendix":  {apacheWithoutAppendix, "Apache-2.0"},
+		"MIT without notice":   {strings.Replace(mitLicense, "The above copyright notice and this permission notice shall be included in\nall copies or substantial portions of the Software.", "", 1), "MIT-0"},
+		"Licence spelling":     {strings.ReplaceAll(mitLicense, "permission notice", "licence notice"), "MIT"},
+		"not a license":        {"This project is licensed under the terms of the MIT license, see the website.", ""},
+		"empty":                {"", ""},
+		"only copyright lines": {"Copyright (c) 2023 Jane Doe\nAll rights reserved.", ""},
+	}
+
+	for name, test := range texts {
+		id, confidence := Classify(test.text)
+		if id != test.id {
+			t.Errorf("%s: expected %q, got %q with confidence %.2f", name, test.id, id, confidence)
+		}
+		if id != "" && confidence < MinConfidence {
+			t.Errorf("%s: expected confidence at least %.2f, got %.2f", name, MinConfidence, confidence)
+		}
+	}
+
+	// only the title differs from the template
+	if _, confidence := Classify(mitLicense); confidence < 0.95 {
+		t.Errorf("Expected close match of the MIT license, got confidence %.2f", confidence)
+	}
+}
+
+func TestLicenseTextsKnown(t *testing.T) {
+	entries, err := licenseTexts.ReadDir("texts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".txt")
+		if license, ok := GetLicense(id); !ok || license.Deprecated {
+			t.Errorf("License text %s is not a current SPDX license", entry.Name())
+		}
+	}
+}
+
+func TestClassifyFiles(t *testing.T) {
+	readText := func(id string) string {
+		data, err := licenseTexts.ReadFile("texts/" + id + ".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	tests := map[string]struct {
+		files    []LicenseFile
+		license  string
+		licenses []string
+	}{
+		"single file":       {[]LicenseFile{{"LICENSE", mitLicense}}, "MIT", nil},
+		"LGPL-3.0 with GPL": {[]LicenseFile{{"COPYING", readText("GPL-3.0-only")}, {"COPYING.LESSER", readText("LGPL-3.0-only")}}, "LGPL-3.0-only", nil},
+		"GPL-3.0 only":      {[]LicenseFile{{"COPYING", readText("GPL-3.0-only")}}, "GPL-3.0-only", nil},
+		"dual licensed": {
+			[]LicenseFile{{"LICENSE-MIT", mitLicense}, {"LICENSE-APACHE", readText("Apache-2.0")}},
+			"Apache-2.0 OR MIT", []string{"Apache-2.0", "MIT"},
+		},
+		"bundled third party notice": {
+			[]LicenseFile{{"LICENSE", readText("GPL-3.0-only")}, {"LICENSE-THIRDPARTY", mitLicense}},
+			"GPL-3.0-only AND MIT", nil,
+		},
+		"dual license file with third party notice": {
+			[]LicenseFile{{"LICENSE-MIT", mitLicense}, {"LICENSE-APACHE", readText("Apache-2.0")}, {"NOTICE", bsd3License}},
+			"(Apache-2.0 OR MIT) AND BSD-3-Clause", nil,
+		},
+		"same license twice":  {[]LicenseFile{{"LICENSE", mitLicense}, {"COPYING", mitLicense}}, "MIT", nil},
+		"not a license":       {[]LicenseFile{{"LICENSE", "See the website."}}, "", nil},
+		"license and unknown": {[]LicenseFile{{"LICENSE", bsd3License}, {"NOTICE", "See the website."}}, "BSD-3-Clause", nil},
+	}
+	for name, test := range tests {
+		detection := ClassifyFiles(test.files)
+		if detection.License != test.license {
+			t.Errorf("%s: expected %q, got %q", name, test.license, detection.License)
+		}
+		if !reflect.DeepEqual(detection.Licenses, test.licenses) {
+			t.Errorf("%s: expected licenses %v, got %v", name, test.licenses, detection.Licenses)
+		}
+		if _, err := Parse(detection.License); detection.License != "" && err != nil {
+			t.Errorf("%s: expected a valid expression, got %v", name, err)
+		}
+		if detection.License != "" && detection.Confidence < MinConfidence {
+			t.Errorf("%s: expected confidence at least %.2f, got %.2f", name, MinConfidence, detection.Confidence)
+		}
+	}
+}
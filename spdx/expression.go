@@ -0,0 +1,245 @@
+package spdx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Expression is a node of the parsed SPDX license expression
+// e.g. MIT OR (GPL-2.0-only WITH Classpath-exception-2.0 AND BSD-3-Clause)
+// https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/
+// The node is one of *SimpleExpression, *And and *Or
+type Expression interface {
+	// String renders the expression in the canonical form
+	String() string
+}
+
+// SimpleExpression is a single license of the expression with the optional exception
+type SimpleExpression struct {
+	ID string
+	// OrLater is set for the + operator and the -or-later identifiers
+	OrLater   bool
+	Exception string
+}
+
+// And requires all the licenses of the expressions to be satisfied
+type And struct {
+	Expressions []Expression
+}
+
+// Or requires any of the licenses of the expressions to be satisfied
+type Or struct {
+	Expressions []Expression
+}
+
+// The grammar of the license expression, the AND operator has higher precedence
+// than the OR operator, the WITH operator has the highest precedence
+type orExpression struct {
+	And []*andExpression `parser:"@@ ( ( 'OR' | 'or' ) @@ )*"`
+}
+
+type andExpression struct {
+	With []*withExpression `parser:"@@ ( ( 'AND' | 'and' ) @@ )*"`
+}
+
+type withExpression struct {
+	Simple    *licenseTerm `parser:"@@"`
+	Exception *string      `parser:"( ( 'WITH' | 'with' ) @Ident )?"`
+}
+
+type licenseTerm struct {
+	Group   *orExpression `parser:"  '(' @@ ')'"`
+	License *string       `parser:"| @Ident"`
+	Plus    bool          `parser:"  @'+'?"`
+}
+
+var expressionParser = participle.MustBuild[orExpression](
+	participle.Lexer(lexer.MustSimple([]lexer.SimpleRule{
+		{Name: "Ident", Pattern: `[A-Za-z0-9][A-Za-z0-9.\-:]*`},
+		{Name: "Punct", Pattern: `[()+]`},
+		{Name: "Whitespace", Pattern: `\s+`},
+	})),
+	participle.UseLookahead(2),
+	participle.Elide("Whitespace"),
+)
+
+var operators = map[string]bool{"and": true, "or": true, "with": true}
+
+// Parse parses the SPDX license expression
+// The parser is only syntactic, the license identifiers are not required
+// to be in the SPDX license list, see Known for that
+func Parse(expression string) (Expression, error) {
+	parsed, err := expressionParser.ParseString("", expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %w", expression, err)
+	}
+	return parsed.toExpression()
+}
+
+func (o *orExpression) toExpression() (Expression, error) {
+	or := &Or{}
+	for _, and := range o.And {
+		expression, err := and.toExpression()
+		if err != nil {
+			return nil, err
+		}
+		// (A OR B) OR C is the same as A OR B OR C
+		if nested, ok := expression.(*Or); ok {
+			or.Expressions = append(or.Expressions, nested.Expressions...)
+		} else {
+			or.Expressions = append(or.Expressions, expression)
+		}
+	}
+	if len(or.Expressions) == 1 {
+		return or.Expressions[0], nil
+	}
+	return or, nil
+}
+
+func (a *andExpression) toExpression() (Expression, error) {
+	and := &And{}
+	for _, with := range a.With {
+		expression, err := with.toExpression()
+		if err != nil {
+			return nil, err
+		}
+		if nested, ok := expression.(*And); ok {
+			and.Expressions = append(and.Expressions, nested.Expressions...)
+		} else {
+			and.Expressions = append(and.Expressions, expression)
+		}
+	}
+	if len(and.Expressions) == 1 {
+		return and.Expressions[0], nil
+	}
+	return and, nil
+}
+
+func (w *withExpression) toExpression() (Expression, error) {
+	if w.Simple.Group != nil {
+		if w.Simple.Plus {
+			return nil, fmt.Errorf("invalid license expression: + operator after parentheses")
+		}
+		if w.Exception != nil {
+			return nil, fmt.Errorf("invalid license expression: WITH operator after parentheses")
+		}
+		return w.Simple.Group.toExpression()
+	}
+
+	id := *w.Simple.License
+	if operators[strings.ToLower(id)] {
+		return nil, fmt.Errorf("invalid license expression: missing license before %s", id)
+	}
+	license := &SimpleExpression{ID: id, OrLater: w.Simple.Plus}
+	if strings.HasSuffix(id, "-or-later") && !isLicenseRef(id) {
+		license.ID = strings.TrimSuffix(id, "-or-later")
+		license.OrLater = true
+	}
+	if w.Exception != nil {
+		if operators[strings.ToLower(*w.Exception)] {
+			return nil, fmt.Errorf("invalid license expression: missing exception after WITH")
+		}
+		license.Exception = *w.Exception
+	}
+	return license, nil
+}
+
+// Known reports whether the license and its exception are in the SPDX license list
+// The user defined LicenseRef- licenses are considered known as well
+func (l *SimpleExpression) Known() bool {
+	if !isLicenseRef(l.ID) {
+		if _, ok := GetLicense(l.licenseID()); !ok {
+			if _, ok := GetLicense(l.ID); !ok {
+				return false
+			}
+		}
+	}
+	if l.Exception != "" {
+		if _, ok := GetException(l.Exception); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// licenseID returns the canonical identifier without the exception
+// the + operator is rendered as the -or-later identifier when it exists
+func (l *SimpleExpression) licenseID() string {
+	if isLicenseRef(l.ID) {
+		return l.ID
+	}
+	if l.OrLater {
+		base := strings.TrimSuffix(l.ID, "-only")
+		if license, ok := GetLicense(base + "-or-later"); ok {
+			return license.ID
+		}
+		if license, ok := GetLicense(l.ID); ok {
+			return license.ID + "+"
+		}
+		return l.ID + "+"
+	}
+	if license, ok := GetLicense(l.ID); ok {
+		return canonicalID(license)
+	}
+	return l.ID
+}
+
+func (l *SimpleExpression) String() string {
+	if l.Exception == "" {
+		return l.licenseID()
+	}
+	exception := l.Exception
+	if known, ok := GetException(exception); ok {
+		exception = known.ID
+	}
+	return l.licenseID() + " WITH " + exception
+}
+
+func (a *And) String() string {
+	var parts []string
+	for _, expression := range a.Expressions {
+		// OR has lower precedence, so it has to be in parentheses
+		if _, ok := expression.(*Or); ok {
+			parts = append(parts, "("+expression.String()+")")
+		} else {
+			parts = append(parts, expression.String())
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func (o *Or) String() string {
+	var parts []string
+	for _, expression := range o.Expressions {
+		parts = append(parts, expression.String())
+	}
+	return strings.Join(parts, " OR ")
+}
+
+func isLicenseRef(id string) bool {
+	return strings.HasPrefix(id, "LicenseRef-") || strings.HasPrefix(id, "DocumentRef-")
+}
+
+// Licenses returns all licenses of the expression in the order of appearance
+func Licenses(expression Expression) []*SimpleExpression {
+	switch e := expression.(type) {
+	case *SimpleExpression:
+		return []*SimpleExpression{e}
+	case *And:
+		return licensesOf(e.Expressions)
+	case *Or:
+		return licensesOf(e.Expressions)
+	}
+	return nil
+}
+
+func licensesOf(expressions []Expression) []*SimpleExpression {
+	var licenses []*SimpleExpression
+	for _, expression := range expressions {
+		licenses = append(licenses, Licenses(expression)...)
+	}
+	return licenses
+}
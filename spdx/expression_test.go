@@ -0,0 +1,96 @@
+package spdx
+
+import "testing"
+
+func TestParseCanonical(t *testing.T) {
+	expressions := map[string]string{
+		"MIT":                    "MIT",
+		"mit OR apache-2.0":      "MIT OR Apache-2.0",
+		"MIT or Apache-2.0":      "MIT OR Apache-2.0",
+		"(BSD-3-Clause AND MIT)": "BSD-3-Clause AND MIT",
+		"GPL-2.0-only WITH Classpath-exception-2.0": "GPL-2.0-only WITH Classpath-exception-2.0",
+		"GPL-2.0 with classpath-exception-2.0":      "GPL-2.0-only WITH Classpath-exception-2.0",
+		"GPL-2.0+":                                  "GPL-2.0-or-later",
+		"GPL-2.0-or-later":                          "GPL-2.0-or-later",
+		"LGPL-2.1-only+":                            "LGPL-2.1-or-later",
+		"Apache-2.0+":                               "Apache-2.0+",
+		"MIT AND (Apache-2.0 OR BSD-2-Clause)":      "MIT AND (Apache-2.0 OR BSD-2-Clause)",
+		"MIT AND Apache-2.0 OR BSD-2-Clause":        "MIT AND Apache-2.0 OR BSD-2-Clause",
+		"(MIT OR ISC) OR (Zlib)":                    "MIT OR ISC OR Zlib",
+		"LicenseRef-Custom OR MIT":                  "LicenseRef-Custom OR MIT",
+	}
+
+	for expression, expected := range expressions {
+		parsed, err := Parse(expression)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", expression, err)
+			continue
+		}
+		if parsed.String() != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, expression, parsed.String())
+		}
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	parsed, err := Parse("MIT AND Apache-2.0 OR GPL-2.0+ WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	or, ok := parsed.(*Or)
+	if !ok || len(or.Expressions) != 2 {
+		t.Fatalf("Expected OR expression with 2 branches, got %#v", parsed)
+	}
+
+	and, ok := or.Expressions[0].(*And)
+	if !ok || len(and.Expressions) != 2 {
+		t.Fatalf("Expected AND expression with 2 branches, got %#v", or.Expressions[0])
+	}
+
+	license, ok := or.Expressions[1].(*SimpleExpression)
+	if !ok {
+		t.Fatalf("Expected simple expression, got %#v", or.Expressions[1])
+	}
+	if license.ID != "GPL-2.0" || !license.OrLater || license.Exception != "Classpath-exception-2.0" {
+		t.Errorf("Unexpected license %#v", license)
+	}
+
+	if licenses := Licenses(parsed); len(licenses) != 3 {
+		t.Errorf("Expected 3 licenses, got %d", len(licenses))
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	expressions := []string{
+		"",
+		"MIT OR",
+		"AND MIT",
+		"(MIT OR Apache-2.0",
+		"MIT Apache-2.0",
+		"(MIT OR Apache-2.0) WITH Classpath-exception-2.0",
+		"MIT WITH",
+	}
+
+	for _, expression := range expressions {
+		if parsed, err := Parse(expression); err == nil {
+			t.Errorf("Expected error for %q, got %s", expression, parsed)
+		}
+	}
+}
+
+func TestNormalizeExpression(t *testing.T) {
+	licenses := map[string]string{
+		"MIT OR Apache-2.0":    "MIT OR Apache-2.0",
+		"mit and bsd-3-clause": "MIT AND BSD-3-Clause",
+		// unknown licenses in the expression
+		"MIT OR Foo-1.0": "",
+		"MIT WITH Foo":   "",
+	}
+
+	for license, expected := range licenses {
+		if id := Normalize(license); id != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, license, id)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package spdx
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// names maps the normalized license names to the SPDX identifiers
+	names = map[string]string{}
+	// urls maps the normalized license urls to the SPDX identifiers
+	urls = map[string]string{}
+
+	nonWordPattern      = regexp.MustCompile(`[^a-z0-9+.]+`)
+	versionPrefix       = regexp.MustCompile(`([a-z])v?(\d)`)
+	zeroMinorVersion    = regexp.MustCompile(`(\d)\.0\b`)
+	abbreviationPattern = regexp.MustCompile(`\(([^()]+)\)\s*$`)
+	// words that do not change the meaning of the license name
+	fillerWords = map[string]bool{"the": true, "license": true, "version": true, "v": true}
+)
+
+// initNormalization builds the lookup tables from the license list
+// the deprecated licenses are added last, so they do not shadow the current ones
+func initNormalization(list []License) {
+	for _, deprecated := range []bool{false, true} {
+		for _, license := range list {
+			if license.Deprecated != deprecated {
+				continue
+			}
+			id := canonicalID(license)
+			addName(license.Name, id)
+			addName(license.ID, id)
+			for _, url := range license.SeeAlso {
+				if _, ok := urls[normalizeURL(url)]; !ok {
+					urls[normalizeURL(url)] = id
+				}
+			}
+		}
+	}
+	for alias, id := range aliases {
+		names[normalizeName(alias)] = id
+	}
+	for url, id := range urlAliases {
+		urls[normalizeURL(url)] = id
+	}
+}
+
+func addName(name string, id string) {
+	key := normalizeName(name)
+	if _, ok := names[key]; !ok {
+		names[key] = id
+	}
+}
+
+// canonicalID returns the identifier that should be used instead of the license
+// the deprecated GNU identifiers are replaced by their -only and -or-later variants
+func canonicalID(license License) string {
+	if !license.Deprecated {
+		return license.ID
+	}
+	if strings.HasSuffix(license.ID, "+") {
+		if replacement, ok := GetLicense(strings.TrimSuffix(license.ID, "+") + "-or-later"); ok {
+			return replacement.ID
+		}
+	}
+	if replacement, ok := GetLicense(license.ID + "-only"); ok {
+		return replacement.ID
+	}
+	return license.ID
+}
+
+// normalizeName returns the key used to compare the license names
+// e.g. "Apache License, Version 2.0", "Apache-2" and "apache 2.0" are the same key
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "licence", "license")
+	name = versionPrefix.ReplaceAllString(name, "$1 $2")
+	for {
+		replaced := zeroMinorVersion.ReplaceAllString(name, "$1")
+		if replaced == name {
+			break
+		}
+		name = replaced
+	}
+	var words []string
+	for _, word := range strings.Fields(nonWordPattern.ReplaceAllString(name, " ")) {
+		word = strings.Trim(word, ".")
+		if word != "" && !fillerWords[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// normalizeURL returns the key used to compare the license urls
+// the scheme, www prefix, file extension and trailing slash are ignored
+func normalizeURL(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimPrefix(url, "http://")
+	url = strings.TrimPrefix(url, "www.")
+	url = strings.TrimSuffix(url, "/")
+	for _, extension := range []string{".html", ".htm", ".txt", ".php"} {
+		url = strings.TrimSuffix(url, extension)
+	}
+	return url
+}
+
+func isURL(license string) bool {
+	lower := strings.ToLower(license)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// Normalize returns the SPDX identifier of the license
+// The license can be the SPDX identifier or name, a trove classifier
+// (e.g. "License :: OSI Approved :: MIT License"), a common free text name
+// (e.g. "Apache 2.0", "GPLv3+"), the license url or the SPDX license expression
+// The expression is returned in its canonical form
+// If the license cannot be mapped, empty string is returned
+func Normalize(license string) string {
+	license = strings.TrimSpace(license)
+	// only the last part of the trove classifier is the license name
+	if strings.Contains(license, "::") {
+		parts := strings.Split(license, "::")
+		license = strings.TrimSpace(parts[len(parts)-1])
+	}
+	if license == "" {
+		return ""
+	}
+
+	if known, ok := GetLicense(license); ok {
+		return canonicalID(known)
+	}
+
+	if isURL(license) {
+		return urls[normalizeURL(license)]
+	}
+
+	if id, ok := names[normalizeName(license)]; ok {
+		return id
+	}
+
+	if expression, err := Parse(license); err == nil && allKnown(expression) {
+		return expression.String()
+	}
+
+	// the trove classifiers and free text often end with the abbreviation
+	// e.g. "Eclipse Public License 2.0 (EPL-2.0)"
+	if match := abbreviationPattern.FindStringSubmatch(license); match != nil {
+		abbreviation := strings.TrimSpace(match[1])
+		if known, ok := GetLicense(strings.ReplaceAll(abbreviation, " ", "-")); ok {
+			return canonicalID(known)
+		}
+		if id, ok := names[normalizeName(abbreviation)]; ok {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// allKnown reports whether all licenses of the expression are in the SPDX license list
+func allKnown(expression Expression) bool {
+	for _, license := range Licenses(expression) {
+		if !license.Known() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package spdx
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	licenses := map[string]string{
+		// SPDX identifiers and names
+		"MIT":          "MIT",
+		"apache-2.0":   "Apache-2.0",
+		"MIT License":  "MIT",
+		"GPL-2.0":      "GPL-2.0-only",
+		"GPL-2.0+":     "GPL-2.0-or-later",
+		"BSD-3-Clause": "BSD-3-Clause",
+		// trove classifiers
+		"License :: OSI Approved :: MIT License":                                     "MIT",
+		"License :: OSI Approved :: GNU General Public License v3 or later (GPLv3+)": "GPL-3.0-or-later",
+		"License :: OSI Approved :: Mozilla Public License 2.0 (MPL 2.0)":            "MPL-2.0",
+		"License :: OSI Approved :: Eclipse Public License 2.0 (EPL-2.0)":            "EPL-2.0",
+		// free text
+		"Apache 2.0":                  "Apache-2.0",
+		"Apache License, Version 2.0": "Apache-2.0",
+		"Apache License 2.0":          "Apache-2.0",
+		"apache2":                     "Apache-2.0",
+		"GPLv3+":                      "GPL-3.0-or-later",
+		"GPLv2":                       "GPL-2.0-only",
+		"LGPLv2.1+":                   "LGPL-2.1-or-later",
+		"3-clause BSD":                "BSD-3-Clause",
+		"ISC license":                 "ISC",
+		"Mozilla Public Licence 2.0":  "MPL-2.0",
+		// urls
+		"https://opensource.org/licenses/MIT":             "MIT",
+		"http://www.apache.org/licenses/LICENSE-2.0":      "Apache-2.0",
+		"https://www.apache.org/licenses/LICENSE-2.0.txt": "Apache-2.0",
+		// ambiguous or unknown licenses
+		"License :: OSI Approved :: BSD License":                        "",
+		"License :: OSI Approved :: Apache Software License":            "",
+		"License :: OSI Approved :: Python Software Foundation License": "",
+		"Apache":                           "",
+		"PSF":                              "",
+		"Boost":                            "",
+		"GNU General Public License (GPL)": "",
+		"Proprietary":                      "",
+		"":                                 "",
+	}
+
+	for license, expected := range licenses {
+		if id := Normalize(license); id != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, license, id)
+		}
+	}
+}
+
+func TestGetLicense(t *testing.T) {
+	license, ok := GetLicense("mit")
+	if !ok {
+		t.Fatal("Expected MIT license in the license list")
+	}
+	if license.ID != "MIT" || license.Name != "MIT License" {
+		t.Errorf("Expected MIT License, got %s %s", license.ID, license.Name)
+	}
+
+	if _, ok := GetException("Classpath-exception-2.0"); !ok {
+		t.Error("Expected Classpath-exception-2.0 in the exception list")
+	}
+}
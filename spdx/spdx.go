@@ -0,0 +1,70 @@
+// The spdx package works with the SPDX license identifiers https://spdx.org/licenses/
+// It contains an embedded copy of the SPDX license list, so no network
+// access is needed to normalize the license names to the SPDX identifiers
+package spdx
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+// licenseListData is the offline copy of the SPDX license list
+// in the format of https://github.com/spdx/license-list-data/blob/main/json/licenses.json
+// with the license exceptions added
+//
+//go:embed licenses.json
+var licenseListData []byte
+
+// License is a single license from the SPDX license list
+type License struct {
+	ID         string   `json:"licenseId"`
+	Name       string   `json:"name"`
+	SeeAlso    []string `json:"seeAlso"`
+	Deprecated bool     `json:"isDeprecatedLicenseId"`
+}
+
+// Exception is a license exception used with the WITH operator
+// e.g. GPL-2.0-only WITH Classpath-exception-2.0
+type Exception struct {
+	ID string `json:"licenseExceptionId"`
+}
+
+type licenseList struct {
+	Licenses   []License   `json:"licenses"`
+	Exceptions []Exception `json:"exceptions"`
+}
+
+var (
+	// licenses and exceptions are keyed by the lower case identifier,
+	// the identifiers are case insensitive
+	licenses   = map[string]License{}
+	exceptions = map[string]Exception{}
+)
+
+func init() {
+	var list licenseList
+	if err := json.Unmarshal(licenseListData, &list); err != nil {
+		panic("invalid embedded SPDX license list: " + err.Error())
+	}
+	for _, license := range list.Licenses {
+		licenses[strings.ToLower(license.ID)] = license
+	}
+	for _, exception := range list.Exceptions {
+		exceptions[strings.ToLower(exception.ID)] = exception
+	}
+	initNormalization(list.Licenses)
+}
+
+// GetLicense returns the license with the identifier, the lookup is case insensitive
+func GetLicense(id string) (License, bool) {
+	license, ok := licenses[strings.ToLower(id)]
+	return license, ok
+}
+
+// GetException returns the license exception with the identifier,
+// the lookup is case insensitive
+func GetException(id string) (Exception, bool) {
+	exception, ok := exceptions[strings.ToLower(id)]
+	return exception, ok
+}